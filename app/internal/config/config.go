@@ -0,0 +1,490 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"point-service/app/pkg/kafka"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+type Config struct {
+	Postgres   PostgresConfig   `yaml:"postgres"`
+	Point      PointConfig      `yaml:"point"`
+	Outbox     OutboxConfig     `yaml:"outbox"`
+	Redemption RedemptionConfig `yaml:"redemption"`
+	Kafka      KafkaConfig      `yaml:"kafka"`
+	Http       HttpConfig       `yaml:"http"`
+}
+
+type PostgresConfig struct {
+	Dsn string `yaml:"dsn" env:"POSTGRES_DSN"`
+}
+
+// PointConfig.Strategy is a repository.DecreaseStrategy and TierPolicy a service.TierPolicy, they are
+// checked where they are converted
+type PointConfig struct {
+	Strategy   string        `yaml:"strategy" env:"POINT_DECREASE_STRATEGY"`
+	TierPolicy string        `yaml:"tier_policy" env:"POINT_TIER_POLICY"`
+	WaitTime   time.Duration `yaml:"wait_time" env:"POINT_WAIT_TIME"`
+	MaxAttempt uint          `yaml:"max_attempt" env:"POINT_MAX_ATTEMPT"`
+}
+
+type OutboxConfig struct {
+	BatchSize       int           `yaml:"batch_size" env:"OUTBOX_BATCH_SIZE"`
+	PollInterval    time.Duration `yaml:"poll_interval" env:"OUTBOX_POLL_INTERVAL"`
+	CleanupInterval time.Duration `yaml:"cleanup_interval" env:"OUTBOX_CLEANUP_INTERVAL"`
+	Retention       time.Duration `yaml:"retention" env:"OUTBOX_RETENTION"`
+}
+
+type RedemptionConfig struct {
+	ReservationTtl time.Duration `yaml:"reservation_ttl" env:"REDEMPTION_RESERVATION_TTL"`
+	SweepInterval  time.Duration `yaml:"sweep_interval" env:"REDEMPTION_SWEEP_INTERVAL"`
+	SweepBatchSize int           `yaml:"sweep_batch_size" env:"REDEMPTION_SWEEP_BATCH_SIZE"`
+}
+
+// HttpConfig.AdminToken is the bearer token of the /admin api, HttpConfig.UserTokenSecret signs the
+// HS256 bearer JWT of the redemption api
+type HttpConfig struct {
+	Addr            string        `yaml:"addr" env:"HTTP_ADDR"`
+	AdminToken      string        `yaml:"admin_token" env:"HTTP_ADMIN_TOKEN"`
+	UserTokenSecret string        `yaml:"user_token_secret" env:"HTTP_USER_TOKEN_SECRET"`
+	ReadTimeout     time.Duration `yaml:"read_timeout" env:"HTTP_READ_TIMEOUT"`
+	WriteTimeout    time.Duration `yaml:"write_timeout" env:"HTTP_WRITE_TIMEOUT"`
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" env:"HTTP_SHUTDOWN_TIMEOUT"`
+}
+
+type KafkaConfig struct {
+	Brokers               []string          `yaml:"brokers" env:"KAFKA_BROKERS"`
+	ConsumerGroupId       string            `yaml:"consumer_group_id" env:"KAFKA_CONSUMER_GROUP_ID"`
+	HandlerTimeout        time.Duration     `yaml:"handler_timeout" env:"KAFKA_HANDLER_TIMEOUT"`
+	ProcessingTimeout     time.Duration     `yaml:"processing_timeout" env:"KAFKA_PROCESSING_TIMEOUT"`
+	LogMessages           bool              `yaml:"log_messages" env:"KAFKA_LOG_MESSAGES"`
+	Partitioner           kafka.Partitioner `yaml:"partitioner" env:"KAFKA_PARTITIONER"`
+	Transactional         bool              `yaml:"transactional" env:"KAFKA_TRANSACTIONAL"`
+	TransactionalIdPrefix string            `yaml:"transactional_id_prefix" env:"KAFKA_TRANSACTIONAL_ID_PREFIX"`
+	// SchemaDir holds the schemas messages are validated against, the bundled schemas by default,
+	// messages are plain json when it is set empty
+	SchemaDir   string            `yaml:"schema_dir" env:"KAFKA_SCHEMA_DIR"`
+	Producer    ProducerConfig    `yaml:"producer"`
+	CloudEvents CloudEventsConfig `yaml:"cloud_events"`
+	Topics      TopicConfig       `yaml:"topics"`
+	Retry       RetryConfig       `yaml:"retry"`
+}
+
+// CloudEventsConfig.Mode wraps decrease.point.success in a CloudEvents envelope with Source, none sends
+// the bare event
+type CloudEventsConfig struct {
+	Mode   kafka.CloudEventsMode `yaml:"mode" env:"KAFKA_CLOUD_EVENTS_MODE"`
+	Source string                `yaml:"source" env:"KAFKA_CLOUD_EVENTS_SOURCE"`
+}
+
+// ProducerConfig.Async makes the outbox relay queue its batch on an async producer and wait for it
+// once, or once more per order with several events in the batch, the other settings only apply to
+// the async producer
+type ProducerConfig struct {
+	Async       bool              `yaml:"async" env:"KAFKA_PRODUCER_ASYNC"`
+	BatchSize   int               `yaml:"batch_size" env:"KAFKA_PRODUCER_BATCH_SIZE"`
+	Linger      time.Duration     `yaml:"linger" env:"KAFKA_PRODUCER_LINGER"`
+	Compression kafka.Compression `yaml:"compression" env:"KAFKA_PRODUCER_COMPRESSION"`
+}
+
+func (config ProducerConfig) Batching() kafka.Batching {
+	return kafka.Batching{
+		Size:        config.BatchSize,
+		Linger:      config.Linger,
+		Compression: config.Compression,
+	}
+}
+
+type TopicConfig struct {
+	SuccessOrder         string `yaml:"success_order" env:"KAFKA_TOPIC_SUCCESS_ORDER"`
+	SuccessOrderDlq      string `yaml:"success_order_dlq" env:"KAFKA_TOPIC_SUCCESS_ORDER_DLQ"`
+	OrderCancelled       string `yaml:"order_cancelled" env:"KAFKA_TOPIC_ORDER_CANCELLED"`
+	OrderCancelledDlq    string `yaml:"order_cancelled_dlq" env:"KAFKA_TOPIC_ORDER_CANCELLED_DLQ"`
+	OrderRefunded        string `yaml:"order_refunded" env:"KAFKA_TOPIC_ORDER_REFUNDED"`
+	OrderRefundedDlq     string `yaml:"order_refunded_dlq" env:"KAFKA_TOPIC_ORDER_REFUNDED_DLQ"`
+	DecreasePointSuccess string `yaml:"decrease_point_success" env:"KAFKA_TOPIC_DECREASE_POINT_SUCCESS"`
+	DecreasePointFailed  string `yaml:"decrease_point_failed" env:"KAFKA_TOPIC_DECREASE_POINT_FAILED"`
+	IncreasePointSuccess string `yaml:"increase_point_success" env:"KAFKA_TOPIC_INCREASE_POINT_SUCCESS"`
+}
+
+type RetryConfig struct {
+	MaxAttempts    uint               `yaml:"max_attempts" env:"KAFKA_RETRY_MAX_ATTEMPTS"`
+	InitialBackoff time.Duration      `yaml:"initial_backoff" env:"KAFKA_RETRY_INITIAL_BACKOFF"`
+	MaxBackoff     time.Duration      `yaml:"max_backoff" env:"KAFKA_RETRY_MAX_BACKOFF"`
+	Multiplier     float64            `yaml:"multiplier" env:"KAFKA_RETRY_MULTIPLIER"`
+	Topics         []RetryTopicConfig `yaml:"topics"`
+}
+
+type RetryTopicConfig struct {
+	Topic string        `yaml:"topic"`
+	Delay time.Duration `yaml:"delay"`
+}
+
+// Default returns every setting but the postgres dsn and the admin token, which have to come from the
+// file or POSTGRES_DSN and HTTP_ADMIN_TOKEN.
+func Default() Config {
+	return Config{
+		Point: PointConfig{
+			Strategy:   "optimistic",
+			TierPolicy: "order_total",
+			WaitTime:   time.Millisecond * 100,
+			MaxAttempt: 1000,
+		},
+		Outbox: OutboxConfig{
+			BatchSize:       100,
+			PollInterval:    time.Millisecond * 500,
+			CleanupInterval: time.Hour,
+			Retention:       time.Hour * 24,
+		},
+		Redemption: RedemptionConfig{
+			ReservationTtl: time.Minute * 15,
+			SweepInterval:  time.Minute,
+			SweepBatchSize: 100,
+		},
+		Kafka: KafkaConfig{
+			Brokers:               []string{"localhost:9092"},
+			ConsumerGroupId:       "point-service",
+			HandlerTimeout:        time.Second * 30,
+			ProcessingTimeout:     time.Minute * 2,
+			Partitioner:           kafka.Murmur2Partitioner,
+			TransactionalIdPrefix: "point-service",
+			SchemaDir:             "schemas",
+			Producer: ProducerConfig{
+				BatchSize:   100,
+				Linger:      time.Millisecond * 5,
+				Compression: kafka.SnappyCompression,
+			},
+			CloudEvents: CloudEventsConfig{
+				Mode:   kafka.NoCloudEvents,
+				Source: "/point-service",
+			},
+			Topics: TopicConfig{
+				SuccessOrder:         "success.order",
+				SuccessOrderDlq:      "success.order.dlq",
+				OrderCancelled:       "order.cancelled",
+				OrderCancelledDlq:    "order.cancelled.dlq",
+				OrderRefunded:        "order.refunded",
+				OrderRefundedDlq:     "order.refunded.dlq",
+				DecreasePointSuccess: "decrease.point.success",
+				DecreasePointFailed:  "decrease.point.failed",
+				IncreasePointSuccess: "increase.point.success",
+			},
+			Retry: RetryConfig{
+				MaxAttempts:    3,
+				InitialBackoff: time.Millisecond * 200,
+				MaxBackoff:     time.Second * 2,
+				Multiplier:     2,
+				Topics: []RetryTopicConfig{
+					{Topic: "success.order.retry.5s", Delay: time.Second * 5},
+					{Topic: "success.order.retry.1m", Delay: time.Minute},
+					{Topic: "success.order.retry.10m", Delay: time.Minute * 10},
+				},
+			},
+		},
+		Http: HttpConfig{
+			Addr:            ":8080",
+			ReadTimeout:     time.Second * 5,
+			WriteTimeout:    time.Second * 10,
+			ShutdownTimeout: time.Second * 10,
+		},
+	}
+}
+
+// Load reads the yaml or json file at path over the defaults, applies environment
+// variable overrides and validates the result. An empty path only uses the environment.
+func Load(path string) (Config, error) {
+	config := Default()
+
+	if path != "" {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return config, errors.Wrap(err, "read config file error")
+		}
+
+		// json is valid yaml, one decoder reads both
+		err = yaml.Unmarshal(content, &config)
+		if err != nil {
+			return config, errors.Wrapf(err, "parse config file %s error", path)
+		}
+	}
+
+	err := applyEnv(reflect.ValueOf(&config).Elem())
+	if err != nil {
+		return config, err
+	}
+
+	err = config.Validate()
+	if err != nil {
+		return config, err
+	}
+
+	return config, nil
+}
+
+func (config Config) Validate() error {
+	var problems []string
+
+	if config.Postgres.Dsn == "" {
+		problems = append(problems, "postgres.dsn is required")
+	}
+	if config.Point.WaitTime <= 0 {
+		problems = append(problems, "point.wait_time must be greater than 0")
+	}
+	if config.Point.MaxAttempt == 0 {
+		problems = append(problems, "point.max_attempt must be greater than 0")
+	}
+	if config.Outbox.BatchSize <= 0 {
+		problems = append(problems, "outbox.batch_size must be greater than 0")
+	}
+	if config.Outbox.PollInterval <= 0 {
+		problems = append(problems, "outbox.poll_interval must be greater than 0")
+	}
+	if config.Outbox.CleanupInterval <= 0 {
+		problems = append(problems, "outbox.cleanup_interval must be greater than 0")
+	}
+	if config.Outbox.Retention <= 0 {
+		problems = append(problems, "outbox.retention must be greater than 0")
+	}
+	if config.Redemption.ReservationTtl <= 0 {
+		problems = append(problems, "redemption.reservation_ttl must be greater than 0")
+	}
+	if config.Redemption.SweepInterval <= 0 {
+		problems = append(problems, "redemption.sweep_interval must be greater than 0")
+	}
+	if config.Redemption.SweepBatchSize <= 0 {
+		problems = append(problems, "redemption.sweep_batch_size must be greater than 0")
+	}
+	if len(config.Kafka.Brokers) == 0 {
+		problems = append(problems, "kafka.brokers is required")
+	}
+	if config.Kafka.ConsumerGroupId == "" {
+		problems = append(problems, "kafka.consumer_group_id is required")
+	}
+	if config.Kafka.HandlerTimeout <= 0 {
+		problems = append(problems, "kafka.handler_timeout must be greater than 0")
+	}
+	if !config.Kafka.Partitioner.Valid() {
+		problems = append(problems, fmt.Sprintf("kafka.partitioner must be one of %s, %s or %s", kafka.HashPartitioner, kafka.Murmur2Partitioner, kafka.RoundRobinPartitioner))
+	}
+	if config.Kafka.Transactional && config.Kafka.TransactionalIdPrefix == "" {
+		problems = append(problems, "kafka.transactional_id_prefix is required when kafka.transactional is set")
+	}
+	if config.Kafka.Producer.BatchSize <= 0 {
+		problems = append(problems, "kafka.producer.batch_size must be greater than 0")
+	}
+	if config.Kafka.Producer.Linger < 0 {
+		problems = append(problems, "kafka.producer.linger must not be negative")
+	}
+	if !config.Kafka.Producer.Compression.Valid() {
+		problems = append(problems, fmt.Sprintf("kafka.producer.compression must be one of %s, %s, %s, %s or %s", kafka.NoCompression, kafka.GzipCompression, kafka.SnappyCompression, kafka.Lz4Compression, kafka.ZstdCompression))
+	}
+	if !config.Kafka.CloudEvents.Mode.Valid() {
+		problems = append(problems, fmt.Sprintf("kafka.cloud_events.mode must be one of %s, %s or %s", kafka.NoCloudEvents, kafka.BinaryCloudEvents, kafka.StructuredCloudEvents))
+	}
+	if config.Kafka.CloudEvents.Mode != kafka.NoCloudEvents && config.Kafka.CloudEvents.Source == "" {
+		problems = append(problems, "kafka.cloud_events.source is required when kafka.cloud_events.mode is set")
+	}
+	if config.Kafka.ProcessingTimeout < config.Kafka.HandlerTimeout {
+		problems = append(problems, "kafka.processing_timeout must not be less than kafka.handler_timeout")
+	}
+	if config.Kafka.Topics.SuccessOrder == "" {
+		problems = append(problems, "kafka.topics.success_order is required")
+	}
+	if config.Kafka.Topics.SuccessOrderDlq == "" {
+		problems = append(problems, "kafka.topics.success_order_dlq is required")
+	}
+	if config.Kafka.Topics.OrderCancelled == "" {
+		problems = append(problems, "kafka.topics.order_cancelled is required")
+	}
+	if config.Kafka.Topics.OrderCancelledDlq == "" {
+		problems = append(problems, "kafka.topics.order_cancelled_dlq is required")
+	}
+	if config.Kafka.Topics.OrderRefunded == "" {
+		problems = append(problems, "kafka.topics.order_refunded is required")
+	}
+	if config.Kafka.Topics.OrderRefundedDlq == "" {
+		problems = append(problems, "kafka.topics.order_refunded_dlq is required")
+	}
+	if config.Kafka.Topics.DecreasePointSuccess == "" {
+		problems = append(problems, "kafka.topics.decrease_point_success is required")
+	}
+	if config.Kafka.Topics.DecreasePointFailed == "" {
+		problems = append(problems, "kafka.topics.decrease_point_failed is required")
+	}
+	if config.Kafka.Topics.IncreasePointSuccess == "" {
+		problems = append(problems, "kafka.topics.increase_point_success is required")
+	}
+	if config.Kafka.Retry.MaxAttempts == 0 {
+		problems = append(problems, "kafka.retry.max_attempts must be greater than 0")
+	}
+	if config.Kafka.Retry.Multiplier < 1 {
+		problems = append(problems, "kafka.retry.multiplier must be at least 1")
+	}
+	for i, retryTopic := range config.Kafka.Retry.Topics {
+		if retryTopic.Topic == "" {
+			problems = append(problems, fmt.Sprintf("kafka.retry.topics[%d].topic is required", i))
+		}
+		if retryTopic.Delay <= 0 {
+			problems = append(problems, fmt.Sprintf("kafka.retry.topics[%d].delay must be greater than 0", i))
+		}
+	}
+	if config.Http.Addr == "" {
+		problems = append(problems, "http.addr is required")
+	}
+	if config.Http.AdminToken == "" {
+		problems = append(problems, "http.admin_token is required")
+	}
+	if config.Http.UserTokenSecret == "" {
+		problems = append(problems, "http.user_token_secret is required")
+	}
+	if config.Http.ReadTimeout <= 0 {
+		problems = append(problems, "http.read_timeout must be greater than 0")
+	}
+	if config.Http.WriteTimeout <= 0 {
+		problems = append(problems, "http.write_timeout must be greater than 0")
+	}
+	if config.Http.ShutdownTimeout <= 0 {
+		problems = append(problems, "http.shutdown_timeout must be greater than 0")
+	}
+
+	if len(problems) > 0 {
+		return errors.Errorf("invalid config: %s", strings.Join(problems, "; "))
+	}
+
+	return nil
+}
+
+// Timeouts is how long a handler attempt and all attempts of a message may take, see kafka.Timeouts
+func (config KafkaConfig) Timeouts() kafka.Timeouts {
+	return kafka.Timeouts{
+		Handler:    config.HandlerTimeout,
+		Processing: config.ProcessingTimeout,
+	}
+}
+
+func (config RetryConfig) Policy() kafka.RetryPolicy {
+	retryTopics := []kafka.RetryTopic{}
+	for _, retryTopic := range config.Topics {
+		retryTopics = append(retryTopics, kafka.RetryTopic{
+			Topic: retryTopic.Topic,
+			Delay: retryTopic.Delay,
+		})
+	}
+
+	return kafka.RetryPolicy{
+		MaxAttempts:    config.MaxAttempts,
+		InitialBackoff: config.InitialBackoff,
+		MaxBackoff:     config.MaxBackoff,
+		Multiplier:     config.Multiplier,
+		RetryTopics:    retryTopics,
+	}
+}
+
+// InProcessPolicy retries with backoff like Policy but without retry topics,
+// a message that still fails goes straight to the dead letter topic
+func (config RetryConfig) InProcessPolicy() kafka.RetryPolicy {
+	policy := config.Policy()
+	policy.RetryTopics = nil
+
+	return policy
+}
+
+// applyEnv overrides every field tagged with env whose variable is set
+func applyEnv(value reflect.Value) error {
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Field(i)
+		structField := value.Type().Field(i)
+
+		if field.Kind() == reflect.Struct {
+			err := applyEnv(field)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		name, ok := structField.Tag.Lookup("env")
+		if !ok {
+			continue
+		}
+
+		raw, ok := os.LookupEnv(name)
+		if !ok {
+			continue
+		}
+
+		err := setField(field, raw)
+		if err != nil {
+			return errors.Wrapf(err, "invalid value of %s", name)
+		}
+	}
+
+	return nil
+}
+
+func setField(field reflect.Value, raw string) error {
+	if field.Type() == reflect.TypeOf(time.Duration(0)) {
+		duration, err := time.ParseDuration(raw)
+		if err != nil {
+			return err
+		}
+
+		field.SetInt(int64(duration))
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(raw)
+
+	case reflect.Int, reflect.Int64:
+		number, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return err
+		}
+		field.SetInt(number)
+
+	case reflect.Uint, reflect.Uint64:
+		number, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			return err
+		}
+		field.SetUint(number)
+
+	case reflect.Float64:
+		number, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return err
+		}
+		field.SetFloat(number)
+
+	case reflect.Bool:
+		boolean, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		field.SetBool(boolean)
+
+	case reflect.Slice:
+		// comma separated list of strings
+		items := []string{}
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		field.Set(reflect.ValueOf(items))
+
+	default:
+		return errors.Errorf("unsupported field type %s", field.Type())
+	}
+
+	return nil
+}
@@ -0,0 +1,218 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"point-service/app/internal/config"
+	"point-service/app/pkg/kafka"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ConfigTestSuite struct {
+	suite.Suite
+}
+
+func (suite *ConfigTestSuite) SetupTest() {}
+
+func (suite *ConfigTestSuite) writeFile(name string, content string) string {
+	path := filepath.Join(suite.T().TempDir(), name)
+
+	err := os.WriteFile(path, []byte(content), 0o600)
+	if err != nil {
+		panic(err)
+	}
+
+	return path
+}
+
+func (suite *ConfigTestSuite) TestConfig_HappyCase_Yaml() {
+	path := suite.writeFile("config.yaml", `
+postgres:
+  dsn: host=localhost dbname=point
+point:
+  strategy: atomic
+  tier_policy: per_line
+  wait_time: 50ms
+  max_attempt: 10
+kafka:
+  brokers:
+    - broker-1:9092
+    - broker-2:9092
+  retry:
+    topics:
+      - topic: success.order.retry.30s
+        delay: 30s
+http:
+  admin_token: admin-secret
+  user_token_secret: user-secret
+`)
+
+	cfg, err := config.Load(path)
+	suite.Nil(err)
+	suite.Equal("host=localhost dbname=point", cfg.Postgres.Dsn)
+	suite.Equal("atomic", cfg.Point.Strategy)
+	suite.Equal("per_line", cfg.Point.TierPolicy)
+	suite.Equal(time.Millisecond*50, cfg.Point.WaitTime)
+	suite.Equal(uint(10), cfg.Point.MaxAttempt)
+	suite.Equal([]string{"broker-1:9092", "broker-2:9092"}, cfg.Kafka.Brokers)
+	suite.Equal("schemas", cfg.Kafka.SchemaDir)
+	suite.Equal([]config.RetryTopicConfig{{Topic: "success.order.retry.30s", Delay: time.Second * 30}}, cfg.Kafka.Retry.Topics)
+	suite.Equal("admin-secret", cfg.Http.AdminToken)
+	suite.Equal("user-secret", cfg.Http.UserTokenSecret)
+
+	// unset values keep their default
+	suite.Equal("point-service", cfg.Kafka.ConsumerGroupId)
+	suite.Equal("success.order", cfg.Kafka.Topics.SuccessOrder)
+	suite.Equal("order.cancelled", cfg.Kafka.Topics.OrderCancelled)
+	suite.Equal("order.refunded", cfg.Kafka.Topics.OrderRefunded)
+	suite.Equal("increase.point.success", cfg.Kafka.Topics.IncreasePointSuccess)
+	suite.Equal(time.Minute*15, cfg.Redemption.ReservationTtl)
+}
+
+func (suite *ConfigTestSuite) TestConfig_HappyCase_Json() {
+	path := suite.writeFile("config.json", `{
+		"postgres": {"dsn": "host=localhost dbname=point"},
+		"kafka": {"consumer_group_id": "point-service-json"},
+		"http": {"admin_token": "admin-secret", "user_token_secret": "user-secret"}
+	}`)
+
+	cfg, err := config.Load(path)
+	suite.Nil(err)
+	suite.Equal("host=localhost dbname=point", cfg.Postgres.Dsn)
+	suite.Equal("point-service-json", cfg.Kafka.ConsumerGroupId)
+}
+
+func (suite *ConfigTestSuite) TestConfig_HappyCase_EnvOverride() {
+	path := suite.writeFile("config.yaml", `
+postgres:
+  dsn: host=localhost dbname=point
+`)
+	suite.T().Setenv("POSTGRES_DSN", "host=db dbname=point")
+	suite.T().Setenv("POINT_WAIT_TIME", "1s")
+	suite.T().Setenv("POINT_MAX_ATTEMPT", "5")
+	suite.T().Setenv("KAFKA_BROKERS", "kafka-1:9092, kafka-2:9092")
+	suite.T().Setenv("KAFKA_RETRY_MULTIPLIER", "1.5")
+	suite.T().Setenv("HTTP_ADDR", ":9090")
+	suite.T().Setenv("HTTP_ADMIN_TOKEN", "env-secret")
+	suite.T().Setenv("HTTP_USER_TOKEN_SECRET", "env-user-secret")
+	suite.T().Setenv("KAFKA_LOG_MESSAGES", "true")
+	suite.T().Setenv("KAFKA_PROCESSING_TIMEOUT", "5m")
+	suite.T().Setenv("KAFKA_PARTITIONER", "round_robin")
+	suite.T().Setenv("KAFKA_PRODUCER_ASYNC", "true")
+	suite.T().Setenv("KAFKA_TRANSACTIONAL", "true")
+	suite.T().Setenv("KAFKA_PRODUCER_LINGER", "20ms")
+	suite.T().Setenv("KAFKA_SCHEMA_DIR", "/etc/point-service/schemas")
+	suite.T().Setenv("KAFKA_CLOUD_EVENTS_MODE", "binary")
+	suite.T().Setenv("REDEMPTION_RESERVATION_TTL", "5m")
+
+	cfg, err := config.Load(path)
+	suite.Nil(err)
+	suite.Equal("host=db dbname=point", cfg.Postgres.Dsn)
+	suite.Equal(time.Second, cfg.Point.WaitTime)
+	suite.Equal(uint(5), cfg.Point.MaxAttempt)
+	suite.Equal([]string{"kafka-1:9092", "kafka-2:9092"}, cfg.Kafka.Brokers)
+	suite.Equal(1.5, cfg.Kafka.Retry.Multiplier)
+	suite.Equal(":9090", cfg.Http.Addr)
+	suite.Equal("env-secret", cfg.Http.AdminToken)
+	suite.Equal("env-user-secret", cfg.Http.UserTokenSecret)
+	suite.True(cfg.Kafka.LogMessages)
+	suite.Equal(time.Minute*5, cfg.Kafka.ProcessingTimeout)
+	suite.Equal(kafka.RoundRobinPartitioner, cfg.Kafka.Partitioner)
+	suite.Equal(kafka.Batching{Size: 100, Linger: time.Millisecond * 20, Compression: kafka.SnappyCompression}, cfg.Kafka.Producer.Batching())
+	suite.True(cfg.Kafka.Producer.Async)
+	suite.True(cfg.Kafka.Transactional)
+	suite.Equal("point-service", cfg.Kafka.TransactionalIdPrefix)
+	suite.Equal("/etc/point-service/schemas", cfg.Kafka.SchemaDir)
+	suite.Equal(config.CloudEventsConfig{Mode: kafka.BinaryCloudEvents, Source: "/point-service"}, cfg.Kafka.CloudEvents)
+	suite.Equal(time.Minute*5, cfg.Redemption.ReservationTtl)
+}
+
+func (suite *ConfigTestSuite) TestConfig_InvalidEnv() {
+	suite.T().Setenv("POSTGRES_DSN", "host=db dbname=point")
+	suite.T().Setenv("POINT_MAX_ATTEMPT", "many")
+
+	_, err := config.Load("")
+	suite.ErrorContains(err, "POINT_MAX_ATTEMPT")
+}
+
+func (suite *ConfigTestSuite) TestConfig_FileNotFound() {
+	_, err := config.Load(filepath.Join(suite.T().TempDir(), "missing.yaml"))
+	suite.NotNil(err)
+}
+
+func (suite *ConfigTestSuite) TestConfig_ParseError() {
+	path := suite.writeFile("config.yaml", `postgres: [`)
+
+	_, err := config.Load(path)
+	suite.NotNil(err)
+}
+
+func (suite *ConfigTestSuite) TestConfig_ValidateError() {
+	path := suite.writeFile("config.yaml", `
+point:
+  max_attempt: 0
+redemption:
+  sweep_batch_size: 0
+kafka:
+  brokers: []
+  handler_timeout: 0s
+  processing_timeout: -1s
+  partitioner: random
+  transactional: true
+  transactional_id_prefix: ""
+  producer:
+    batch_size: 0
+    compression: brotli
+  cloud_events:
+    mode: envelope
+    source: ""
+  retry:
+    topics:
+      - topic: ""
+        delay: 0s
+http:
+  addr: ""
+  shutdown_timeout: 0s
+`)
+
+	_, err := config.Load(path)
+	suite.ErrorContains(err, "postgres.dsn is required")
+	suite.ErrorContains(err, "point.max_attempt must be greater than 0")
+	suite.ErrorContains(err, "redemption.sweep_batch_size must be greater than 0")
+	suite.ErrorContains(err, "kafka.brokers is required")
+	suite.ErrorContains(err, "kafka.handler_timeout must be greater than 0")
+	suite.ErrorContains(err, "kafka.partitioner must be one of hash, murmur2 or round_robin")
+	suite.ErrorContains(err, "kafka.transactional_id_prefix is required when kafka.transactional is set")
+	suite.ErrorContains(err, "kafka.producer.batch_size must be greater than 0")
+	suite.ErrorContains(err, "kafka.producer.compression must be one of none, gzip, snappy, lz4 or zstd")
+	suite.ErrorContains(err, "kafka.cloud_events.mode must be one of none, binary or structured")
+	suite.ErrorContains(err, "kafka.cloud_events.source is required when kafka.cloud_events.mode is set")
+	suite.ErrorContains(err, "kafka.processing_timeout must not be less than kafka.handler_timeout")
+	suite.ErrorContains(err, "kafka.retry.topics[0].topic is required")
+	suite.ErrorContains(err, "kafka.retry.topics[0].delay must be greater than 0")
+	suite.ErrorContains(err, "http.addr is required")
+	suite.ErrorContains(err, "http.admin_token is required")
+	suite.ErrorContains(err, "http.user_token_secret is required")
+	suite.ErrorContains(err, "http.shutdown_timeout must be greater than 0")
+}
+
+func (suite *ConfigTestSuite) TestConfig_Timeouts() {
+	suite.Equal(kafka.Timeouts{Handler: time.Second * 30, Processing: time.Minute * 2}, config.Default().Kafka.Timeouts())
+}
+
+func (suite *ConfigTestSuite) TestConfig_RetryPolicy() {
+	policy := config.Default().Kafka.Retry.Policy()
+	suite.Equal(uint(3), policy.MaxAttempts)
+	suite.Equal([]string{"success.order.retry.5s", "success.order.retry.1m", "success.order.retry.10m"}, policy.Topics())
+
+	policy = config.Default().Kafka.Retry.InProcessPolicy()
+	suite.Equal(uint(3), policy.MaxAttempts)
+	suite.Empty(policy.Topics())
+}
+
+func TestConfigTestSuite(t *testing.T) {
+	suite.Run(t, new(ConfigTestSuite))
+}
@@ -0,0 +1,84 @@
+package handler
+
+import (
+	"context"
+	"crypto/subtle"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+type userIdKey struct{}
+
+// RequireAdminToken serves next only to a request with the bearer token, the token is compared in
+// constant time so its length and content do not leak through the response time
+func RequireAdminToken(token string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		bearer, ok := bearerToken(r)
+		if !ok || subtle.ConstantTimeCompare([]byte(bearer), []byte(token)) != 1 {
+			writeUnauthorized(w)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// RequireUserToken serves next only to a request with a bearer JWT signed with HS256 by secret that
+// has an expiry and the id of the user as subject, the user id is put in the request context
+func RequireUserToken(secret string, next http.Handler) http.Handler {
+	parser := jwt.NewParser(jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithExpirationRequired())
+	keyFunc := func(*jwt.Token) (interface{}, error) {
+		return []byte(secret), nil
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		bearer, ok := bearerToken(r)
+		if !ok {
+			writeUnauthorized(w)
+			return
+		}
+
+		token, err := parser.Parse(bearer, keyFunc)
+		if err != nil {
+			writeUnauthorized(w)
+			return
+		}
+
+		subject, err := token.Claims.GetSubject()
+		if err != nil {
+			writeUnauthorized(w)
+			return
+		}
+
+		userId, err := strconv.ParseUint(subject, 10, 0)
+		if err != nil || userId == 0 {
+			writeUnauthorized(w)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), userIdKey{}, uint(userId))))
+	})
+}
+
+// userIdFromContext returns the user id RequireUserToken authenticated
+func userIdFromContext(ctx context.Context) (uint, bool) {
+	userId, ok := ctx.Value(userIdKey{}).(uint)
+	return userId, ok
+}
+
+func bearerToken(r *http.Request) (string, bool) {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") || token == "" {
+		return "", false
+	}
+
+	return token, true
+}
+
+func writeUnauthorized(w http.ResponseWriter) {
+	w.Header().Set("WWW-Authenticate", "Bearer")
+	writeError(w, http.StatusUnauthorized, errUnauthorized)
+}
@@ -0,0 +1,98 @@
+package handler_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"point-service/app/internal/handler"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/suite"
+)
+
+type AuthTestSuite struct {
+	suite.Suite
+	handler http.Handler
+}
+
+func (suite *AuthTestSuite) SetupTest() {
+	suite.handler = handler.RequireAdminToken("admin-secret", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+}
+
+func (suite *AuthTestSuite) serve(authorization string) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(http.MethodDelete, "/admin/points/gold", nil)
+	if authorization != "" {
+		request.Header.Set("Authorization", authorization)
+	}
+	recorder := httptest.NewRecorder()
+	suite.handler.ServeHTTP(recorder, request)
+
+	return recorder
+}
+
+func (suite *AuthTestSuite) TestRequireAdminToken_HappyCase() {
+	suite.Equal(http.StatusNoContent, suite.serve("Bearer admin-secret").Code)
+	suite.Equal(http.StatusNoContent, suite.serve("bearer admin-secret").Code)
+}
+
+func (suite *AuthTestSuite) TestRequireAdminToken_Unauthorized() {
+	for _, authorization := range []string{"", "Bearer", "Bearer ", "Bearer admin", "Basic admin-secret", "admin-secret"} {
+		recorder := suite.serve(authorization)
+		suite.Equal(http.StatusUnauthorized, recorder.Code, authorization)
+		suite.Equal("Bearer", recorder.Header().Get("WWW-Authenticate"))
+		suite.JSONEq(`{"error":"unauthorized"}`, recorder.Body.String())
+	}
+}
+
+func (suite *AuthTestSuite) TestRequireUserToken_HappyCase() {
+	userHandler := handler.RequireUserToken("user-secret", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	request := httptest.NewRequest(http.MethodGet, "/redemptions/10", nil)
+	request.Header.Set("Authorization", "Bearer "+userToken("user-secret", "42", time.Now().Add(time.Hour)))
+	recorder := httptest.NewRecorder()
+	userHandler.ServeHTTP(recorder, request)
+
+	suite.Equal(http.StatusNoContent, recorder.Code)
+}
+
+func (suite *AuthTestSuite) TestRequireUserToken_Unauthorized() {
+	userHandler := handler.RequireUserToken("user-secret", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	noExpiry, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{Subject: "42"}).SignedString([]byte("user-secret"))
+	none, _ := jwt.NewWithClaims(jwt.SigningMethodNone, jwt.RegisteredClaims{
+		Subject:   "42",
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+	}).SignedString(jwt.UnsafeAllowNoneSignatureType)
+
+	for name, token := range map[string]string{
+		"missing":      "",
+		"other secret": userToken("other-secret", "42", time.Now().Add(time.Hour)),
+		"expired":      userToken("user-secret", "42", time.Now().Add(-time.Minute)),
+		"no expiry":    noExpiry,
+		"none alg":     none,
+		"no subject":   userToken("user-secret", "", time.Now().Add(time.Hour)),
+		"zero subject": userToken("user-secret", "0", time.Now().Add(time.Hour)),
+		"text subject": userToken("user-secret", "alice", time.Now().Add(time.Hour)),
+		"not a jwt":    "user-secret",
+	} {
+		request := httptest.NewRequest(http.MethodGet, "/redemptions/10", nil)
+		if token != "" {
+			request.Header.Set("Authorization", "Bearer "+token)
+		}
+		recorder := httptest.NewRecorder()
+		userHandler.ServeHTTP(recorder, request)
+
+		suite.Equal(http.StatusUnauthorized, recorder.Code, name)
+		suite.Equal("Bearer", recorder.Header().Get("WWW-Authenticate"), name)
+	}
+}
+
+func TestAuthTestSuite(t *testing.T) {
+	suite.Run(t, new(AuthTestSuite))
+}
@@ -0,0 +1,66 @@
+package handler
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/pkg/errors"
+)
+
+// maxRequestBodySize is large enough for any admin request body
+const maxRequestBodySize = 1 << 20
+
+var (
+	errNotFound         = errors.New("not found")
+	errMethodNotAllowed = errors.New("method not allowed")
+	errUnauthorized     = errors.New("unauthorized")
+)
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	err := json.NewEncoder(w).Encode(body)
+	if err != nil {
+		log.Printf("write response body error: %s", err.Error())
+	}
+}
+
+// writeError responds with the message of err, a server error is logged and
+// answered with a generic message so database details do not leak
+func writeError(w http.ResponseWriter, status int, err error) {
+	message := err.Error()
+	if status >= http.StatusInternalServerError {
+		log.Printf("http handler error: %s", message)
+		message = http.StatusText(status)
+	}
+
+	writeJSON(w, status, errorResponse{Error: message})
+}
+
+func writeMethodNotAllowed(w http.ResponseWriter, allowed string) {
+	w.Header().Set("Allow", allowed)
+	writeError(w, http.StatusMethodNotAllowed, errMethodNotAllowed)
+}
+
+// decodeJSON reads the request body into v, unknown fields and trailing data are rejected
+func decodeJSON(r *http.Request, v interface{}) error {
+	decoder := json.NewDecoder(http.MaxBytesReader(nil, r.Body, maxRequestBodySize))
+	decoder.DisallowUnknownFields()
+
+	err := decoder.Decode(v)
+	if err != nil {
+		return errors.Wrap(err, "invalid request body")
+	}
+
+	if decoder.More() {
+		return errors.New("invalid request body: unexpected data after the json object")
+	}
+
+	return nil
+}
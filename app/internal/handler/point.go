@@ -2,41 +2,96 @@ package handler
 
 import (
 	"context"
-	"encoding/json"
 	"log"
 	"point-service/app/internal/model"
 	"point-service/app/internal/service"
+	"point-service/app/pkg/kafka"
 
-	"github.com/IBM/sarama"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
 )
 
+// PointHandler handles decoded order events, see kafka.Decode. A handler returns a retryable
+// error for a transient failure, any other error sends the message to the dead letter topic.
 type PointHandler interface {
-	SuccessOrderProcess(ctx context.Context, message *sarama.ConsumerMessage) error
+	SuccessOrderProcess(ctx context.Context, successOrder model.SuccessOrder) error
+	OrderCancelledProcess(ctx context.Context, orderCancelled model.OrderCancelled) error
+	OrderRefundedProcess(ctx context.Context, orderRefunded model.OrderRefunded) error
 }
 
 type pointHandler struct {
-	pointService service.PointService
+	pointService      service.PointService
+	redemptionService service.RedemptionService
 }
 
-func NewPointHandler(pointService service.PointService) PointHandler {
+func NewPointHandler(pointService service.PointService, redemptionService service.RedemptionService) PointHandler {
 	return &pointHandler{
-		pointService: pointService,
+		pointService:      pointService,
+		redemptionService: redemptionService,
 	}
 }
 
-func (handler *pointHandler) SuccessOrderProcess(ctx context.Context, message *sarama.ConsumerMessage) error {
-	var successOrder model.SuccessOrder
-	err := json.Unmarshal(message.Value, &successOrder)
-	if err != nil {
-		log.Printf("unmarshal message value error: %s", err.Error())
+func (handler *pointHandler) SuccessOrderProcess(ctx context.Context, successOrder model.SuccessOrder) error {
+	err := handler.pointService.DecreasePoint(ctx, successOrder)
+	// the reservation of a cancelled order was given back with the cancellation
+	if errors.Is(err, service.ErrOrderCancelled) {
 		return nil
 	}
-
-	err = handler.pointService.DecreasePoint(ctx, successOrder)
 	if err != nil {
 		log.Printf("decrease point error: %s", err.Error())
+		return transientOrPermanent(errors.Wrap(err, "decrease point error"))
+	}
+
+	// points are reserved by a user, an order without user or paid without points has no
+	// reservation to confirm
+	if successOrder.UserId == 0 {
 		return nil
 	}
+	_, err = handler.redemptionService.Confirm(ctx, successOrder.UserId, successOrder.OrderId)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		log.Printf("confirm reservation error: %s", err.Error())
+		return transientOrPermanent(errors.Wrap(err, "confirm reservation error"))
+	}
+
+	return nil
+}
+
+// OrderCancelledProcess gives the points of the cancelled order back to the pools and the points
+// reserved or spent for it back to the users
+func (handler *pointHandler) OrderCancelledProcess(ctx context.Context, orderCancelled model.OrderCancelled) error {
+	err := handler.restorePoint(ctx, orderCancelled.OrderId)
+	if err != nil {
+		return err
+	}
+
+	err = handler.redemptionService.Cancel(ctx, orderCancelled.OrderId)
+	if err != nil {
+		log.Printf("cancel reservation error: %s", err.Error())
+		return transientOrPermanent(errors.Wrap(err, "cancel reservation error"))
+	}
 
 	return nil
 }
+
+// OrderRefundedProcess gives the points of the refunded order back to the pools
+func (handler *pointHandler) OrderRefundedProcess(ctx context.Context, orderRefunded model.OrderRefunded) error {
+	return handler.restorePoint(ctx, orderRefunded.OrderId)
+}
+
+func (handler *pointHandler) restorePoint(ctx context.Context, orderId uint) error {
+	err := handler.pointService.RestorePoint(ctx, orderId)
+	if err != nil {
+		log.Printf("restore point error: %s", err.Error())
+		return transientOrPermanent(errors.Wrap(err, "restore point error"))
+	}
+
+	return nil
+}
+
+func transientOrPermanent(err error) error {
+	if service.IsTransientError(err) {
+		return kafka.Retryable(err)
+	}
+
+	return err
+}
@@ -0,0 +1,222 @@
+package handler
+
+import (
+	"net/http"
+	"point-service/app/internal/model"
+	"point-service/app/internal/service"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+const pointPoolPath = "/admin/points"
+
+type PointPoolHandler interface {
+	RegisterRoutes(mux *http.ServeMux)
+}
+
+type pointPoolHandler struct {
+	pointPoolService service.PointPoolService
+}
+
+func NewPointPoolHandler(pointPoolService service.PointPoolService) PointPoolHandler {
+	return &pointPoolHandler{
+		pointPoolService: pointPoolService,
+	}
+}
+
+type pointPoolResponse struct {
+	Level     string    `json:"level"`
+	Remaining uint      `json:"remaining"`
+	Version   uint      `json:"version"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type createPointPoolRequest struct {
+	Level     string `json:"level"`
+	Remaining *uint  `json:"remaining"`
+}
+
+type topUpPointPoolRequest struct {
+	Amount uint `json:"amount"`
+}
+
+type setPointPoolRequest struct {
+	Remaining *uint `json:"remaining"`
+}
+
+// RegisterRoutes serves
+//
+//	GET    /admin/points                list every level
+//	POST   /admin/points                create a level
+//	GET    /admin/points/{level}        get a level
+//	PUT    /admin/points/{level}        set the remaining of a level
+//	DELETE /admin/points/{level}        soft delete a level
+//	POST   /admin/points/{level}/top-up add to the remaining of a level
+func (handler *pointPoolHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc(pointPoolPath, handler.pointPools)
+	mux.HandleFunc(pointPoolPath+"/", handler.pointPool)
+}
+
+func (handler *pointPoolHandler) pointPools(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		handler.listPointPools(w, r)
+	case http.MethodPost:
+		handler.createPointPool(w, r)
+	default:
+		writeMethodNotAllowed(w, "GET, POST")
+	}
+}
+
+func (handler *pointPoolHandler) pointPool(w http.ResponseWriter, r *http.Request) {
+	level, action, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, pointPoolPath+"/"), "/")
+	if level == "" || strings.Contains(action, "/") {
+		writeError(w, http.StatusNotFound, errNotFound)
+		return
+	}
+
+	switch action {
+	case "":
+		switch r.Method {
+		case http.MethodGet:
+			handler.getPointPool(w, r, level)
+		case http.MethodPut:
+			handler.setPointPool(w, r, level)
+		case http.MethodDelete:
+			handler.deletePointPool(w, r, level)
+		default:
+			writeMethodNotAllowed(w, "GET, PUT, DELETE")
+		}
+	case "top-up":
+		if r.Method != http.MethodPost {
+			writeMethodNotAllowed(w, "POST")
+			return
+		}
+		handler.topUpPointPool(w, r, level)
+	default:
+		writeError(w, http.StatusNotFound, errNotFound)
+	}
+}
+
+func (handler *pointPoolHandler) listPointPools(w http.ResponseWriter, r *http.Request) {
+	points, err := handler.pointPoolService.ListPointPools(r.Context())
+	if err != nil {
+		writeError(w, pointPoolErrorStatus(err), err)
+		return
+	}
+
+	response := []pointPoolResponse{}
+	for _, point := range points {
+		response = append(response, newPointPoolResponse(point))
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+func (handler *pointPoolHandler) getPointPool(w http.ResponseWriter, r *http.Request, level string) {
+	point, err := handler.pointPoolService.GetPointPool(r.Context(), level)
+	if err != nil {
+		writeError(w, pointPoolErrorStatus(err), err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, newPointPoolResponse(point))
+}
+
+func (handler *pointPoolHandler) createPointPool(w http.ResponseWriter, r *http.Request) {
+	var request createPointPoolRequest
+	err := decodeJSON(r, &request)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if request.Remaining == nil {
+		writeError(w, http.StatusBadRequest, errors.New("remaining is required"))
+		return
+	}
+
+	point, err := handler.pointPoolService.CreatePointPool(r.Context(), request.Level, *request.Remaining)
+	if err != nil {
+		writeError(w, pointPoolErrorStatus(err), err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, newPointPoolResponse(point))
+}
+
+func (handler *pointPoolHandler) setPointPool(w http.ResponseWriter, r *http.Request, level string) {
+	var request setPointPoolRequest
+	err := decodeJSON(r, &request)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if request.Remaining == nil {
+		writeError(w, http.StatusBadRequest, errors.New("remaining is required"))
+		return
+	}
+
+	point, err := handler.pointPoolService.SetPointPoolRemaining(r.Context(), level, *request.Remaining)
+	if err != nil {
+		writeError(w, pointPoolErrorStatus(err), err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, newPointPoolResponse(point))
+}
+
+func (handler *pointPoolHandler) topUpPointPool(w http.ResponseWriter, r *http.Request, level string) {
+	var request topUpPointPoolRequest
+	err := decodeJSON(r, &request)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	point, err := handler.pointPoolService.TopUpPointPool(r.Context(), level, request.Amount)
+	if err != nil {
+		writeError(w, pointPoolErrorStatus(err), err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, newPointPoolResponse(point))
+}
+
+func (handler *pointPoolHandler) deletePointPool(w http.ResponseWriter, r *http.Request, level string) {
+	err := handler.pointPoolService.DeletePointPool(r.Context(), level)
+	if err != nil {
+		writeError(w, pointPoolErrorStatus(err), err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func newPointPoolResponse(point model.Point) pointPoolResponse {
+	return pointPoolResponse{
+		Level:     point.Level,
+		Remaining: point.Remaining,
+		Version:   point.Version,
+		CreatedAt: point.CreatedAt,
+		UpdatedAt: point.UpdatedAt,
+	}
+}
+
+func pointPoolErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrInvalidPointPool):
+		return http.StatusBadRequest
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrPointPoolExists), errors.Is(err, service.ErrPointPoolInUse):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,205 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"point-service/app/internal/handler"
+	"point-service/app/internal/model"
+	"point-service/app/internal/service"
+	mockService "point-service/app/internal/service/mocks"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+)
+
+type PointPoolHandlerTestSuite struct {
+	suite.Suite
+	mux *http.ServeMux
+
+	pointPoolService *mockService.PointPoolService
+}
+
+func (suite *PointPoolHandlerTestSuite) SetupTest() {
+	pointPoolService := new(mockService.PointPoolService)
+	pointPoolService.On("ListPointPools", mock.Anything).Return([]model.Point{{Level: "bronze", Remaining: 10}, {Level: "gold", Remaining: 1}}, nil)
+
+	pointPoolService.On("GetPointPool", mock.Anything, "gold").Return(model.Point{Level: "gold", Remaining: 1}, nil)
+	pointPoolService.On("GetPointPool", mock.Anything, "broken").Return(model.Point{}, errors.New("connection refused"))
+	pointPoolService.On("GetPointPool", mock.Anything, mock.Anything).Return(model.Point{}, gorm.ErrRecordNotFound)
+
+	pointPoolService.On("CreatePointPool", mock.Anything, "platinum", uint(100)).Return(model.Point{Level: "platinum", Remaining: 100}, nil)
+	pointPoolService.On("CreatePointPool", mock.Anything, "gold", mock.Anything).Return(model.Point{}, service.ErrPointPoolExists)
+	pointPoolService.On("CreatePointPool", mock.Anything, mock.Anything, mock.Anything).Return(model.Point{}, fmt.Errorf("%w: level is invalid", service.ErrInvalidPointPool))
+
+	pointPoolService.On("TopUpPointPool", mock.Anything, "gold", uint(5)).Return(model.Point{Level: "gold", Remaining: 6}, nil)
+	pointPoolService.On("TopUpPointPool", mock.Anything, mock.Anything, uint(0)).Return(model.Point{}, fmt.Errorf("%w: amount must be greater than 0", service.ErrInvalidPointPool))
+
+	pointPoolService.On("SetPointPoolRemaining", mock.Anything, "gold", uint(50)).Return(model.Point{Level: "gold", Remaining: 50}, nil)
+	pointPoolService.On("SetPointPoolRemaining", mock.Anything, mock.Anything, mock.Anything).Return(model.Point{}, gorm.ErrRecordNotFound)
+
+	pointPoolService.On("DeletePointPool", mock.Anything, "gold").Return(nil)
+	pointPoolService.On("DeletePointPool", mock.Anything, "silver").Return(fmt.Errorf("%w: tier rule 2 maps prices to silver", service.ErrPointPoolInUse))
+	pointPoolService.On("DeletePointPool", mock.Anything, mock.Anything).Return(gorm.ErrRecordNotFound)
+
+	suite.pointPoolService = pointPoolService
+	suite.mux = http.NewServeMux()
+	handler.NewPointPoolHandler(pointPoolService).RegisterRoutes(suite.mux)
+}
+
+func (suite *PointPoolHandlerTestSuite) serve(method string, path string, body string) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(method, path, strings.NewReader(body))
+	recorder := httptest.NewRecorder()
+	suite.mux.ServeHTTP(recorder, request)
+
+	return recorder
+}
+
+func (suite *PointPoolHandlerTestSuite) errorMessage(recorder *httptest.ResponseRecorder) string {
+	var response struct {
+		Error string `json:"error"`
+	}
+	suite.Nil(json.Unmarshal(recorder.Body.Bytes(), &response))
+
+	return response.Error
+}
+
+func (suite *PointPoolHandlerTestSuite) TestPointPoolHandler_HappyCase_List() {
+	recorder := suite.serve(http.MethodGet, "/admin/points", "")
+	suite.Equal(http.StatusOK, recorder.Code)
+	suite.Equal("application/json", recorder.Header().Get("Content-Type"))
+
+	var response []map[string]interface{}
+	suite.Nil(json.Unmarshal(recorder.Body.Bytes(), &response))
+	suite.Len(response, 2)
+	suite.Equal("bronze", response[0]["level"])
+	suite.Equal(float64(10), response[0]["remaining"])
+}
+
+func (suite *PointPoolHandlerTestSuite) TestPointPoolHandler_HappyCase_Get() {
+	recorder := suite.serve(http.MethodGet, "/admin/points/gold", "")
+	suite.Equal(http.StatusOK, recorder.Code)
+	suite.Contains(recorder.Body.String(), `"level":"gold"`)
+}
+
+func (suite *PointPoolHandlerTestSuite) TestPointPoolHandler_GetNotFound() {
+	recorder := suite.serve(http.MethodGet, "/admin/points/platinum", "")
+	suite.Equal(http.StatusNotFound, recorder.Code)
+	suite.Equal("record not found", suite.errorMessage(recorder))
+}
+
+func (suite *PointPoolHandlerTestSuite) TestPointPoolHandler_GetInternalError() {
+	recorder := suite.serve(http.MethodGet, "/admin/points/broken", "")
+	suite.Equal(http.StatusInternalServerError, recorder.Code)
+	suite.Equal("Internal Server Error", suite.errorMessage(recorder))
+}
+
+func (suite *PointPoolHandlerTestSuite) TestPointPoolHandler_HappyCase_Create() {
+	recorder := suite.serve(http.MethodPost, "/admin/points", `{"level":"platinum","remaining":100}`)
+	suite.Equal(http.StatusCreated, recorder.Code)
+	suite.Contains(recorder.Body.String(), `"remaining":100`)
+}
+
+func (suite *PointPoolHandlerTestSuite) TestPointPoolHandler_CreateExists() {
+	recorder := suite.serve(http.MethodPost, "/admin/points", `{"level":"gold","remaining":100}`)
+	suite.Equal(http.StatusConflict, recorder.Code)
+	suite.Equal("point pool already exists", suite.errorMessage(recorder))
+}
+
+func (suite *PointPoolHandlerTestSuite) TestPointPoolHandler_CreateInvalidLevel() {
+	recorder := suite.serve(http.MethodPost, "/admin/points", `{"level":"Gold","remaining":100}`)
+	suite.Equal(http.StatusBadRequest, recorder.Code)
+	suite.Equal("invalid point pool: level is invalid", suite.errorMessage(recorder))
+}
+
+func (suite *PointPoolHandlerTestSuite) TestPointPoolHandler_CreateInvalidBody() {
+	for _, body := range []string{
+		``,
+		`{"level":"platinum","remaining":-1}`,
+		`{"level":"platinum","remaining":100,"price":5}`,
+		`{"level":"platinum","remaining":100}{}`,
+	} {
+		recorder := suite.serve(http.MethodPost, "/admin/points", body)
+		suite.Equal(http.StatusBadRequest, recorder.Code, body)
+		suite.Contains(suite.errorMessage(recorder), "invalid request body", body)
+	}
+
+	suite.pointPoolService.AssertNotCalled(suite.T(), "CreatePointPool", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *PointPoolHandlerTestSuite) TestPointPoolHandler_CreateMissingRemaining() {
+	recorder := suite.serve(http.MethodPost, "/admin/points", `{"level":"platinum"}`)
+	suite.Equal(http.StatusBadRequest, recorder.Code)
+	suite.Equal("remaining is required", suite.errorMessage(recorder))
+}
+
+func (suite *PointPoolHandlerTestSuite) TestPointPoolHandler_HappyCase_TopUp() {
+	recorder := suite.serve(http.MethodPost, "/admin/points/gold/top-up", `{"amount":5}`)
+	suite.Equal(http.StatusOK, recorder.Code)
+	suite.Contains(recorder.Body.String(), `"remaining":6`)
+}
+
+func (suite *PointPoolHandlerTestSuite) TestPointPoolHandler_TopUpZeroAmount() {
+	recorder := suite.serve(http.MethodPost, "/admin/points/gold/top-up", `{}`)
+	suite.Equal(http.StatusBadRequest, recorder.Code)
+}
+
+func (suite *PointPoolHandlerTestSuite) TestPointPoolHandler_HappyCase_Set() {
+	recorder := suite.serve(http.MethodPut, "/admin/points/gold", `{"remaining":50}`)
+	suite.Equal(http.StatusOK, recorder.Code)
+	suite.Contains(recorder.Body.String(), `"remaining":50`)
+}
+
+func (suite *PointPoolHandlerTestSuite) TestPointPoolHandler_SetNotFound() {
+	recorder := suite.serve(http.MethodPut, "/admin/points/platinum", `{"remaining":50}`)
+	suite.Equal(http.StatusNotFound, recorder.Code)
+}
+
+func (suite *PointPoolHandlerTestSuite) TestPointPoolHandler_SetMissingRemaining() {
+	recorder := suite.serve(http.MethodPut, "/admin/points/gold", `{}`)
+	suite.Equal(http.StatusBadRequest, recorder.Code)
+	suite.pointPoolService.AssertNotCalled(suite.T(), "SetPointPoolRemaining", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *PointPoolHandlerTestSuite) TestPointPoolHandler_HappyCase_Delete() {
+	recorder := suite.serve(http.MethodDelete, "/admin/points/gold", "")
+	suite.Equal(http.StatusNoContent, recorder.Code)
+	suite.Empty(recorder.Body.String())
+}
+
+func (suite *PointPoolHandlerTestSuite) TestPointPoolHandler_DeleteNotFound() {
+	recorder := suite.serve(http.MethodDelete, "/admin/points/platinum", "")
+	suite.Equal(http.StatusNotFound, recorder.Code)
+}
+
+func (suite *PointPoolHandlerTestSuite) TestPointPoolHandler_DeleteInUse() {
+	recorder := suite.serve(http.MethodDelete, "/admin/points/silver", "")
+	suite.Equal(http.StatusConflict, recorder.Code)
+	suite.JSONEq(`{"error": "point pool is used by active tier rules: tier rule 2 maps prices to silver"}`, recorder.Body.String())
+}
+
+func (suite *PointPoolHandlerTestSuite) TestPointPoolHandler_MethodNotAllowed() {
+	recorder := suite.serve(http.MethodDelete, "/admin/points", "")
+	suite.Equal(http.StatusMethodNotAllowed, recorder.Code)
+	suite.Equal("GET, POST", recorder.Header().Get("Allow"))
+
+	recorder = suite.serve(http.MethodGet, "/admin/points/gold/top-up", "")
+	suite.Equal(http.StatusMethodNotAllowed, recorder.Code)
+	suite.Equal("POST", recorder.Header().Get("Allow"))
+}
+
+func (suite *PointPoolHandlerTestSuite) TestPointPoolHandler_UnknownPath() {
+	for _, path := range []string{"/admin/points/", "/admin/points/gold/withdraw", "/admin/points/gold/top-up/1"} {
+		recorder := suite.serve(http.MethodPost, path, `{"amount":5}`)
+		suite.Equal(http.StatusNotFound, recorder.Code, path)
+	}
+}
+
+func TestPointPoolHandlerTestSuite(t *testing.T) {
+	suite.Run(t, new(PointPoolHandlerTestSuite))
+}
@@ -6,25 +6,58 @@ import (
 	"errors"
 	"point-service/app/internal/handler"
 	"point-service/app/internal/model"
+	"point-service/app/internal/repository"
+	"point-service/app/internal/service"
 	mockService "point-service/app/internal/service/mocks"
+	"point-service/app/pkg/kafka"
 	"testing"
 
 	"github.com/IBM/sarama"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
 )
 
 type PointHandlerTestSuite struct {
 	suite.Suite
-	handler handler.PointHandler
+
+	// the handlers as they are routed, decoding the message value first
+	successOrderProcess   kafka.HandlerFunc
+	orderCancelledProcess kafka.HandlerFunc
+	orderRefundedProcess  kafka.HandlerFunc
+
+	pointService      *mockService.PointService
+	redemptionService *mockService.RedemptionService
 }
 
 func (suite *PointHandlerTestSuite) SetupTest() {
 	pointService := new(mockService.PointService)
 	pointService.On("DecreasePoint", mock.Anything, model.SuccessOrder{OrderId: 1, ProductId: 1}).Return(nil)
+	pointService.On("DecreasePoint", mock.Anything, model.SuccessOrder{OrderId: 2, ProductId: 1}).Return(repository.ErrMaxAttemptsReached)
 	pointService.On("DecreasePoint", mock.Anything, model.SuccessOrder{}).Return(errors.New("decrease point error"))
 
-	suite.handler = handler.NewPointHandler(pointService)
+	pointService.On("DecreasePoint", mock.Anything, model.SuccessOrder{OrderId: 3, UserId: 42, ProductId: 1}).Return(nil)
+	pointService.On("DecreasePoint", mock.Anything, model.SuccessOrder{OrderId: 4, UserId: 42, ProductId: 1}).Return(nil)
+	pointService.On("DecreasePoint", mock.Anything, model.SuccessOrder{OrderId: 6, UserId: 42, ProductId: 1}).Return(service.ErrOrderCancelled)
+
+	pointService.On("RestorePoint", mock.Anything, uint(4)).Return(repository.ErrNotEnoughBalance)
+	pointService.On("RestorePoint", mock.Anything, uint(5)).Return(repository.ErrMaxAttemptsReached)
+	pointService.On("RestorePoint", mock.Anything, mock.Anything).Return(nil)
+
+	redemptionService := new(mockService.RedemptionService)
+	redemptionService.On("Confirm", mock.Anything, uint(42), uint(3)).Return(model.PointReservation{Status: model.ReservationConfirmed}, nil)
+	redemptionService.On("Confirm", mock.Anything, uint(42), uint(4)).Return(model.PointReservation{}, errors.New("update reservation status error"))
+	redemptionService.On("Confirm", mock.Anything, mock.Anything, mock.Anything).Return(model.PointReservation{}, gorm.ErrRecordNotFound)
+
+	redemptionService.On("Cancel", mock.Anything, uint(3)).Return(context.DeadlineExceeded)
+	redemptionService.On("Cancel", mock.Anything, mock.Anything).Return(nil)
+
+	suite.pointService = pointService
+	suite.redemptionService = redemptionService
+	pointHandler := handler.NewPointHandler(pointService, redemptionService)
+	suite.successOrderProcess = kafka.Decode(kafka.JSONDecoder, pointHandler.SuccessOrderProcess)
+	suite.orderCancelledProcess = kafka.Decode(kafka.JSONDecoder, pointHandler.OrderCancelledProcess)
+	suite.orderRefundedProcess = kafka.Decode(kafka.JSONDecoder, pointHandler.OrderRefundedProcess)
 }
 
 func (suite *PointHandlerTestSuite) TestPointHandler_HappyCase() {
@@ -34,8 +67,10 @@ func (suite *PointHandlerTestSuite) TestPointHandler_HappyCase() {
 		Value: b,
 	}
 
-	err := suite.handler.SuccessOrderProcess(context.Background(), &message)
+	err := suite.successOrderProcess(context.Background(), &message)
 	suite.Nil(err)
+	// an order without user has no reservation
+	suite.redemptionService.AssertNotCalled(suite.T(), "Confirm", mock.Anything, mock.Anything, mock.Anything)
 }
 
 func (suite *PointHandlerTestSuite) TestPointHandler_UnmarshalError() {
@@ -45,8 +80,9 @@ func (suite *PointHandlerTestSuite) TestPointHandler_UnmarshalError() {
 		Value: b,
 	}
 
-	err := suite.handler.SuccessOrderProcess(context.Background(), &message)
-	suite.Nil(err)
+	err := suite.successOrderProcess(context.Background(), &message)
+	suite.NotNil(err)
+	suite.False(kafka.IsRetryable(err))
 }
 
 func (suite *PointHandlerTestSuite) TestPointHandler_DecreasePointError() {
@@ -56,8 +92,124 @@ func (suite *PointHandlerTestSuite) TestPointHandler_DecreasePointError() {
 		Value: b,
 	}
 
-	err := suite.handler.SuccessOrderProcess(context.Background(), &message)
+	err := suite.successOrderProcess(context.Background(), &message)
+	suite.NotNil(err)
+	suite.False(kafka.IsRetryable(err))
+}
+
+func (suite *PointHandlerTestSuite) TestPointHandler_DecreasePointTransientError() {
+	successOrder := model.SuccessOrder{OrderId: 2, ProductId: 1}
+	b, _ := json.Marshal(successOrder)
+	message := sarama.ConsumerMessage{
+		Value: b,
+	}
+
+	err := suite.successOrderProcess(context.Background(), &message)
+	suite.NotNil(err)
+	suite.True(kafka.IsRetryable(err))
+}
+
+func (suite *PointHandlerTestSuite) TestPointHandler_HappyCase_ConfirmReservation() {
+	successOrder := model.SuccessOrder{OrderId: 3, UserId: 42, ProductId: 1}
+	b, _ := json.Marshal(successOrder)
+	message := sarama.ConsumerMessage{
+		Value: b,
+	}
+
+	err := suite.successOrderProcess(context.Background(), &message)
 	suite.Nil(err)
+	suite.redemptionService.AssertCalled(suite.T(), "Confirm", mock.Anything, uint(42), uint(3))
+}
+
+func (suite *PointHandlerTestSuite) TestPointHandler_HappyCase_OrderCancelledBeforeSuccess() {
+	b, _ := json.Marshal(model.SuccessOrder{OrderId: 6, UserId: 42, ProductId: 1})
+	message := sarama.ConsumerMessage{
+		Value: b,
+	}
+
+	err := suite.successOrderProcess(context.Background(), &message)
+	suite.Nil(err)
+	suite.redemptionService.AssertNotCalled(suite.T(), "Confirm", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *PointHandlerTestSuite) TestPointHandler_ConfirmReservationError() {
+	successOrder := model.SuccessOrder{OrderId: 4, UserId: 42, ProductId: 1}
+	b, _ := json.Marshal(successOrder)
+	message := sarama.ConsumerMessage{
+		Value: b,
+	}
+
+	err := suite.successOrderProcess(context.Background(), &message)
+	suite.EqualError(err, "confirm reservation error: update reservation status error")
+	suite.False(kafka.IsRetryable(err))
+}
+
+func (suite *PointHandlerTestSuite) TestPointHandler_HappyCase_OrderCancelled() {
+	for _, orderId := range []uint{1, 2} {
+		b, _ := json.Marshal(model.OrderCancelled{OrderId: orderId})
+		message := sarama.ConsumerMessage{
+			Value: b,
+		}
+
+		err := suite.orderCancelledProcess(context.Background(), &message)
+		suite.Nil(err)
+	}
+}
+
+func (suite *PointHandlerTestSuite) TestPointHandler_OrderCancelledTransientError() {
+	b, _ := json.Marshal(model.OrderCancelled{OrderId: 3})
+	message := sarama.ConsumerMessage{
+		Value: b,
+	}
+
+	err := suite.orderCancelledProcess(context.Background(), &message)
+	suite.NotNil(err)
+	suite.True(kafka.IsRetryable(err))
+}
+
+func (suite *PointHandlerTestSuite) TestPointHandler_OrderCancelledUnmarshalError() {
+	message := sarama.ConsumerMessage{
+		Value: []byte("invalid body"),
+	}
+
+	err := suite.orderCancelledProcess(context.Background(), &message)
+	suite.NotNil(err)
+	suite.False(kafka.IsRetryable(err))
+	suite.redemptionService.AssertNotCalled(suite.T(), "Cancel", mock.Anything, mock.Anything)
+}
+
+func (suite *PointHandlerTestSuite) TestPointHandler_OrderCancelledRestoreError() {
+	b, _ := json.Marshal(model.OrderCancelled{OrderId: 4})
+	message := sarama.ConsumerMessage{
+		Value: b,
+	}
+
+	err := suite.orderCancelledProcess(context.Background(), &message)
+	suite.ErrorIs(err, repository.ErrNotEnoughBalance)
+	suite.False(kafka.IsRetryable(err))
+	suite.redemptionService.AssertNotCalled(suite.T(), "Cancel", mock.Anything, mock.Anything)
+}
+
+func (suite *PointHandlerTestSuite) TestPointHandler_HappyCase_OrderRefunded() {
+	b, _ := json.Marshal(model.OrderRefunded{OrderId: 1})
+	message := sarama.ConsumerMessage{
+		Value: b,
+	}
+
+	err := suite.orderRefundedProcess(context.Background(), &message)
+	suite.Nil(err)
+	suite.pointService.AssertCalled(suite.T(), "RestorePoint", mock.Anything, uint(1))
+	suite.redemptionService.AssertNotCalled(suite.T(), "Cancel", mock.Anything, mock.Anything)
+}
+
+func (suite *PointHandlerTestSuite) TestPointHandler_OrderRefundedTransientError() {
+	b, _ := json.Marshal(model.OrderRefunded{OrderId: 5})
+	message := sarama.ConsumerMessage{
+		Value: b,
+	}
+
+	err := suite.orderRefundedProcess(context.Background(), &message)
+	suite.True(kafka.IsRetryable(err))
 }
 
 func TestPointHandlerTestSuite(t *testing.T) {
@@ -0,0 +1,226 @@
+package handler
+
+import (
+	"net/http"
+	"point-service/app/internal/model"
+	"point-service/app/internal/service"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+const productPath = "/admin/products"
+
+type ProductHandler interface {
+	RegisterRoutes(mux *http.ServeMux)
+}
+
+type productHandler struct {
+	productService service.ProductService
+}
+
+func NewProductHandler(productService service.ProductService) ProductHandler {
+	return &productHandler{
+		productService: productService,
+	}
+}
+
+type productResponse struct {
+	Id        uint      `json:"id"`
+	Name      string    `json:"name"`
+	Price     float64   `json:"price"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type productPageResponse struct {
+	Items    []productResponse `json:"items"`
+	Page     int               `json:"page"`
+	PageSize int               `json:"page_size"`
+	Total    int64             `json:"total"`
+}
+
+type productRequest struct {
+	Name  string   `json:"name"`
+	Price *float64 `json:"price"`
+}
+
+// RegisterRoutes serves
+//
+//	GET    /admin/products?page=1&page_size=20 list products
+//	POST   /admin/products                     create a product
+//	GET    /admin/products/{id}                get a product
+//	PUT    /admin/products/{id}                replace the name and price of a product
+//	DELETE /admin/products/{id}                soft delete a product
+func (handler *productHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc(productPath, handler.products)
+	mux.HandleFunc(productPath+"/", handler.product)
+}
+
+func (handler *productHandler) products(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		handler.listProducts(w, r)
+	case http.MethodPost:
+		handler.createProduct(w, r)
+	default:
+		writeMethodNotAllowed(w, "GET, POST")
+	}
+}
+
+func (handler *productHandler) product(w http.ResponseWriter, r *http.Request) {
+	productId, err := strconv.ParseUint(strings.TrimPrefix(r.URL.Path, productPath+"/"), 10, 0)
+	if err != nil || productId == 0 {
+		writeError(w, http.StatusNotFound, errNotFound)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		handler.getProduct(w, r, uint(productId))
+	case http.MethodPut:
+		handler.updateProduct(w, r, uint(productId))
+	case http.MethodDelete:
+		handler.deleteProduct(w, r, uint(productId))
+	default:
+		writeMethodNotAllowed(w, "GET, PUT, DELETE")
+	}
+}
+
+func (handler *productHandler) listProducts(w http.ResponseWriter, r *http.Request) {
+	page, err := queryInt(r, "page")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	pageSize, err := queryInt(r, "page_size")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	productPage, err := handler.productService.ListProducts(r.Context(), page, pageSize)
+	if err != nil {
+		writeError(w, productErrorStatus(err), err)
+		return
+	}
+
+	response := productPageResponse{
+		Items:    []productResponse{},
+		Page:     productPage.Page,
+		PageSize: productPage.PageSize,
+		Total:    productPage.Total,
+	}
+	for _, product := range productPage.Products {
+		response.Items = append(response.Items, newProductResponse(product))
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+func (handler *productHandler) getProduct(w http.ResponseWriter, r *http.Request, productId uint) {
+	product, err := handler.productService.GetProduct(r.Context(), productId)
+	if err != nil {
+		writeError(w, productErrorStatus(err), err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, newProductResponse(product))
+}
+
+func (handler *productHandler) createProduct(w http.ResponseWriter, r *http.Request) {
+	request, err := decodeProductRequest(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	product, err := handler.productService.CreateProduct(r.Context(), request.Name, *request.Price)
+	if err != nil {
+		writeError(w, productErrorStatus(err), err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, newProductResponse(product))
+}
+
+func (handler *productHandler) updateProduct(w http.ResponseWriter, r *http.Request, productId uint) {
+	request, err := decodeProductRequest(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	product, err := handler.productService.UpdateProduct(r.Context(), productId, request.Name, *request.Price)
+	if err != nil {
+		writeError(w, productErrorStatus(err), err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, newProductResponse(product))
+}
+
+func (handler *productHandler) deleteProduct(w http.ResponseWriter, r *http.Request, productId uint) {
+	err := handler.productService.DeleteProduct(r.Context(), productId)
+	if err != nil {
+		writeError(w, productErrorStatus(err), err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func decodeProductRequest(r *http.Request) (productRequest, error) {
+	var request productRequest
+	err := decodeJSON(r, &request)
+	if err != nil {
+		return request, err
+	}
+
+	// a missing price would silently become 0
+	if request.Price == nil {
+		return request, errors.New("price is required")
+	}
+
+	return request, nil
+}
+
+// queryInt reads an optional integer query parameter, a missing one is 0
+func queryInt(r *http.Request, name string) (int, error) {
+	raw := r.URL.Query().Get(name)
+	if raw == "" {
+		return 0, nil
+	}
+
+	value, err := strconv.Atoi(raw)
+	if err != nil {
+		return 0, errors.Errorf("%s must be an integer", name)
+	}
+
+	return value, nil
+}
+
+func newProductResponse(product model.Product) productResponse {
+	return productResponse{
+		Id:        product.ID,
+		Name:      product.Name,
+		Price:     product.Price,
+		CreatedAt: product.CreatedAt,
+		UpdatedAt: product.UpdatedAt,
+	}
+}
+
+func productErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrInvalidProduct), errors.Is(err, service.ErrInvalidPage):
+		return http.StatusBadRequest
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
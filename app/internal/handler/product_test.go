@@ -0,0 +1,183 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"point-service/app/internal/handler"
+	"point-service/app/internal/model"
+	"point-service/app/internal/service"
+	mockService "point-service/app/internal/service/mocks"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+)
+
+type ProductHandlerTestSuite struct {
+	suite.Suite
+	mux *http.ServeMux
+
+	productService *mockService.ProductService
+}
+
+func product(id uint, name string, price float64) model.Product {
+	product := model.Product{Name: name, Price: price}
+	product.ID = id
+
+	return product
+}
+
+func (suite *ProductHandlerTestSuite) SetupTest() {
+	productService := new(mockService.ProductService)
+	productService.On("ListProducts", mock.Anything, 0, 0).Return(service.ProductPage{Products: []model.Product{product(1, "car", 77)}, Page: 1, PageSize: 20, Total: 1}, nil)
+	productService.On("ListProducts", mock.Anything, 2, 10).Return(service.ProductPage{Page: 2, PageSize: 10, Total: 1}, nil)
+	productService.On("ListProducts", mock.Anything, mock.Anything, mock.Anything).Return(service.ProductPage{}, fmt.Errorf("%w: page size must be between 1 and 100", service.ErrInvalidPage))
+
+	productService.On("GetProduct", mock.Anything, uint(1)).Return(product(1, "car", 77), nil)
+	productService.On("GetProduct", mock.Anything, uint(5)).Return(model.Product{}, errors.New("connection refused"))
+	productService.On("GetProduct", mock.Anything, mock.Anything).Return(model.Product{}, gorm.ErrRecordNotFound)
+
+	productService.On("CreateProduct", mock.Anything, "bike", 1000.5).Return(product(2, "bike", 1000.5), nil)
+	productService.On("CreateProduct", mock.Anything, mock.Anything, mock.Anything).Return(model.Product{}, fmt.Errorf("%w: no active tier rule covers price 0", service.ErrInvalidProduct))
+
+	productService.On("UpdateProduct", mock.Anything, uint(1), "sports car", 1500.0).Return(product(1, "sports car", 1500), nil)
+	productService.On("UpdateProduct", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(model.Product{}, gorm.ErrRecordNotFound)
+
+	productService.On("DeleteProduct", mock.Anything, uint(1)).Return(nil)
+	productService.On("DeleteProduct", mock.Anything, mock.Anything).Return(gorm.ErrRecordNotFound)
+
+	suite.productService = productService
+	suite.mux = http.NewServeMux()
+	handler.NewProductHandler(productService).RegisterRoutes(suite.mux)
+}
+
+func (suite *ProductHandlerTestSuite) serve(method string, path string, body string) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(method, path, strings.NewReader(body))
+	recorder := httptest.NewRecorder()
+	suite.mux.ServeHTTP(recorder, request)
+
+	return recorder
+}
+
+func (suite *ProductHandlerTestSuite) TestProductHandler_HappyCase_List() {
+	recorder := suite.serve(http.MethodGet, "/admin/products", "")
+	suite.Equal(http.StatusOK, recorder.Code)
+
+	var response struct {
+		Items []struct {
+			Id    uint    `json:"id"`
+			Name  string  `json:"name"`
+			Price float64 `json:"price"`
+		} `json:"items"`
+		Page     int   `json:"page"`
+		PageSize int   `json:"page_size"`
+		Total    int64 `json:"total"`
+	}
+	suite.Nil(json.Unmarshal(recorder.Body.Bytes(), &response))
+	suite.Len(response.Items, 1)
+	suite.Equal(uint(1), response.Items[0].Id)
+	suite.Equal(77.0, response.Items[0].Price)
+	suite.Equal(1, response.Page)
+	suite.Equal(20, response.PageSize)
+	suite.Equal(int64(1), response.Total)
+}
+
+func (suite *ProductHandlerTestSuite) TestProductHandler_HappyCase_ListPage() {
+	recorder := suite.serve(http.MethodGet, "/admin/products?page=2&page_size=10", "")
+	suite.Equal(http.StatusOK, recorder.Code)
+	suite.Contains(recorder.Body.String(), `"items":[]`)
+}
+
+func (suite *ProductHandlerTestSuite) TestProductHandler_ListInvalidPage() {
+	recorder := suite.serve(http.MethodGet, "/admin/products?page=two", "")
+	suite.Equal(http.StatusBadRequest, recorder.Code)
+	suite.Contains(recorder.Body.String(), "page must be an integer")
+
+	recorder = suite.serve(http.MethodGet, "/admin/products?page_size=500", "")
+	suite.Equal(http.StatusBadRequest, recorder.Code)
+}
+
+func (suite *ProductHandlerTestSuite) TestProductHandler_HappyCase_Get() {
+	recorder := suite.serve(http.MethodGet, "/admin/products/1", "")
+	suite.Equal(http.StatusOK, recorder.Code)
+	suite.Contains(recorder.Body.String(), `"name":"car"`)
+}
+
+func (suite *ProductHandlerTestSuite) TestProductHandler_GetNotFound() {
+	recorder := suite.serve(http.MethodGet, "/admin/products/9", "")
+	suite.Equal(http.StatusNotFound, recorder.Code)
+}
+
+func (suite *ProductHandlerTestSuite) TestProductHandler_GetInternalError() {
+	recorder := suite.serve(http.MethodGet, "/admin/products/5", "")
+	suite.Equal(http.StatusInternalServerError, recorder.Code)
+	suite.NotContains(recorder.Body.String(), "connection refused")
+}
+
+func (suite *ProductHandlerTestSuite) TestProductHandler_InvalidId() {
+	for _, path := range []string{"/admin/products/", "/admin/products/0", "/admin/products/car", "/admin/products/1/price"} {
+		recorder := suite.serve(http.MethodGet, path, "")
+		suite.Equal(http.StatusNotFound, recorder.Code, path)
+	}
+}
+
+func (suite *ProductHandlerTestSuite) TestProductHandler_HappyCase_Create() {
+	recorder := suite.serve(http.MethodPost, "/admin/products", `{"name":"bike","price":1000.5}`)
+	suite.Equal(http.StatusCreated, recorder.Code)
+	suite.Contains(recorder.Body.String(), `"id":2`)
+}
+
+func (suite *ProductHandlerTestSuite) TestProductHandler_CreateUncoveredPrice() {
+	recorder := suite.serve(http.MethodPost, "/admin/products", `{"name":"free","price":0}`)
+	suite.Equal(http.StatusBadRequest, recorder.Code)
+	suite.Contains(recorder.Body.String(), "no active tier rule covers price 0")
+}
+
+func (suite *ProductHandlerTestSuite) TestProductHandler_CreateMissingPrice() {
+	recorder := suite.serve(http.MethodPost, "/admin/products", `{"name":"bike"}`)
+	suite.Equal(http.StatusBadRequest, recorder.Code)
+	suite.Contains(recorder.Body.String(), "price is required")
+	suite.productService.AssertNotCalled(suite.T(), "CreateProduct", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *ProductHandlerTestSuite) TestProductHandler_CreateInvalidBody() {
+	recorder := suite.serve(http.MethodPost, "/admin/products", `{"name":"bike","price":"cheap"}`)
+	suite.Equal(http.StatusBadRequest, recorder.Code)
+	suite.Contains(recorder.Body.String(), "invalid request body")
+}
+
+func (suite *ProductHandlerTestSuite) TestProductHandler_HappyCase_Update() {
+	recorder := suite.serve(http.MethodPut, "/admin/products/1", `{"name":"sports car","price":1500}`)
+	suite.Equal(http.StatusOK, recorder.Code)
+	suite.Contains(recorder.Body.String(), `"price":1500`)
+}
+
+func (suite *ProductHandlerTestSuite) TestProductHandler_UpdateNotFound() {
+	recorder := suite.serve(http.MethodPut, "/admin/products/9", `{"name":"sports car","price":1500}`)
+	suite.Equal(http.StatusNotFound, recorder.Code)
+}
+
+func (suite *ProductHandlerTestSuite) TestProductHandler_HappyCase_Delete() {
+	recorder := suite.serve(http.MethodDelete, "/admin/products/1", "")
+	suite.Equal(http.StatusNoContent, recorder.Code)
+}
+
+func (suite *ProductHandlerTestSuite) TestProductHandler_DeleteNotFound() {
+	recorder := suite.serve(http.MethodDelete, "/admin/products/9", "")
+	suite.Equal(http.StatusNotFound, recorder.Code)
+}
+
+func (suite *ProductHandlerTestSuite) TestProductHandler_MethodNotAllowed() {
+	recorder := suite.serve(http.MethodPatch, "/admin/products/1", "")
+	suite.Equal(http.StatusMethodNotAllowed, recorder.Code)
+	suite.Equal("GET, PUT, DELETE", recorder.Header().Get("Allow"))
+}
+
+func TestProductHandlerTestSuite(t *testing.T) {
+	suite.Run(t, new(ProductHandlerTestSuite))
+}
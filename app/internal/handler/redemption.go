@@ -0,0 +1,161 @@
+package handler
+
+import (
+	"net/http"
+	"point-service/app/internal/model"
+	"point-service/app/internal/repository"
+	"point-service/app/internal/service"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+const redemptionPath = "/redemptions"
+
+type RedemptionHandler interface {
+	RegisterRoutes(mux *http.ServeMux)
+}
+
+type redemptionHandler struct {
+	redemptionService service.RedemptionService
+}
+
+func NewRedemptionHandler(redemptionService service.RedemptionService) RedemptionHandler {
+	return &redemptionHandler{
+		redemptionService: redemptionService,
+	}
+}
+
+type reservationResponse struct {
+	UserId    uint      `json:"user_id"`
+	OrderId   uint      `json:"order_id"`
+	Level     string    `json:"level"`
+	Amount    uint      `json:"amount"`
+	Status    string    `json:"status"`
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type reserveRequest struct {
+	OrderId uint   `json:"order_id"`
+	Level   string `json:"level"`
+	Amount  uint   `json:"amount"`
+}
+
+// RegisterRoutes serves to the user RequireUserToken authenticated
+//
+//	POST /redemptions                     reserve points of the user for an order
+//	GET  /redemptions/{order_id}          get the reservation of the user for an order
+//	POST /redemptions/{order_id}/cancel   give the points of a pending reservation back
+//
+// A reservation is only confirmed or refunded by the events of its order.
+func (handler *redemptionHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc(redemptionPath, handler.redemptions)
+	mux.HandleFunc(redemptionPath+"/", handler.redemption)
+}
+
+func (handler *redemptionHandler) redemptions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeMethodNotAllowed(w, "POST")
+		return
+	}
+
+	userId, ok := userIdFromContext(r.Context())
+	if !ok {
+		writeUnauthorized(w)
+		return
+	}
+
+	var request reserveRequest
+	err := decodeJSON(r, &request)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	reservation, err := handler.redemptionService.Reserve(r.Context(), userId, request.OrderId, request.Level, request.Amount)
+	if err != nil {
+		writeError(w, redemptionErrorStatus(err), err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, newReservationResponse(reservation))
+}
+
+func (handler *redemptionHandler) redemption(w http.ResponseWriter, r *http.Request) {
+	userId, ok := userIdFromContext(r.Context())
+	if !ok {
+		writeUnauthorized(w)
+		return
+	}
+
+	rawOrderId, action, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, redemptionPath+"/"), "/")
+	orderId, err := strconv.ParseUint(rawOrderId, 10, 0)
+	if err != nil || orderId == 0 {
+		writeError(w, http.StatusNotFound, errNotFound)
+		return
+	}
+
+	switch action {
+	case "":
+		if r.Method != http.MethodGet {
+			writeMethodNotAllowed(w, "GET")
+			return
+		}
+	case "cancel":
+		if r.Method != http.MethodPost {
+			writeMethodNotAllowed(w, "POST")
+			return
+		}
+	default:
+		writeError(w, http.StatusNotFound, errNotFound)
+		return
+	}
+
+	// only the reservations of the user are found, a reservation of another user is answered like a
+	// missing one
+	var reservation model.PointReservation
+	if action == "cancel" {
+		reservation, err = handler.redemptionService.Release(r.Context(), userId, uint(orderId))
+	} else {
+		reservation, err = handler.redemptionService.GetReservation(r.Context(), userId, uint(orderId))
+	}
+	if err != nil {
+		writeError(w, redemptionErrorStatus(err), err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, newReservationResponse(reservation))
+}
+
+func newReservationResponse(reservation model.PointReservation) reservationResponse {
+	return reservationResponse{
+		UserId:    reservation.UserId,
+		OrderId:   reservation.OrderId,
+		Level:     reservation.Level,
+		Amount:    reservation.Amount,
+		Status:    reservation.Status,
+		ExpiresAt: reservation.ExpiresAt,
+		CreatedAt: reservation.CreatedAt,
+		UpdatedAt: reservation.UpdatedAt,
+	}
+}
+
+func redemptionErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrInvalidReservation):
+		return http.StatusBadRequest
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrReservationExists),
+		errors.Is(err, service.ErrReservationNotPending),
+		errors.Is(err, repository.ErrNotEnoughBalance):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
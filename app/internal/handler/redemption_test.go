@@ -0,0 +1,166 @@
+package handler_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"point-service/app/internal/handler"
+	"point-service/app/internal/model"
+	"point-service/app/internal/repository"
+	"point-service/app/internal/service"
+	mockService "point-service/app/internal/service/mocks"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+)
+
+type RedemptionHandlerTestSuite struct {
+	suite.Suite
+	handler http.Handler
+
+	redemptionService *mockService.RedemptionService
+}
+
+func (suite *RedemptionHandlerTestSuite) SetupTest() {
+	pending := model.PointReservation{UserId: 42, OrderId: 10, Level: "gold", Amount: 5, Status: model.ReservationPending}
+	cancelled := pending
+	cancelled.Status = model.ReservationCancelled
+
+	redemptionService := new(mockService.RedemptionService)
+	redemptionService.On("Reserve", mock.Anything, uint(42), uint(10), "gold", uint(5)).Return(pending, nil)
+	redemptionService.On("Reserve", mock.Anything, uint(42), uint(10), "gold", uint(500)).Return(model.PointReservation{}, fmt.Errorf("reserve user gold point error: %w", repository.ErrNotEnoughBalance))
+	redemptionService.On("Reserve", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(model.PointReservation{}, fmt.Errorf("%w: amount must be greater than 0", service.ErrInvalidReservation))
+
+	redemptionService.On("GetReservation", mock.Anything, uint(42), uint(10)).Return(pending, nil)
+	redemptionService.On("GetReservation", mock.Anything, mock.Anything, mock.Anything).Return(model.PointReservation{}, gorm.ErrRecordNotFound)
+
+	redemptionService.On("Release", mock.Anything, uint(42), uint(10)).Return(cancelled, nil)
+	redemptionService.On("Release", mock.Anything, uint(42), uint(12)).Return(model.PointReservation{}, errors.New("connection refused"))
+	redemptionService.On("Release", mock.Anything, uint(42), uint(14)).Return(model.PointReservation{}, fmt.Errorf("%w: reservation is confirmed", service.ErrReservationNotPending))
+	redemptionService.On("Release", mock.Anything, mock.Anything, mock.Anything).Return(model.PointReservation{}, gorm.ErrRecordNotFound)
+
+	suite.redemptionService = redemptionService
+	mux := http.NewServeMux()
+	handler.NewRedemptionHandler(redemptionService).RegisterRoutes(mux)
+	suite.handler = handler.RequireUserToken("user-secret", mux)
+}
+
+func userToken(secret string, subject string, expiresAt time.Time) string {
+	token, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{
+		Subject:   subject,
+		ExpiresAt: jwt.NewNumericDate(expiresAt),
+	}).SignedString([]byte(secret))
+
+	return token
+}
+
+func (suite *RedemptionHandlerTestSuite) serve(method string, path string, body string) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(method, path, strings.NewReader(body))
+	request.Header.Set("Authorization", "Bearer "+userToken("user-secret", "42", time.Now().Add(time.Hour)))
+	recorder := httptest.NewRecorder()
+	suite.handler.ServeHTTP(recorder, request)
+
+	return recorder
+}
+
+func (suite *RedemptionHandlerTestSuite) TestRedemptionHandler_HappyCase_Reserve() {
+	recorder := suite.serve(http.MethodPost, "/redemptions", `{"order_id":10,"level":"gold","amount":5}`)
+	suite.Equal(http.StatusCreated, recorder.Code)
+	suite.Contains(recorder.Body.String(), `"status":"pending"`)
+}
+
+func (suite *RedemptionHandlerTestSuite) TestRedemptionHandler_ReserveNotEnoughBalance() {
+	recorder := suite.serve(http.MethodPost, "/redemptions", `{"order_id":10,"level":"gold","amount":500}`)
+	suite.Equal(http.StatusConflict, recorder.Code)
+	suite.Contains(recorder.Body.String(), "not enough user point balance")
+}
+
+func (suite *RedemptionHandlerTestSuite) TestRedemptionHandler_ReserveInvalid() {
+	recorder := suite.serve(http.MethodPost, "/redemptions", `{"order_id":10,"level":"gold"}`)
+	suite.Equal(http.StatusBadRequest, recorder.Code)
+
+	recorder = suite.serve(http.MethodPost, "/redemptions", `{"user_id":7,"order_id":10,"level":"gold","amount":5}`)
+	suite.Equal(http.StatusBadRequest, recorder.Code)
+	suite.Contains(recorder.Body.String(), "invalid request body")
+}
+
+func (suite *RedemptionHandlerTestSuite) TestRedemptionHandler_HappyCase_Get() {
+	recorder := suite.serve(http.MethodGet, "/redemptions/10", "")
+	suite.Equal(http.StatusOK, recorder.Code)
+	suite.Contains(recorder.Body.String(), `"order_id":10`)
+}
+
+func (suite *RedemptionHandlerTestSuite) TestRedemptionHandler_GetNotFound() {
+	recorder := suite.serve(http.MethodGet, "/redemptions/11", "")
+	suite.Equal(http.StatusNotFound, recorder.Code)
+}
+
+func (suite *RedemptionHandlerTestSuite) TestRedemptionHandler_HappyCase_Cancel() {
+	recorder := suite.serve(http.MethodPost, "/redemptions/10/cancel", "")
+	suite.Equal(http.StatusOK, recorder.Code)
+	suite.Contains(recorder.Body.String(), `"status":"cancelled"`)
+	suite.redemptionService.AssertCalled(suite.T(), "Release", mock.Anything, uint(42), uint(10))
+}
+
+func (suite *RedemptionHandlerTestSuite) TestRedemptionHandler_CancelNotPending() {
+	recorder := suite.serve(http.MethodPost, "/redemptions/14/cancel", "")
+	suite.Equal(http.StatusConflict, recorder.Code)
+	suite.Contains(recorder.Body.String(), "reservation is not pending")
+}
+
+func (suite *RedemptionHandlerTestSuite) TestRedemptionHandler_OtherUser() {
+	// the reservation of order 10 belongs to user 42
+	request := httptest.NewRequest(http.MethodPost, "/redemptions/10/cancel", nil)
+	request.Header.Set("Authorization", "Bearer "+userToken("user-secret", "7", time.Now().Add(time.Hour)))
+	recorder := httptest.NewRecorder()
+	suite.handler.ServeHTTP(recorder, request)
+
+	suite.Equal(http.StatusNotFound, recorder.Code)
+	suite.redemptionService.AssertCalled(suite.T(), "Release", mock.Anything, uint(7), uint(10))
+	suite.redemptionService.AssertNotCalled(suite.T(), "Release", mock.Anything, uint(42), uint(10))
+}
+
+func (suite *RedemptionHandlerTestSuite) TestRedemptionHandler_Unauthorized() {
+	request := httptest.NewRequest(http.MethodPost, "/redemptions", strings.NewReader(`{"order_id":10,"level":"gold","amount":5}`))
+	recorder := httptest.NewRecorder()
+	suite.handler.ServeHTTP(recorder, request)
+
+	suite.Equal(http.StatusUnauthorized, recorder.Code)
+	suite.redemptionService.AssertNotCalled(suite.T(), "Reserve", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *RedemptionHandlerTestSuite) TestRedemptionHandler_CancelInternalError() {
+	recorder := suite.serve(http.MethodPost, "/redemptions/12/cancel", "")
+	suite.Equal(http.StatusInternalServerError, recorder.Code)
+	suite.NotContains(recorder.Body.String(), "connection refused")
+}
+
+func (suite *RedemptionHandlerTestSuite) TestRedemptionHandler_MethodNotAllowed() {
+	recorder := suite.serve(http.MethodGet, "/redemptions", "")
+	suite.Equal(http.StatusMethodNotAllowed, recorder.Code)
+	suite.Equal("POST", recorder.Header().Get("Allow"))
+
+	recorder = suite.serve(http.MethodGet, "/redemptions/10/cancel", "")
+	suite.Equal(http.StatusMethodNotAllowed, recorder.Code)
+
+	recorder = suite.serve(http.MethodDelete, "/redemptions/10", "")
+	suite.Equal(http.StatusMethodNotAllowed, recorder.Code)
+	suite.Equal("GET", recorder.Header().Get("Allow"))
+}
+
+func (suite *RedemptionHandlerTestSuite) TestRedemptionHandler_UnknownPath() {
+	for _, path := range []string{"/redemptions/", "/redemptions/0", "/redemptions/order", "/redemptions/10/refund", "/redemptions/10/confirm", "/redemptions/10/cancel/1"} {
+		recorder := suite.serve(http.MethodPost, path, "")
+		suite.Equal(http.StatusNotFound, recorder.Code, path)
+	}
+}
+
+func TestRedemptionHandlerTestSuite(t *testing.T) {
+	suite.Run(t, new(RedemptionHandlerTestSuite))
+}
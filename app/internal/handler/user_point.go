@@ -0,0 +1,159 @@
+package handler
+
+import (
+	"net/http"
+	"point-service/app/internal/service"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const userPath = "/admin/users/"
+
+type UserPointHandler interface {
+	RegisterRoutes(mux *http.ServeMux)
+}
+
+type userPointHandler struct {
+	userPointService service.UserPointService
+}
+
+func NewUserPointHandler(userPointService service.UserPointService) UserPointHandler {
+	return &userPointHandler{
+		userPointService: userPointService,
+	}
+}
+
+type userPointLevelResponse struct {
+	Level   string `json:"level"`
+	Balance uint   `json:"balance"`
+}
+
+type userPointBalanceResponse struct {
+	UserId uint                     `json:"user_id"`
+	Total  uint                     `json:"total"`
+	Levels []userPointLevelResponse `json:"levels"`
+}
+
+type userPointLedgerResponse struct {
+	OrderId      uint      `json:"order_id"`
+	Level        string    `json:"level"`
+	Type         string    `json:"type"`
+	Amount       int64     `json:"amount"`
+	BalanceAfter uint      `json:"balance_after"`
+	Shortfall    uint      `json:"shortfall"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type userPointLedgerPageResponse struct {
+	Items    []userPointLedgerResponse `json:"items"`
+	Page     int                       `json:"page"`
+	PageSize int                       `json:"page_size"`
+	Total    int64                     `json:"total"`
+}
+
+// RegisterRoutes serves
+//
+//	GET /admin/users/{id}/points                            balance of every level and the total
+//	GET /admin/users/{id}/points/ledger?page=1&page_size=20 ledger entries, newest first
+func (handler *userPointHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc(userPath, handler.userPoints)
+}
+
+func (handler *userPointHandler) userPoints(w http.ResponseWriter, r *http.Request) {
+	rawUserId, resource, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, userPath), "/")
+	userId, err := strconv.ParseUint(rawUserId, 10, 0)
+	if err != nil || userId == 0 {
+		writeError(w, http.StatusNotFound, errNotFound)
+		return
+	}
+
+	if resource != "points" && resource != "points/ledger" {
+		writeError(w, http.StatusNotFound, errNotFound)
+		return
+	}
+
+	if r.Method != http.MethodGet {
+		writeMethodNotAllowed(w, "GET")
+		return
+	}
+
+	if resource == "points" {
+		handler.getUserPointBalance(w, r, uint(userId))
+		return
+	}
+
+	handler.listUserPointLedger(w, r, uint(userId))
+}
+
+func (handler *userPointHandler) getUserPointBalance(w http.ResponseWriter, r *http.Request, userId uint) {
+	balance, err := handler.userPointService.GetUserPointBalance(r.Context(), userId)
+	if err != nil {
+		writeError(w, userPointErrorStatus(err), err)
+		return
+	}
+
+	response := userPointBalanceResponse{
+		UserId: balance.UserId,
+		Total:  balance.Total,
+		Levels: []userPointLevelResponse{},
+	}
+	for _, userPoint := range balance.Levels {
+		response.Levels = append(response.Levels, userPointLevelResponse{
+			Level:   userPoint.Level,
+			Balance: userPoint.Balance,
+		})
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+func (handler *userPointHandler) listUserPointLedger(w http.ResponseWriter, r *http.Request, userId uint) {
+	page, err := queryInt(r, "page")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	pageSize, err := queryInt(r, "page_size")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	ledgerPage, err := handler.userPointService.ListUserPointLedger(r.Context(), userId, page, pageSize)
+	if err != nil {
+		writeError(w, userPointErrorStatus(err), err)
+		return
+	}
+
+	response := userPointLedgerPageResponse{
+		Items:    []userPointLedgerResponse{},
+		Page:     ledgerPage.Page,
+		PageSize: ledgerPage.PageSize,
+		Total:    ledgerPage.Total,
+	}
+	for _, entry := range ledgerPage.Entries {
+		response.Items = append(response.Items, userPointLedgerResponse{
+			OrderId:      entry.OrderId,
+			Level:        entry.Level,
+			Type:         entry.Type,
+			Amount:       entry.Amount,
+			BalanceAfter: entry.BalanceAfter,
+			Shortfall:    entry.Shortfall,
+			CreatedAt:    entry.CreatedAt,
+		})
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+func userPointErrorStatus(err error) int {
+	if errors.Is(err, service.ErrInvalidPage) {
+		return http.StatusBadRequest
+	}
+
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,107 @@
+package handler_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"point-service/app/internal/handler"
+	"point-service/app/internal/model"
+	"point-service/app/internal/service"
+	mockService "point-service/app/internal/service/mocks"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type UserPointHandlerTestSuite struct {
+	suite.Suite
+	mux *http.ServeMux
+}
+
+func (suite *UserPointHandlerTestSuite) SetupTest() {
+	userPointService := new(mockService.UserPointService)
+	userPointService.On("GetUserPointBalance", mock.Anything, uint(42)).Return(service.UserPointBalance{
+		UserId: 42,
+		Total:  10,
+		Levels: []model.UserPoint{{UserId: 42, Level: "bronze", Balance: 3}, {UserId: 42, Level: "gold", Balance: 7}},
+	}, nil)
+	userPointService.On("GetUserPointBalance", mock.Anything, mock.Anything).Return(service.UserPointBalance{}, errors.New("connection refused"))
+
+	userPointService.On("ListUserPointLedger", mock.Anything, uint(42), 0, 0).Return(service.UserPointLedgerPage{
+		Entries:  []model.UserPointLedger{{UserId: 42, OrderId: 10, Level: "gold", Type: model.LedgerCredit, Amount: 2, BalanceAfter: 7}},
+		Page:     1,
+		PageSize: 20,
+		Total:    1,
+	}, nil)
+	userPointService.On("ListUserPointLedger", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(service.UserPointLedgerPage{}, fmt.Errorf("%w: page size must be between 1 and 100", service.ErrInvalidPage))
+
+	suite.mux = http.NewServeMux()
+	handler.NewUserPointHandler(userPointService).RegisterRoutes(suite.mux)
+}
+
+func (suite *UserPointHandlerTestSuite) serve(method string, path string) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	suite.mux.ServeHTTP(recorder, httptest.NewRequest(method, path, nil))
+
+	return recorder
+}
+
+func (suite *UserPointHandlerTestSuite) TestUserPointHandler_HappyCase_Balance() {
+	recorder := suite.serve(http.MethodGet, "/admin/users/42/points")
+	suite.Equal(http.StatusOK, recorder.Code)
+	suite.JSONEq(`{"user_id":42,"total":10,"levels":[{"level":"bronze","balance":3},{"level":"gold","balance":7}]}`, recorder.Body.String())
+}
+
+func (suite *UserPointHandlerTestSuite) TestUserPointHandler_AdminToken() {
+	admin := handler.RequireAdminToken("admin-secret", suite.mux)
+
+	for _, path := range []string{"/admin/users/42/points", "/admin/users/42/points/ledger"} {
+		recorder := httptest.NewRecorder()
+		admin.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+		suite.Equal(http.StatusUnauthorized, recorder.Code)
+
+		request := httptest.NewRequest(http.MethodGet, path, nil)
+		request.Header.Set("Authorization", "Bearer admin-secret")
+		recorder = httptest.NewRecorder()
+		admin.ServeHTTP(recorder, request)
+		suite.Equal(http.StatusOK, recorder.Code)
+	}
+}
+
+func (suite *UserPointHandlerTestSuite) TestUserPointHandler_BalanceError() {
+	recorder := suite.serve(http.MethodGet, "/admin/users/43/points")
+	suite.Equal(http.StatusInternalServerError, recorder.Code)
+}
+
+func (suite *UserPointHandlerTestSuite) TestUserPointHandler_HappyCase_Ledger() {
+	recorder := suite.serve(http.MethodGet, "/admin/users/42/points/ledger")
+	suite.Equal(http.StatusOK, recorder.Code)
+	suite.Contains(recorder.Body.String(), `"items":[{"order_id":10,"level":"gold","type":"credit","amount":2,"balance_after":7,`)
+	suite.Contains(recorder.Body.String(), `"total":1`)
+}
+
+func (suite *UserPointHandlerTestSuite) TestUserPointHandler_LedgerInvalidPage() {
+	recorder := suite.serve(http.MethodGet, "/admin/users/42/points/ledger?page_size=1000")
+	suite.Equal(http.StatusBadRequest, recorder.Code)
+
+	recorder = suite.serve(http.MethodGet, "/admin/users/42/points/ledger?page=first")
+	suite.Equal(http.StatusBadRequest, recorder.Code)
+}
+
+func (suite *UserPointHandlerTestSuite) TestUserPointHandler_UnknownPath() {
+	for _, path := range []string{"/admin/users/", "/admin/users/0/points", "/admin/users/bob/points", "/admin/users/42", "/admin/users/42/orders"} {
+		recorder := suite.serve(http.MethodGet, path)
+		suite.Equal(http.StatusNotFound, recorder.Code, path)
+	}
+}
+
+func (suite *UserPointHandlerTestSuite) TestUserPointHandler_MethodNotAllowed() {
+	recorder := suite.serve(http.MethodPost, "/admin/users/42/points")
+	suite.Equal(http.StatusMethodNotAllowed, recorder.Code)
+}
+
+func TestUserPointHandlerTestSuite(t *testing.T) {
+	suite.Run(t, new(UserPointHandlerTestSuite))
+}
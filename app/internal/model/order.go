@@ -1,11 +1,139 @@
 package model
 
+import (
+	"errors"
+	"fmt"
+)
+
+const (
+	// SuccessOrderVersion is the current success.order schema, a message without
+	// version is version 1 and carries a single ProductId instead of Items
+	SuccessOrderVersion = 2
+	// DecreasePointSuccessVersion is the current decrease.point.success schema
+	DecreasePointSuccessVersion = 2
+	// IncreasePointSuccessVersion is the current increase.point.success schema
+	IncreasePointSuccessVersion = 1
+	// DecreasePointSuccessType is the CloudEvents type of decrease.point.success
+	DecreasePointSuccessType = "point.decrease.success"
+)
+
+var (
+	ErrUnsupportedOrderVersion = errors.New("unsupported success order version")
+	ErrInvalidOrder            = errors.New("invalid order")
+	ErrInvalidOrderItem        = errors.New("invalid order item")
+)
+
 type SuccessOrder struct {
-	OrderId   uint `json:"order_id"`
+	Version uint `json:"version,omitempty"`
+	OrderId uint `json:"order_id"`
+	// UserId is credited with the points of the order, an order without user only decreases the pools
+	UserId uint `json:"user_id,omitempty"`
+	// ProductId is the only product of a version 1 order
+	ProductId uint        `json:"product_id,omitempty"`
+	Items     []OrderItem `json:"items,omitempty"`
+}
+
+type OrderItem struct {
 	ProductId uint `json:"product_id"`
+	Quantity  uint `json:"quantity"`
+	// UnitPrice overrides the catalog price of the product, for example a discounted price
+	UnitPrice *float64 `json:"unit_price,omitempty"`
+}
+
+// LineItems returns the items of the order whatever its version, a version 1 order is
+// one item of quantity 1. Both versions are validated the same way as their schemas
+func (order SuccessOrder) LineItems() ([]OrderItem, error) {
+	if order.OrderId == 0 {
+		return nil, fmt.Errorf("%w: order id is required", ErrInvalidOrder)
+	}
+
+	var items []OrderItem
+	switch order.Version {
+	case 0, 1:
+		items = []OrderItem{{ProductId: order.ProductId, Quantity: 1}}
+
+	case SuccessOrderVersion:
+		if len(order.Items) == 0 {
+			return nil, fmt.Errorf("%w: order has no items", ErrInvalidOrderItem)
+		}
+
+		items = order.Items
+
+	default:
+		return nil, ErrUnsupportedOrderVersion
+	}
+
+	for _, item := range items {
+		if item.ProductId == 0 {
+			return nil, fmt.Errorf("%w: product id is required", ErrInvalidOrderItem)
+		}
+		if item.Quantity == 0 {
+			return nil, fmt.Errorf("%w: quantity must be greater than 0", ErrInvalidOrderItem)
+		}
+		if item.UnitPrice != nil && *item.UnitPrice < 0 {
+			return nil, fmt.Errorf("%w: unit price must not be negative", ErrInvalidOrderItem)
+		}
+	}
+
+	return items, nil
+}
+
+// PointUsage is the amount of points an order took from a level
+type PointUsage struct {
+	Level  string `json:"level"`
+	Amount uint   `json:"amount"`
 }
 
 type DecreasePointSuccess struct {
-	OrderId    uint   `json:"order_id"`
-	PointLevel string `json:"point_level"`
+	Version uint `json:"version"`
+	OrderId uint `json:"order_id"`
+	UserId  uint `json:"user_id,omitempty"`
+	// PointLevel is only set when the order took points from a single level, for version 1 consumers
+	PointLevel string       `json:"point_level,omitempty"`
+	Points     []PointUsage `json:"points"`
+}
+
+func NewDecreasePointSuccess(orderId uint, userId uint, points []PointUsage) DecreasePointSuccess {
+	decreasePointSuccess := DecreasePointSuccess{
+		Version: DecreasePointSuccessVersion,
+		OrderId: orderId,
+		UserId:  userId,
+		Points:  points,
+	}
+
+	if len(points) == 1 {
+		decreasePointSuccess.PointLevel = points[0].Level
+	}
+
+	return decreasePointSuccess
+}
+
+// IncreasePointSuccess reports the points a cancelled or refunded order gave back to the pools
+type IncreasePointSuccess struct {
+	Version uint         `json:"version"`
+	OrderId uint         `json:"order_id"`
+	UserId  uint         `json:"user_id,omitempty"`
+	Points  []PointUsage `json:"points"`
+}
+
+func NewIncreasePointSuccess(orderId uint, userId uint, points []PointUsage) IncreasePointSuccess {
+	return IncreasePointSuccess{
+		Version: IncreasePointSuccessVersion,
+		OrderId: orderId,
+		UserId:  userId,
+		Points:  points,
+	}
+}
+
+type OrderCancelled struct {
+	OrderId uint `json:"order_id"`
+}
+
+type OrderRefunded struct {
+	OrderId uint `json:"order_id"`
+}
+
+type DecreasePointFailed struct {
+	OrderId uint   `json:"order_id"`
+	Reason  string `json:"reason"`
 }
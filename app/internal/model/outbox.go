@@ -0,0 +1,17 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type Outbox struct {
+	gorm.Model
+	AggregateId string `gorm:"index"`
+	Topic       string
+	// Payload is the encoded event, json or avro binary
+	Payload     []byte
+	Headers     map[string]string `gorm:"serializer:json"`
+	DeliveredAt *time.Time        `gorm:"index"`
+}
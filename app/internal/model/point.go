@@ -4,7 +4,8 @@ import "gorm.io/gorm"
 
 type Point struct {
 	gorm.Model
-	Level     string
+	// a soft deleted level can be created again
+	Level     string `gorm:"uniqueIndex:idx_points_level,where:deleted_at IS NULL"`
 	Remaining uint
+	Version   uint `gorm:"not null;default:0"`
 }
-
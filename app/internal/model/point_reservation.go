@@ -0,0 +1,33 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	ReservationPending   = "pending"
+	ReservationConfirmed = "confirmed"
+	ReservationCancelled = "cancelled"
+	ReservationExpired   = "expired"
+	// ReservationRefunded is a confirmed reservation of an order cancelled later, its points were given back
+	ReservationRefunded = "refunded"
+	// ReservationConflict is an order that succeeded without its points, they were released and the user
+	// no longer has them, it is left for a manual resolution
+	ReservationConflict = "conflict"
+)
+
+// PointReservation holds points of a user for the checkout of an order, the points
+// are taken from the balance when reserved and given back when cancelled, expired or refunded.
+// A user has at most one reservation by order, a reservation of another user for the same order
+// does not block it.
+type PointReservation struct {
+	gorm.Model
+	UserId    uint `gorm:"uniqueIndex:idx_point_reservations_user_order"`
+	OrderId   uint `gorm:"uniqueIndex:idx_point_reservations_user_order;index"`
+	Level     string
+	Amount    uint
+	Status    string    `gorm:"index"`
+	ExpiresAt time.Time `gorm:"index"`
+}
@@ -0,0 +1,45 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type ProcessedOrder struct {
+	gorm.Model
+	OrderId uint `gorm:"uniqueIndex"`
+	UserId  uint
+	// ProductId and PointLevel are only set on orders processed before multi-item orders
+	ProductId  uint
+	PointLevel string
+	Points     []ProcessedOrderPoint
+	// RestoredAt is set once a cancel or refund of the order gave its points back to the pools
+	RestoredAt *time.Time
+	// Cancelled marks an order cancelled or refunded before its success.order was processed, it
+	// took no points and its success.order is skipped when it comes
+	Cancelled bool `gorm:"not null;default:false"`
+}
+
+// ProcessedOrderPoint is the amount of points a processed order took from a level
+type ProcessedOrderPoint struct {
+	gorm.Model
+	ProcessedOrderId uint `gorm:"index"`
+	Level            string
+	Amount           uint
+}
+
+// PointUsages returns the points the order took, an order processed before multi-item
+// orders took 1 point of its PointLevel
+func (processedOrder ProcessedOrder) PointUsages() []PointUsage {
+	if len(processedOrder.Points) == 0 && processedOrder.PointLevel != "" {
+		return []PointUsage{{Level: processedOrder.PointLevel, Amount: 1}}
+	}
+
+	usages := []PointUsage{}
+	for _, point := range processedOrder.Points {
+		usages = append(usages, PointUsage{Level: point.Level, Amount: point.Amount})
+	}
+
+	return usages
+}
@@ -0,0 +1,34 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// TierRule maps a product price range to a point level. A nil MaxPrice has no upper bound,
+// a nil ActiveFrom or ActiveTo leaves that side of the active window open, the window is checked
+// by TierRuleRepository.GetActiveTierRules.
+type TierRule struct {
+	gorm.Model
+	Level        string
+	MinPrice     float64
+	MinInclusive bool
+	MaxPrice     *float64
+	MaxInclusive bool
+	Priority     int
+	ActiveFrom   *time.Time
+	ActiveTo     *time.Time
+}
+
+func (rule TierRule) Matches(price float64) bool {
+	if price < rule.MinPrice || (price == rule.MinPrice && !rule.MinInclusive) {
+		return false
+	}
+
+	if rule.MaxPrice == nil {
+		return true
+	}
+
+	return price < *rule.MaxPrice || (price == *rule.MaxPrice && rule.MaxInclusive)
+}
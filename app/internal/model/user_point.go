@@ -0,0 +1,35 @@
+package model
+
+import "gorm.io/gorm"
+
+const (
+	LedgerCredit   = "credit"
+	LedgerDebit    = "debit"
+	LedgerReserve  = "reserve"
+	LedgerRelease  = "release"
+	LedgerRedeem   = "redeem"
+	LedgerRefund   = "refund"
+	LedgerClawback = "clawback"
+)
+
+// UserPoint is the balance of a user in one point level
+type UserPoint struct {
+	gorm.Model
+	UserId  uint   `gorm:"uniqueIndex:idx_user_points_user_level"`
+	Level   string `gorm:"uniqueIndex:idx_user_points_user_level"`
+	Balance uint
+}
+
+// UserPointLedger is an append-only entry of a change to a UserPoint, an order credits
+// or debits a level of a user at most once. Shortfall is what a clawback could not take
+// back because the user already spent it.
+type UserPointLedger struct {
+	gorm.Model
+	UserId       uint   `gorm:"index;uniqueIndex:idx_user_point_ledgers_entry"`
+	OrderId      uint   `gorm:"uniqueIndex:idx_user_point_ledgers_entry"`
+	Level        string `gorm:"uniqueIndex:idx_user_point_ledgers_entry"`
+	Type         string `gorm:"uniqueIndex:idx_user_point_ledgers_entry"`
+	Amount       int64
+	BalanceAfter uint
+	Shortfall    uint
+}
@@ -0,0 +1,125 @@
+// Code generated by mockery v2.39.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	model "point-service/app/internal/model"
+
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// OutboxRepository is an autogenerated mock type for the OutboxRepository type
+type OutboxRepository struct {
+	mock.Mock
+}
+
+// CreateOutbox provides a mock function with given fields: ctx, outbox
+func (_m *OutboxRepository) CreateOutbox(ctx context.Context, outbox model.Outbox) error {
+	ret := _m.Called(ctx, outbox)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateOutbox")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, model.Outbox) error); ok {
+		r0 = rf(ctx, outbox)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteDeliveredOutboxes provides a mock function with given fields: ctx, before
+func (_m *OutboxRepository) DeleteDeliveredOutboxes(ctx context.Context, before time.Time) (int64, error) {
+	ret := _m.Called(ctx, before)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteDeliveredOutboxes")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) (int64, error)); ok {
+		return rf(ctx, before)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) int64); ok {
+		r0 = rf(ctx, before)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time) error); ok {
+		r1 = rf(ctx, before)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetPendingOutboxes provides a mock function with given fields: ctx, limit
+func (_m *OutboxRepository) GetPendingOutboxes(ctx context.Context, limit int) ([]model.Outbox, error) {
+	ret := _m.Called(ctx, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetPendingOutboxes")
+	}
+
+	var r0 []model.Outbox
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) ([]model.Outbox, error)); ok {
+		return rf(ctx, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) []model.Outbox); ok {
+		r0 = rf(ctx, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.Outbox)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MarkOutboxDelivered provides a mock function with given fields: ctx, outboxId, deliveredAt
+func (_m *OutboxRepository) MarkOutboxDelivered(ctx context.Context, outboxId uint, deliveredAt time.Time) error {
+	ret := _m.Called(ctx, outboxId, deliveredAt)
+
+	if len(ret) == 0 {
+		panic("no return value specified for MarkOutboxDelivered")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, time.Time) error); ok {
+		r0 = rf(ctx, outboxId, deliveredAt)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewOutboxRepository creates a new instance of OutboxRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewOutboxRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *OutboxRepository {
+	mock := &OutboxRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -4,6 +4,7 @@ package mocks
 
 import (
 	context "context"
+	model "point-service/app/internal/model"
 
 	mock "github.com/stretchr/testify/mock"
 )
@@ -13,17 +14,45 @@ type PointRepository struct {
 	mock.Mock
 }
 
-// DecreaseBronzePoint provides a mock function with given fields: ctx
-func (_m *PointRepository) DecreaseBronzePoint(ctx context.Context) error {
-	ret := _m.Called(ctx)
+// Create provides a mock function with given fields: ctx, point
+func (_m *PointRepository) Create(ctx context.Context, point model.Point) (model.Point, error) {
+	ret := _m.Called(ctx, point)
 
 	if len(ret) == 0 {
-		panic("no return value specified for DecreaseBronzePoint")
+		panic("no return value specified for Create")
+	}
+
+	var r0 model.Point
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, model.Point) (model.Point, error)); ok {
+		return rf(ctx, point)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, model.Point) model.Point); ok {
+		r0 = rf(ctx, point)
+	} else {
+		r0 = ret.Get(0).(model.Point)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, model.Point) error); ok {
+		r1 = rf(ctx, point)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Decrease provides a mock function with given fields: ctx, level, amount
+func (_m *PointRepository) Decrease(ctx context.Context, level string, amount uint) error {
+	ret := _m.Called(ctx, level, amount)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Decrease")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context) error); ok {
-		r0 = rf(ctx)
+	if rf, ok := ret.Get(0).(func(context.Context, string, uint) error); ok {
+		r0 = rf(ctx, level, amount)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -31,17 +60,17 @@ func (_m *PointRepository) DecreaseBronzePoint(ctx context.Context) error {
 	return r0
 }
 
-// DecreaseGoldPoint provides a mock function with given fields: ctx
-func (_m *PointRepository) DecreaseGoldPoint(ctx context.Context) error {
-	ret := _m.Called(ctx)
+// Delete provides a mock function with given fields: ctx, level
+func (_m *PointRepository) Delete(ctx context.Context, level string) error {
+	ret := _m.Called(ctx, level)
 
 	if len(ret) == 0 {
-		panic("no return value specified for DecreaseGoldPoint")
+		panic("no return value specified for Delete")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context) error); ok {
-		r0 = rf(ctx)
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, level)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -49,17 +78,93 @@ func (_m *PointRepository) DecreaseGoldPoint(ctx context.Context) error {
 	return r0
 }
 
-// DecreaseSilverPoint provides a mock function with given fields: ctx
-func (_m *PointRepository) DecreaseSilverPoint(ctx context.Context) error {
-	ret := _m.Called(ctx)
+// Get provides a mock function with given fields: ctx, level
+func (_m *PointRepository) Get(ctx context.Context, level string) (model.Point, error) {
+	ret := _m.Called(ctx, level)
 
 	if len(ret) == 0 {
-		panic("no return value specified for DecreaseSilverPoint")
+		panic("no return value specified for Get")
+	}
+
+	var r0 model.Point
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (model.Point, error)); ok {
+		return rf(ctx, level)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) model.Point); ok {
+		r0 = rf(ctx, level)
+	} else {
+		r0 = ret.Get(0).(model.Point)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, level)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Increase provides a mock function with given fields: ctx, level, amount
+func (_m *PointRepository) Increase(ctx context.Context, level string, amount uint) error {
+	ret := _m.Called(ctx, level, amount)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Increase")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context) error); ok {
+	if rf, ok := ret.Get(0).(func(context.Context, string, uint) error); ok {
+		r0 = rf(ctx, level, amount)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// List provides a mock function with given fields: ctx
+func (_m *PointRepository) List(ctx context.Context) ([]model.Point, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for List")
+	}
+
+	var r0 []model.Point
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]model.Point, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []model.Point); ok {
 		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.Point)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SetRemaining provides a mock function with given fields: ctx, level, remaining
+func (_m *PointRepository) SetRemaining(ctx context.Context, level string, remaining uint) error {
+	ret := _m.Called(ctx, level, remaining)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetRemaining")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, uint) error); ok {
+		r0 = rf(ctx, level, remaining)
 	} else {
 		r0 = ret.Error(0)
 	}
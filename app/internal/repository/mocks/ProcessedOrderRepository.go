@@ -0,0 +1,95 @@
+// Code generated by mockery v2.39.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	model "point-service/app/internal/model"
+
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// ProcessedOrderRepository is an autogenerated mock type for the ProcessedOrderRepository type
+type ProcessedOrderRepository struct {
+	mock.Mock
+}
+
+// CreateProcessedOrder provides a mock function with given fields: ctx, processedOrder
+func (_m *ProcessedOrderRepository) CreateProcessedOrder(ctx context.Context, processedOrder model.ProcessedOrder) error {
+	ret := _m.Called(ctx, processedOrder)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateProcessedOrder")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, model.ProcessedOrder) error); ok {
+		r0 = rf(ctx, processedOrder)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetProcessedOrderByOrderId provides a mock function with given fields: ctx, orderId
+func (_m *ProcessedOrderRepository) GetProcessedOrderByOrderId(ctx context.Context, orderId uint) (model.ProcessedOrder, error) {
+	ret := _m.Called(ctx, orderId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetProcessedOrderByOrderId")
+	}
+
+	var r0 model.ProcessedOrder
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint) (model.ProcessedOrder, error)); ok {
+		return rf(ctx, orderId)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint) model.ProcessedOrder); ok {
+		r0 = rf(ctx, orderId)
+	} else {
+		r0 = ret.Get(0).(model.ProcessedOrder)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint) error); ok {
+		r1 = rf(ctx, orderId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MarkProcessedOrderRestored provides a mock function with given fields: ctx, id, restoredAt
+func (_m *ProcessedOrderRepository) MarkProcessedOrderRestored(ctx context.Context, id uint, restoredAt time.Time) error {
+	ret := _m.Called(ctx, id, restoredAt)
+
+	if len(ret) == 0 {
+		panic("no return value specified for MarkProcessedOrderRestored")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, time.Time) error); ok {
+		r0 = rf(ctx, id, restoredAt)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewProcessedOrderRepository creates a new instance of ProcessedOrderRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewProcessedOrderRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *ProcessedOrderRepository {
+	mock := &ProcessedOrderRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
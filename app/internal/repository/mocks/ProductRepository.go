@@ -14,6 +14,52 @@ type ProductRepository struct {
 	mock.Mock
 }
 
+// CreateProduct provides a mock function with given fields: ctx, product
+func (_m *ProductRepository) CreateProduct(ctx context.Context, product model.Product) (model.Product, error) {
+	ret := _m.Called(ctx, product)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateProduct")
+	}
+
+	var r0 model.Product
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, model.Product) (model.Product, error)); ok {
+		return rf(ctx, product)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, model.Product) model.Product); ok {
+		r0 = rf(ctx, product)
+	} else {
+		r0 = ret.Get(0).(model.Product)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, model.Product) error); ok {
+		r1 = rf(ctx, product)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeleteProduct provides a mock function with given fields: ctx, productId
+func (_m *ProductRepository) DeleteProduct(ctx context.Context, productId uint) error {
+	ret := _m.Called(ctx, productId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteProduct")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint) error); ok {
+		r0 = rf(ctx, productId)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // GetProductById provides a mock function with given fields: ctx, productId
 func (_m *ProductRepository) GetProductById(ctx context.Context, productId uint) (model.Product, error) {
 	ret := _m.Called(ctx, productId)
@@ -42,6 +88,61 @@ func (_m *ProductRepository) GetProductById(ctx context.Context, productId uint)
 	return r0, r1
 }
 
+// ListProducts provides a mock function with given fields: ctx, offset, limit
+func (_m *ProductRepository) ListProducts(ctx context.Context, offset int, limit int) ([]model.Product, int64, error) {
+	ret := _m.Called(ctx, offset, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListProducts")
+	}
+
+	var r0 []model.Product
+	var r1 int64
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) ([]model.Product, int64, error)); ok {
+		return rf(ctx, offset, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) []model.Product); ok {
+		r0 = rf(ctx, offset, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.Product)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, int) int64); ok {
+		r1 = rf(ctx, offset, limit)
+	} else {
+		r1 = ret.Get(1).(int64)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, int, int) error); ok {
+		r2 = rf(ctx, offset, limit)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// UpdateProduct provides a mock function with given fields: ctx, product
+func (_m *ProductRepository) UpdateProduct(ctx context.Context, product model.Product) error {
+	ret := _m.Called(ctx, product)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateProduct")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, model.Product) error); ok {
+		r0 = rf(ctx, product)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // NewProductRepository creates a new instance of ProductRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewProductRepository(t interface {
@@ -0,0 +1,107 @@
+// Code generated by mockery v2.39.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	model "point-service/app/internal/model"
+
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// TierRuleRepository is an autogenerated mock type for the TierRuleRepository type
+type TierRuleRepository struct {
+	mock.Mock
+}
+
+// CountTierRules provides a mock function with given fields: ctx
+func (_m *TierRuleRepository) CountTierRules(ctx context.Context) (int64, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CountTierRules")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (int64, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) int64); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateTierRule provides a mock function with given fields: ctx, tierRule
+func (_m *TierRuleRepository) CreateTierRule(ctx context.Context, tierRule model.TierRule) error {
+	ret := _m.Called(ctx, tierRule)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateTierRule")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, model.TierRule) error); ok {
+		r0 = rf(ctx, tierRule)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetActiveTierRules provides a mock function with given fields: ctx, at
+func (_m *TierRuleRepository) GetActiveTierRules(ctx context.Context, at time.Time) ([]model.TierRule, error) {
+	ret := _m.Called(ctx, at)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetActiveTierRules")
+	}
+
+	var r0 []model.TierRule
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) ([]model.TierRule, error)); ok {
+		return rf(ctx, at)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) []model.TierRule); ok {
+		r0 = rf(ctx, at)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.TierRule)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time) error); ok {
+		r1 = rf(ctx, at)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewTierRuleRepository creates a new instance of TierRuleRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewTierRuleRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *TierRuleRepository {
+	mock := &TierRuleRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,232 @@
+// Code generated by mockery v2.39.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	model "point-service/app/internal/model"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// UserPointRepository is an autogenerated mock type for the UserPointRepository type
+type UserPointRepository struct {
+	mock.Mock
+}
+
+// Clawback provides a mock function with given fields: ctx, userId, level, orderId, amount
+func (_m *UserPointRepository) Clawback(ctx context.Context, userId uint, level string, orderId uint, amount uint) (uint, error) {
+	ret := _m.Called(ctx, userId, level, orderId, amount)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Clawback")
+	}
+
+	var r0 uint
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, string, uint, uint) (uint, error)); ok {
+		return rf(ctx, userId, level, orderId, amount)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint, string, uint, uint) uint); ok {
+		r0 = rf(ctx, userId, level, orderId, amount)
+	} else {
+		r0 = ret.Get(0).(uint)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint, string, uint, uint) error); ok {
+		r1 = rf(ctx, userId, level, orderId, amount)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Credit provides a mock function with given fields: ctx, userId, level, orderId, amount
+func (_m *UserPointRepository) Credit(ctx context.Context, userId uint, level string, orderId uint, amount uint) error {
+	ret := _m.Called(ctx, userId, level, orderId, amount)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Credit")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, string, uint, uint) error); ok {
+		r0 = rf(ctx, userId, level, orderId, amount)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Debit provides a mock function with given fields: ctx, userId, level, orderId, amount
+func (_m *UserPointRepository) Debit(ctx context.Context, userId uint, level string, orderId uint, amount uint) error {
+	ret := _m.Called(ctx, userId, level, orderId, amount)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Debit")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, string, uint, uint) error); ok {
+		r0 = rf(ctx, userId, level, orderId, amount)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetUserPoints provides a mock function with given fields: ctx, userId
+func (_m *UserPointRepository) GetUserPoints(ctx context.Context, userId uint) ([]model.UserPoint, error) {
+	ret := _m.Called(ctx, userId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetUserPoints")
+	}
+
+	var r0 []model.UserPoint
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint) ([]model.UserPoint, error)); ok {
+		return rf(ctx, userId)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint) []model.UserPoint); ok {
+		r0 = rf(ctx, userId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.UserPoint)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint) error); ok {
+		r1 = rf(ctx, userId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListLedger provides a mock function with given fields: ctx, userId, offset, limit
+func (_m *UserPointRepository) ListLedger(ctx context.Context, userId uint, offset int, limit int) ([]model.UserPointLedger, int64, error) {
+	ret := _m.Called(ctx, userId, offset, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListLedger")
+	}
+
+	var r0 []model.UserPointLedger
+	var r1 int64
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, int, int) ([]model.UserPointLedger, int64, error)); ok {
+		return rf(ctx, userId, offset, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint, int, int) []model.UserPointLedger); ok {
+		r0 = rf(ctx, userId, offset, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.UserPointLedger)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint, int, int) int64); ok {
+		r1 = rf(ctx, userId, offset, limit)
+	} else {
+		r1 = ret.Get(1).(int64)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, uint, int, int) error); ok {
+		r2 = rf(ctx, userId, offset, limit)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// Redeem provides a mock function with given fields: ctx, userId, level, orderId, amount
+func (_m *UserPointRepository) Redeem(ctx context.Context, userId uint, level string, orderId uint, amount uint) error {
+	ret := _m.Called(ctx, userId, level, orderId, amount)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Redeem")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, string, uint, uint) error); ok {
+		r0 = rf(ctx, userId, level, orderId, amount)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Refund provides a mock function with given fields: ctx, userId, level, orderId, amount
+func (_m *UserPointRepository) Refund(ctx context.Context, userId uint, level string, orderId uint, amount uint) error {
+	ret := _m.Called(ctx, userId, level, orderId, amount)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Refund")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, string, uint, uint) error); ok {
+		r0 = rf(ctx, userId, level, orderId, amount)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Release provides a mock function with given fields: ctx, userId, level, orderId, amount
+func (_m *UserPointRepository) Release(ctx context.Context, userId uint, level string, orderId uint, amount uint) error {
+	ret := _m.Called(ctx, userId, level, orderId, amount)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Release")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, string, uint, uint) error); ok {
+		r0 = rf(ctx, userId, level, orderId, amount)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Reserve provides a mock function with given fields: ctx, userId, level, orderId, amount
+func (_m *UserPointRepository) Reserve(ctx context.Context, userId uint, level string, orderId uint, amount uint) error {
+	ret := _m.Called(ctx, userId, level, orderId, amount)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Reserve")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, string, uint, uint) error); ok {
+		r0 = rf(ctx, userId, level, orderId, amount)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewUserPointRepository creates a new instance of UserPointRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewUserPointRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *UserPointRepository {
+	mock := &UserPointRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
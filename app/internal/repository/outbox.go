@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"context"
+	"point-service/app/internal/model"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type OutboxRepository interface {
+	CreateOutbox(ctx context.Context, outbox model.Outbox) error
+	GetPendingOutboxes(ctx context.Context, limit int) ([]model.Outbox, error)
+	MarkOutboxDelivered(ctx context.Context, outboxId uint, deliveredAt time.Time) error
+	DeleteDeliveredOutboxes(ctx context.Context, before time.Time) (int64, error)
+}
+
+type outboxRepository struct {
+	db *gorm.DB
+}
+
+func NewOutboxRepository(db *gorm.DB) OutboxRepository {
+	return &outboxRepository{
+		db: db,
+	}
+}
+
+func (repository *outboxRepository) CreateOutbox(ctx context.Context, outbox model.Outbox) error {
+	return conn(ctx, repository.db).Create(&outbox).Error
+}
+
+// GetPendingOutboxes locks the oldest undelivered rows, a concurrent relay waits
+// until they are delivered so events of the same order are never published out of order
+func (repository *outboxRepository) GetPendingOutboxes(ctx context.Context, limit int) ([]model.Outbox, error) {
+	var outboxes []model.Outbox
+
+	err := conn(ctx, repository.db).Model(&model.Outbox{}).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("delivered_at IS NULL").
+		Order("id").
+		Limit(limit).
+		Find(&outboxes).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return outboxes, nil
+}
+
+func (repository *outboxRepository) MarkOutboxDelivered(ctx context.Context, outboxId uint, deliveredAt time.Time) error {
+	return conn(ctx, repository.db).Model(&model.Outbox{}).
+		Where("id = ?", outboxId).
+		Update("delivered_at", deliveredAt).Error
+}
+
+func (repository *outboxRepository) DeleteDeliveredOutboxes(ctx context.Context, before time.Time) (int64, error) {
+	result := conn(ctx, repository.db).Unscoped().
+		Where("delivered_at < ?", before).
+		Delete(&model.Outbox{})
+	if result.Error != nil {
+		return 0, result.Error
+	}
+
+	return result.RowsAffected, nil
+}
@@ -0,0 +1,149 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"point-service/app/internal/model"
+	"point-service/app/internal/repository"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+type OutboxRepositoryTestSuite struct {
+	suite.Suite
+}
+
+func (suite *OutboxRepositoryTestSuite) SetupTest() {}
+
+func (suite *OutboxRepositoryTestSuite) setupDbMockCustomTrx(process func(sqlmock.Sqlmock)) *gorm.DB {
+	// new mock instance
+	mockDb, sqlMock, err := sqlmock.New()
+	if err != nil {
+		panic(err)
+	}
+
+	// new postgres dialector for gorm
+	dialector := postgres.New(postgres.Config{
+		Conn:       mockDb,
+		DriverName: "postgres",
+	})
+
+	process(sqlMock)
+
+	// initialize gorm database
+	db, err := gorm.Open(dialector, &gorm.Config{})
+	if err != nil {
+		panic(err)
+	}
+
+	return db
+}
+
+func (suite *OutboxRepositoryTestSuite) TestOutboxRepository_HappyCase_Create() {
+	db := suite.setupDbMockCustomTrx(func(sqlMock sqlmock.Sqlmock) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "outboxes"`)).
+			WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, "1", "decrease.point.success", []byte(`{"order_id":1}`), `{"x-key":"value"}`, nil).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+		sqlMock.ExpectCommit()
+	})
+	repository := repository.NewOutboxRepository(db)
+
+	err := repository.CreateOutbox(context.Background(), model.Outbox{
+		AggregateId: "1",
+		Topic:       "decrease.point.success",
+		Payload:     []byte(`{"order_id":1}`),
+		Headers:     map[string]string{"x-key": "value"},
+	})
+	suite.Nil(err)
+}
+
+func (suite *OutboxRepositoryTestSuite) TestOutboxRepository_HappyCase_GetPending() {
+	db := suite.setupDbMockCustomTrx(func(sqlMock sqlmock.Sqlmock) {
+		rows := sqlmock.NewRows([]string{"id", "aggregate_id", "topic", "payload", "headers"}).
+			AddRow(1, "1", "decrease.point.success", `{"order_id":1}`, `{"x-key":"value"}`).
+			AddRow(2, "2", "decrease.point.success", `{"order_id":2}`, `{}`)
+		sqlMock.ExpectQuery(regexp.QuoteMeta(`
+			SELECT * FROM "outboxes" 
+			WHERE delivered_at IS NULL 
+			AND "outboxes"."deleted_at" IS NULL 
+			ORDER BY id 
+			LIMIT 10 
+			FOR UPDATE
+		`)).WillReturnRows(rows)
+	})
+	repository := repository.NewOutboxRepository(db)
+
+	outboxes, err := repository.GetPendingOutboxes(context.Background(), 10)
+	suite.Nil(err)
+	suite.Len(outboxes, 2)
+	suite.Equal("value", outboxes[0].Headers["x-key"])
+}
+
+func (suite *OutboxRepositoryTestSuite) TestOutboxRepository_GetPendingError() {
+	db := suite.setupDbMockCustomTrx(func(sqlMock sqlmock.Sqlmock) {
+		sqlMock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "outboxes"`)).
+			WillReturnError(errors.New("select error"))
+	})
+	repository := repository.NewOutboxRepository(db)
+
+	_, err := repository.GetPendingOutboxes(context.Background(), 10)
+	suite.NotNil(err)
+}
+
+func (suite *OutboxRepositoryTestSuite) TestOutboxRepository_HappyCase_MarkDelivered() {
+	db := suite.setupDbMockCustomTrx(func(sqlMock sqlmock.Sqlmock) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectExec(regexp.QuoteMeta(`
+			UPDATE "outboxes" 
+			SET "delivered_at"=$1,"updated_at"=$2 
+			WHERE id = $3 
+			AND "outboxes"."deleted_at" IS NULL
+		`)).WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), 1).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		sqlMock.ExpectCommit()
+	})
+	repository := repository.NewOutboxRepository(db)
+
+	err := repository.MarkOutboxDelivered(context.Background(), 1, time.Now())
+	suite.Nil(err)
+}
+
+func (suite *OutboxRepositoryTestSuite) TestOutboxRepository_HappyCase_DeleteDelivered() {
+	db := suite.setupDbMockCustomTrx(func(sqlMock sqlmock.Sqlmock) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "outboxes" WHERE delivered_at < $1`)).
+			WithArgs(sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 5))
+		sqlMock.ExpectCommit()
+	})
+	repository := repository.NewOutboxRepository(db)
+
+	deleted, err := repository.DeleteDeliveredOutboxes(context.Background(), time.Now())
+	suite.Nil(err)
+	suite.Equal(int64(5), deleted)
+}
+
+func (suite *OutboxRepositoryTestSuite) TestOutboxRepository_DeleteDeliveredError() {
+	db := suite.setupDbMockCustomTrx(func(sqlMock sqlmock.Sqlmock) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "outboxes"`)).
+			WithArgs(sqlmock.AnyArg()).
+			WillReturnError(errors.New("delete error"))
+		sqlMock.ExpectRollback()
+	})
+	repository := repository.NewOutboxRepository(db)
+
+	_, err := repository.DeleteDeliveredOutboxes(context.Background(), time.Now())
+	suite.NotNil(err)
+}
+
+func TestOutboxRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(OutboxRepositoryTestSuite))
+}
@@ -3,95 +3,287 @@ package repository
 import (
 	"context"
 	"errors"
-	"point-service/app/internal/constant"
+	"fmt"
 	"point-service/app/internal/model"
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+var (
+	ErrNotEnoughPoints    = errors.New("not enough points")
+	ErrMaxAttemptsReached = errors.New("maximum attempts reached")
+	ErrPointExists        = errors.New("point level already exists")
+)
+
+// DecreaseStrategy is how concurrent decreases of the same level are kept consistent
+type DecreaseStrategy string
+
+const (
+	// OptimisticStrategy retries a versioned update, cheap when conflicts are rare
+	OptimisticStrategy DecreaseStrategy = "optimistic"
+	// PessimisticStrategy locks the point row with SELECT ... FOR UPDATE, concurrent decreases queue on the lock
+	PessimisticStrategy DecreaseStrategy = "pessimistic"
+	// AtomicStrategy decreases in a single conditional UPDATE, no read and no retry
+	AtomicStrategy DecreaseStrategy = "atomic"
+)
+
+func (strategy DecreaseStrategy) Valid() bool {
+	switch strategy {
+	case OptimisticStrategy, PessimisticStrategy, AtomicStrategy:
+		return true
+	default:
+		return false
+	}
+}
+
+// ParseDecreaseStrategy returns the strategy named name
+func ParseDecreaseStrategy(name string) (DecreaseStrategy, error) {
+	strategy := DecreaseStrategy(name)
+	if !strategy.Valid() {
+		return "", fmt.Errorf("must be one of %s, %s or %s", OptimisticStrategy, PessimisticStrategy, AtomicStrategy)
+	}
+
+	return strategy, nil
+}
+
 type PointRepository interface {
-	DecreaseBronzePoint(ctx context.Context) error
-	DecreaseSilverPoint(ctx context.Context) error
-	DecreaseGoldPoint(ctx context.Context) error
+	Decrease(ctx context.Context, level string, amount uint) error
+	Increase(ctx context.Context, level string, amount uint) error
+	SetRemaining(ctx context.Context, level string, remaining uint) error
+	Get(ctx context.Context, level string) (model.Point, error)
+	List(ctx context.Context) ([]model.Point, error)
+	Create(ctx context.Context, point model.Point) (model.Point, error)
+	Delete(ctx context.Context, level string) error
 }
 
 type pointRepository struct {
 	db         *gorm.DB
+	strategy   DecreaseStrategy
 	waitTime   time.Duration
 	maxAttempt uint
+	onRetry    func(level string)
 }
 
-func NewPointRepository(db *gorm.DB, waitTime time.Duration, maxAttempt uint) PointRepository {
+// NewPointRepository decreases points with strategy, waitTime and maxAttempt only apply to the optimistic one.
+// onRetry is called with the level before each optimistic retry, it may be nil.
+func NewPointRepository(db *gorm.DB, strategy DecreaseStrategy, waitTime time.Duration, maxAttempt uint, onRetry func(level string)) PointRepository {
 	return &pointRepository{
 		db:         db,
+		strategy:   strategy,
 		waitTime:   waitTime,
 		maxAttempt: maxAttempt,
+		onRetry:    onRetry,
 	}
 }
 
-func (repository *pointRepository) DecreaseBronzePoint(ctx context.Context) error {
-	return repository.decreasePoint(ctx, constant.BRONZE)
+func (repository *pointRepository) Decrease(ctx context.Context, level string, amount uint) error {
+	switch repository.strategy {
+	case PessimisticStrategy:
+		return repository.decreasePointPessimistic(ctx, level, amount)
+	case AtomicStrategy:
+		return repository.decreasePointAtomic(ctx, level, amount)
+	default:
+		return repository.decreasePoint(ctx, level, amount)
+	}
 }
 
-func (repository *pointRepository) DecreaseSilverPoint(ctx context.Context) error {
-	return repository.decreasePoint(ctx, constant.SILVER)
+// Increase adds amount to the level in a single statement, bumping the version
+// makes a concurrent decrease that read the old remaining retry
+func (repository *pointRepository) Increase(ctx context.Context, level string, amount uint) error {
+	result := conn(ctx, repository.db).Model(&model.Point{}).
+		Where("level = ?", level).
+		Updates(map[string]interface{}{
+			"remaining": gorm.Expr("remaining + ?", amount),
+			"version":   gorm.Expr("version + 1"),
+		})
+
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
 }
 
-func (repository *pointRepository) DecreaseGoldPoint(ctx context.Context) error {
-	return repository.decreasePoint(ctx, constant.GOLD)
+// SetRemaining overwrites the remaining of the level, the version is bumped like in Increase
+func (repository *pointRepository) SetRemaining(ctx context.Context, level string, remaining uint) error {
+	result := conn(ctx, repository.db).Model(&model.Point{}).
+		Where("level = ?", level).
+		Updates(map[string]interface{}{
+			"remaining": remaining,
+			"version":   gorm.Expr("version + 1"),
+		})
+
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
 }
 
-func (repository *pointRepository) decreasePoint(ctx context.Context, level string) error {
-	attempt := 1
+func (repository *pointRepository) Get(ctx context.Context, level string) (model.Point, error) {
+	var point model.Point
 
-	tx := repository.db.Begin().WithContext(ctx)
-	defer func() {
-		if r := recover(); r != nil {
-			tx.Rollback()
+	err := conn(ctx, repository.db).Model(&model.Point{}).Where("level = ?", level).First(&point).Error
+	if err != nil {
+		return point, err
+	}
+
+	return point, nil
+}
+
+func (repository *pointRepository) List(ctx context.Context) ([]model.Point, error) {
+	var points []model.Point
+
+	err := conn(ctx, repository.db).Model(&model.Point{}).Order("id").Find(&points).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return points, nil
+}
+
+// Create inserts a new level, a level that is not deleted returns ErrPointExists
+func (repository *pointRepository) Create(ctx context.Context, point model.Point) (model.Point, error) {
+	err := conn(ctx, repository.db).Create(&point).Error
+	if isUniqueViolation(err) {
+		return point, ErrPointExists
+	}
+
+	if err != nil {
+		return point, err
+	}
+
+	return point, nil
+}
+
+// Delete soft deletes the level, it is no longer found by Get, List or a decrease
+func (repository *pointRepository) Delete(ctx context.Context, level string) error {
+	result := conn(ctx, repository.db).Where("level = ?", level).Delete(&model.Point{})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// decreasePoint applies optimistic locking on the point version, a conflicting update
+// re-reads the point after waitTime until maxAttempt is reached or ctx is done
+func (repository *pointRepository) decreasePoint(ctx context.Context, level string, amount uint) error {
+	// join the caller transaction if there is one, gorm rolls back on every error or panic
+	return conn(ctx, repository.db).Transaction(func(tx *gorm.DB) error {
+		var attempt uint = 1
+
+		for {
+			var point model.Point
+
+			// find remaining point
+			err := tx.Model(&model.Point{}).Where("level = ?", level).First(&point).Error
+			if err != nil {
+				return err
+			}
+
+			// decrease point
+			if point.Remaining < amount {
+				return ErrNotEnoughPoints
+			}
+
+			// update point only if nobody changed it since it was read
+			result := tx.Model(&model.Point{}).
+				Where("id = ? AND version = ?", point.ID, point.Version).
+				Updates(map[string]interface{}{
+					"remaining": point.Remaining - amount,
+					"version":   gorm.Expr("version + 1"),
+				})
+
+			if result.Error != nil {
+				return result.Error
+			}
+
+			// update success
+			if result.RowsAffected == 1 {
+				return nil
+			}
+
+			if attempt >= repository.maxAttempt {
+				return ErrMaxAttemptsReached
+			}
+			if repository.onRetry != nil {
+				repository.onRetry(level)
+			}
+
+			select {
+			case <-time.After(repository.waitTime):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+
+			attempt++
 		}
-	}()
+	})
+}
 
-	// optimistic locking
-	for {
+func (repository *pointRepository) decreasePointPessimistic(ctx context.Context, level string, amount uint) error {
+	return conn(ctx, repository.db).Transaction(func(tx *gorm.DB) error {
 		var point model.Point
 
-		// find remaining point
-		err := tx.Model(&model.Point{}).Where("level = ?", level).First(&point).Error
+		// lock the point row until the transaction ends
+		err := tx.Model(&model.Point{}).
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("level = ?", level).
+			First(&point).Error
 		if err != nil {
-			tx.Rollback()
 			return err
 		}
 
-		// decrease point
-		if point.Remaining <= 0 {
-			tx.Rollback()
-			return errors.New("not enough points")
+		if point.Remaining < amount {
+			return ErrNotEnoughPoints
 		}
 
-		remaining := point.Remaining - 1
+		return tx.Model(&point).Updates(map[string]interface{}{
+			"remaining": point.Remaining - amount,
+			"version":   gorm.Expr("version + 1"),
+		}).Error
+	})
+}
 
-		// update point after decrease
-		result := repository.db.Model(&model.Point{}).
-			Where("updated_at = ? AND level = ?", point.UpdatedAt, level).
-			Update("remaining", remaining)
+func (repository *pointRepository) decreasePointAtomic(ctx context.Context, level string, amount uint) error {
+	var point model.Point
 
-		if result.Error != nil {
-			tx.Rollback()
-			return result.Error
-		}
+	result := conn(ctx, repository.db).Model(&point).
+		Clauses(clause.Returning{Columns: []clause.Column{{Name: "remaining"}}}).
+		Where("level = ? AND remaining >= ?", level, amount).
+		Updates(map[string]interface{}{
+			"remaining": gorm.Expr("remaining - ?", amount),
+			"version":   gorm.Expr("version + 1"),
+		})
 
-		// update success
-		if result.RowsAffected == 1 {
-			tx.Commit()
-			return nil
-		}
+	if result.Error != nil {
+		return result.Error
+	}
 
-		if attempt == int(repository.maxAttempt) {
-			return errors.New("maximum attempts reached")
-		}
+	if result.RowsAffected == 1 {
+		return nil
+	}
 
-		time.Sleep(repository.waitTime)
-		attempt++
+	// nothing updated, tell a missing level from an empty one
+	_, err := repository.Get(ctx, level)
+	if err != nil {
+		return err
 	}
+
+	return ErrNotEnoughPoints
 }
@@ -0,0 +1,87 @@
+package repository_test
+
+import (
+	"context"
+	"os"
+	"point-service/app/internal/model"
+	"point-service/app/internal/repository"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// BenchmarkDecreaseStrategy compares the decrease strategies with every goroutine hitting the same level.
+// It needs a postgres database that may be written to, raise -cpu for more contention:
+//
+//	POINT_BENCHMARK_DSN="host=localhost user=postgresusr password=secret dbname=songvutdb port=5432 sslmode=disable" \
+//		go test ./app/internal/repository -run '^$' -bench DecreaseStrategy -cpu 1,4,16
+func BenchmarkDecreaseStrategy(b *testing.B) {
+	dsn := os.Getenv("POINT_BENCHMARK_DSN")
+	if dsn == "" {
+		b.Skip("POINT_BENCHMARK_DSN is not set")
+	}
+
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	err = db.AutoMigrate(&model.Point{})
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	strategies := []repository.DecreaseStrategy{
+		repository.OptimisticStrategy,
+		repository.PessimisticStrategy,
+		repository.AtomicStrategy,
+	}
+
+	for _, strategy := range strategies {
+		b.Run(string(strategy), func(b *testing.B) {
+			level := "benchmark-" + string(strategy)
+
+			// start every run with exactly one point per decrease
+			err := db.Unscoped().Where("level = ?", level).Delete(&model.Point{}).Error
+			if err != nil {
+				b.Fatal(err)
+			}
+
+			err = db.Create(&model.Point{Level: level, Remaining: uint(b.N)}).Error
+			if err != nil {
+				b.Fatal(err)
+			}
+
+			pointRepository := repository.NewPointRepository(db, strategy, time.Millisecond, 1000, nil)
+
+			var failures int64
+
+			b.SetParallelism(4)
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					if pointRepository.Decrease(context.Background(), level, 1) != nil {
+						atomic.AddInt64(&failures, 1)
+					}
+				}
+			})
+			b.StopTimer()
+
+			// a failed decrease leaves its point behind, anything else means a lost update
+			point, err := pointRepository.Get(context.Background(), level)
+			if err != nil {
+				b.Fatal(err)
+			}
+
+			if int64(point.Remaining) != failures {
+				b.Fatalf("remaining = %d, want %d", point.Remaining, failures)
+			}
+
+			b.ReportMetric(float64(failures)/float64(b.N), "failures/op")
+		})
+	}
+}
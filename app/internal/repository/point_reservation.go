@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"context"
+	"point-service/app/internal/model"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PointReservationRepository interface {
+	CreateReservation(ctx context.Context, reservation model.PointReservation) (model.PointReservation, error)
+	GetReservation(ctx context.Context, userId uint, orderId uint) (model.PointReservation, error)
+	GetReservationsByOrderId(ctx context.Context, orderId uint) ([]model.PointReservation, error)
+	GetExpiredReservations(ctx context.Context, now time.Time, limit int) ([]model.PointReservation, error)
+	UpdateReservationStatus(ctx context.Context, id uint, status string) error
+}
+
+type pointReservationRepository struct {
+	db *gorm.DB
+}
+
+func NewPointReservationRepository(db *gorm.DB) PointReservationRepository {
+	return &pointReservationRepository{
+		db: db,
+	}
+}
+
+func (repository *pointReservationRepository) CreateReservation(ctx context.Context, reservation model.PointReservation) (model.PointReservation, error) {
+	err := conn(ctx, repository.db).Create(&reservation).Error
+	if err != nil {
+		return reservation, err
+	}
+
+	return reservation, nil
+}
+
+// GetReservation locks the reservation of the user for the order until the transaction ends, a
+// confirm, cancel and the expiry sweeper cannot change the same reservation at once
+func (repository *pointReservationRepository) GetReservation(ctx context.Context, userId uint, orderId uint) (model.PointReservation, error) {
+	var reservation model.PointReservation
+
+	err := conn(ctx, repository.db).Model(&model.PointReservation{}).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("user_id = ? AND order_id = ?", userId, orderId).
+		First(&reservation).Error
+	if err != nil {
+		return reservation, err
+	}
+
+	return reservation, nil
+}
+
+// GetReservationsByOrderId locks the reservations of every user for the order like GetReservation
+func (repository *pointReservationRepository) GetReservationsByOrderId(ctx context.Context, orderId uint) ([]model.PointReservation, error) {
+	var reservations []model.PointReservation
+
+	err := conn(ctx, repository.db).Model(&model.PointReservation{}).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("order_id = ?", orderId).
+		Order("id").
+		Find(&reservations).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return reservations, nil
+}
+
+// GetExpiredReservations locks pending reservations past their expiry, oldest first,
+// reservations locked by another transaction are skipped
+func (repository *pointReservationRepository) GetExpiredReservations(ctx context.Context, now time.Time, limit int) ([]model.PointReservation, error) {
+	var reservations []model.PointReservation
+
+	err := conn(ctx, repository.db).Model(&model.PointReservation{}).
+		Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+		Where("status = ? AND expires_at <= ?", model.ReservationPending, now).
+		Order("expires_at").
+		Limit(limit).
+		Find(&reservations).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return reservations, nil
+}
+
+func (repository *pointReservationRepository) UpdateReservationStatus(ctx context.Context, id uint, status string) error {
+	result := conn(ctx, repository.db).Model(&model.PointReservation{}).Where("id = ?", id).Update("status", status)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
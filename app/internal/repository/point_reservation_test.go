@@ -0,0 +1,212 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"point-service/app/internal/model"
+	"point-service/app/internal/repository"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+type PointReservationRepositoryTestSuite struct {
+	suite.Suite
+}
+
+func (suite *PointReservationRepositoryTestSuite) SetupTest() {}
+
+func (suite *PointReservationRepositoryTestSuite) setupDbMockCustomTrx(process func(sqlmock.Sqlmock)) *gorm.DB {
+	// new mock instance
+	mockDb, sqlMock, err := sqlmock.New()
+	if err != nil {
+		panic(err)
+	}
+
+	// new postgres dialector for gorm
+	dialector := postgres.New(postgres.Config{
+		Conn:       mockDb,
+		DriverName: "postgres",
+	})
+
+	process(sqlMock)
+
+	// initialize gorm database
+	db, err := gorm.Open(dialector, &gorm.Config{})
+	if err != nil {
+		panic(err)
+	}
+
+	return db
+}
+
+func (suite *PointReservationRepositoryTestSuite) TestPointReservationRepository_HappyCase_Create() {
+	expiresAt := time.Date(2024, 1, 1, 10, 15, 0, 0, time.UTC)
+	db := suite.setupDbMockCustomTrx(func(sqlMock sqlmock.Sqlmock) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectQuery(regexp.QuoteMeta(`
+			INSERT INTO "point_reservations" ("created_at","updated_at","deleted_at","user_id","order_id","level","amount","status","expires_at") 
+			VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9) 
+			RETURNING "id"
+		`)).WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, 42, 10, "gold", 5, "pending", expiresAt).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+		sqlMock.ExpectCommit()
+	})
+	pointReservationRepository := repository.NewPointReservationRepository(db)
+
+	reservation, err := pointReservationRepository.CreateReservation(context.Background(), model.PointReservation{
+		UserId:    42,
+		OrderId:   10,
+		Level:     "gold",
+		Amount:    5,
+		Status:    model.ReservationPending,
+		ExpiresAt: expiresAt,
+	})
+	suite.Nil(err)
+	suite.Equal(uint(1), reservation.ID)
+}
+
+func (suite *PointReservationRepositoryTestSuite) TestPointReservationRepository_CreateError() {
+	db := suite.setupDbMockCustomTrx(func(sqlMock sqlmock.Sqlmock) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "point_reservations"`)).
+			WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, 42, 10, "gold", 5, "pending", sqlmock.AnyArg()).
+			WillReturnError(errors.New("duplicate key value violates unique constraint"))
+		sqlMock.ExpectRollback()
+	})
+	pointReservationRepository := repository.NewPointReservationRepository(db)
+
+	_, err := pointReservationRepository.CreateReservation(context.Background(), model.PointReservation{
+		UserId:  42,
+		OrderId: 10,
+		Level:   "gold",
+		Amount:  5,
+		Status:  model.ReservationPending,
+	})
+	suite.NotNil(err)
+}
+
+func (suite *PointReservationRepositoryTestSuite) TestPointReservationRepository_HappyCase_Get() {
+	db := suite.setupDbMockCustomTrx(func(sqlMock sqlmock.Sqlmock) {
+		rows := sqlmock.NewRows([]string{"id", "user_id", "order_id", "level", "amount", "status"}).
+			AddRow(1, 42, 10, "gold", 5, "pending")
+		sqlMock.ExpectQuery(regexp.QuoteMeta(`
+			SELECT * FROM "point_reservations" 
+			WHERE (user_id = $1 AND order_id = $2) 
+			AND "point_reservations"."deleted_at" IS NULL 
+			ORDER BY "point_reservations"."id" 
+			LIMIT 1 
+			FOR UPDATE
+		`)).WithArgs(42, 10).WillReturnRows(rows)
+	})
+	pointReservationRepository := repository.NewPointReservationRepository(db)
+
+	reservation, err := pointReservationRepository.GetReservation(context.Background(), 42, 10)
+	suite.Nil(err)
+	suite.Equal(model.ReservationPending, reservation.Status)
+}
+
+func (suite *PointReservationRepositoryTestSuite) TestPointReservationRepository_GetNotFound() {
+	db := suite.setupDbMockCustomTrx(func(sqlMock sqlmock.Sqlmock) {
+		sqlMock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "point_reservations"`)).
+			WithArgs(42, 10).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	})
+	pointReservationRepository := repository.NewPointReservationRepository(db)
+
+	_, err := pointReservationRepository.GetReservation(context.Background(), 42, 10)
+	suite.ErrorIs(err, gorm.ErrRecordNotFound)
+}
+
+func (suite *PointReservationRepositoryTestSuite) TestPointReservationRepository_HappyCase_GetByOrderId() {
+	db := suite.setupDbMockCustomTrx(func(sqlMock sqlmock.Sqlmock) {
+		rows := sqlmock.NewRows([]string{"id", "user_id", "order_id", "level", "amount", "status"}).
+			AddRow(1, 42, 10, "gold", 5, "confirmed").
+			AddRow(2, 43, 10, "gold", 1, "pending")
+		sqlMock.ExpectQuery(regexp.QuoteMeta(`
+			SELECT * FROM "point_reservations" 
+			WHERE order_id = $1 
+			AND "point_reservations"."deleted_at" IS NULL 
+			ORDER BY id 
+			FOR UPDATE
+		`)).WithArgs(10).WillReturnRows(rows)
+	})
+	pointReservationRepository := repository.NewPointReservationRepository(db)
+
+	reservations, err := pointReservationRepository.GetReservationsByOrderId(context.Background(), 10)
+	suite.Nil(err)
+	suite.Len(reservations, 2)
+}
+
+func (suite *PointReservationRepositoryTestSuite) TestPointReservationRepository_HappyCase_GetExpired() {
+	now := time.Date(2024, 1, 1, 10, 15, 0, 0, time.UTC)
+	db := suite.setupDbMockCustomTrx(func(sqlMock sqlmock.Sqlmock) {
+		rows := sqlmock.NewRows([]string{"id", "user_id", "order_id", "level", "amount", "status"}).
+			AddRow(1, 42, 10, "gold", 5, "pending").
+			AddRow(2, 43, 11, "bronze", 1, "pending")
+		sqlMock.ExpectQuery(regexp.QuoteMeta(`
+			SELECT * FROM "point_reservations" 
+			WHERE (status = $1 AND expires_at <= $2) 
+			AND "point_reservations"."deleted_at" IS NULL 
+			ORDER BY expires_at 
+			LIMIT 100 
+			FOR UPDATE SKIP LOCKED
+		`)).WithArgs("pending", now).WillReturnRows(rows)
+	})
+	pointReservationRepository := repository.NewPointReservationRepository(db)
+
+	reservations, err := pointReservationRepository.GetExpiredReservations(context.Background(), now, 100)
+	suite.Nil(err)
+	suite.Len(reservations, 2)
+}
+
+func (suite *PointReservationRepositoryTestSuite) TestPointReservationRepository_GetExpiredError() {
+	db := suite.setupDbMockCustomTrx(func(sqlMock sqlmock.Sqlmock) {
+		sqlMock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "point_reservations"`)).
+			WithArgs("pending", sqlmock.AnyArg()).WillReturnError(errors.New("select error"))
+	})
+	pointReservationRepository := repository.NewPointReservationRepository(db)
+
+	_, err := pointReservationRepository.GetExpiredReservations(context.Background(), time.Now(), 100)
+	suite.NotNil(err)
+}
+
+func (suite *PointReservationRepositoryTestSuite) TestPointReservationRepository_HappyCase_UpdateStatus() {
+	db := suite.setupDbMockCustomTrx(func(sqlMock sqlmock.Sqlmock) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectExec(regexp.QuoteMeta(`
+			UPDATE "point_reservations" 
+			SET "status"=$1,"updated_at"=$2 
+			WHERE id = $3 
+			AND "point_reservations"."deleted_at" IS NULL
+		`)).WithArgs("confirmed", sqlmock.AnyArg(), 1).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		sqlMock.ExpectCommit()
+	})
+	pointReservationRepository := repository.NewPointReservationRepository(db)
+
+	err := pointReservationRepository.UpdateReservationStatus(context.Background(), 1, model.ReservationConfirmed)
+	suite.Nil(err)
+}
+
+func (suite *PointReservationRepositoryTestSuite) TestPointReservationRepository_UpdateStatusNotFound() {
+	db := suite.setupDbMockCustomTrx(func(sqlMock sqlmock.Sqlmock) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectExec(regexp.QuoteMeta(`UPDATE "point_reservations"`)).
+			WithArgs("confirmed", sqlmock.AnyArg(), 1).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		sqlMock.ExpectCommit()
+	})
+	pointReservationRepository := repository.NewPointReservationRepository(db)
+
+	err := pointReservationRepository.UpdateReservationStatus(context.Background(), 1, model.ReservationConfirmed)
+	suite.ErrorIs(err, gorm.ErrRecordNotFound)
+}
+
+func TestPointReservationRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(PointReservationRepositoryTestSuite))
+}
@@ -3,12 +3,14 @@ package repository_test
 import (
 	"context"
 	"errors"
+	"point-service/app/internal/model"
 	"point-service/app/internal/repository"
 	"regexp"
 	"testing"
 	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/stretchr/testify/suite"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
@@ -40,7 +42,7 @@ func (suite *PointRepositoryTestSuite) setupDbMockTrxSuccess(level string) *gorm
 	sqlMock.ExpectBegin()
 
 	// step2: expect query point by level
-	rows := sqlmock.NewRows([]string{"id", "level", "remaining"}).AddRow(1, level, 1000)
+	rows := sqlmock.NewRows([]string{"id", "level", "remaining", "version"}).AddRow(1, level, 1000, 7)
 	sqlMock.ExpectQuery(regexp.QuoteMeta(`
 		SELECT * FROM "points" 
 		WHERE level = $1
@@ -49,19 +51,16 @@ func (suite *PointRepositoryTestSuite) setupDbMockTrxSuccess(level string) *gorm
 		LIMIT 1
 	`)).WithArgs(level).WillReturnRows(rows)
 
-	// step3: expect beginning of transaction again
-	sqlMock.ExpectBegin()
-
-	// step4: expect update remaining point by level
+	// step3: expect update remaining point by level
 	sqlMock.ExpectExec(regexp.QuoteMeta(`
 		UPDATE "points" 
-		SET "remaining"=$1,"updated_at"=$2 
-		WHERE (updated_at = $3 AND level = $4) 
+		SET "remaining"=$1,"version"=version + 1,"updated_at"=$2 
+		WHERE (id = $3 AND version = $4) 
 		AND "points"."deleted_at" IS NULL
-	`)).WithArgs(999, sqlmock.AnyArg(), sqlmock.AnyArg(), level).
+	`)).WithArgs(999, sqlmock.AnyArg(), 1, 7).
 		WillReturnResult(sqlmock.NewResult(0, 1))
 
-	// step5: expect commit of transaction
+	// step4: expect commit of transaction
 	sqlMock.ExpectCommit()
 
 	// initialize gorm database
@@ -101,25 +100,25 @@ func (suite *PointRepositoryTestSuite) setupDbMockCustomTrx(level string, proces
 
 func (suite *PointRepositoryTestSuite) TestPointRepository_HappyCase_DecreaseBronze() {
 	db := suite.setupDbMockTrxSuccess("bronze")
-	repository := repository.NewPointRepository(db, time.Second, 3)
+	repository := repository.NewPointRepository(db, repository.OptimisticStrategy, time.Second, 3, nil)
 
-	err := repository.DecreaseBronzePoint(context.Background())
+	err := repository.Decrease(context.Background(), "bronze", 1)
 	suite.Nil(err)
 }
 
 func (suite *PointRepositoryTestSuite) TestPointRepository_HappyCase_DecreaseSilver() {
 	db := suite.setupDbMockTrxSuccess("silver")
-	repository := repository.NewPointRepository(db, time.Second, 3)
+	repository := repository.NewPointRepository(db, repository.OptimisticStrategy, time.Second, 3, nil)
 
-	err := repository.DecreaseSilverPoint(context.Background())
+	err := repository.Decrease(context.Background(), "silver", 1)
 	suite.Nil(err)
 }
 
 func (suite *PointRepositoryTestSuite) TestPointRepository_HappyCase_DecreaseGold() {
 	db := suite.setupDbMockTrxSuccess("gold")
-	repository := repository.NewPointRepository(db, time.Second, 3)
+	repository := repository.NewPointRepository(db, repository.OptimisticStrategy, time.Second, 3, nil)
 
-	err := repository.DecreaseGoldPoint(context.Background())
+	err := repository.Decrease(context.Background(), "gold", 1)
 	suite.Nil(err)
 }
 
@@ -136,9 +135,9 @@ func (suite *PointRepositoryTestSuite) TestPointRepository_BeginTrxError() {
 		sqlMock.ExpectRollback()
 	})
 
-	repository := repository.NewPointRepository(db, time.Second, 3)
+	repository := repository.NewPointRepository(db, repository.OptimisticStrategy, time.Second, 3, nil)
 
-	err := repository.DecreaseBronzePoint(context.Background())
+	err := repository.Decrease(context.Background(), "bronze", 1)
 	suite.NotNil(err)
 }
 
@@ -158,9 +157,9 @@ func (suite *PointRepositoryTestSuite) TestPointRepository_RemainingPointLessTha
 		sqlMock.ExpectRollback()
 	})
 
-	repository := repository.NewPointRepository(db, time.Second, 3)
+	repository := repository.NewPointRepository(db, repository.OptimisticStrategy, time.Second, 3, nil)
 
-	err := repository.DecreaseBronzePoint(context.Background())
+	err := repository.Decrease(context.Background(), "bronze", 1)
 	suite.NotNil(err)
 }
 
@@ -168,7 +167,7 @@ func (suite *PointRepositoryTestSuite) TestPointRepository_UpdatePointError() {
 	db := suite.setupDbMockCustomTrx("bronze", func(sqlMock sqlmock.Sqlmock) {
 		sqlMock.ExpectBegin()
 
-		rows := sqlmock.NewRows([]string{"id", "level", "remaining"}).AddRow(1, "bronze", 1000)
+		rows := sqlmock.NewRows([]string{"id", "level", "remaining", "version"}).AddRow(1, "bronze", 1000, 7)
 		sqlMock.ExpectQuery(regexp.QuoteMeta(`
 			SELECT * FROM "points" 
 			WHERE level = $1
@@ -177,22 +176,20 @@ func (suite *PointRepositoryTestSuite) TestPointRepository_UpdatePointError() {
 			LIMIT 1
 		`)).WithArgs("bronze").WillReturnRows(rows)
 
-		sqlMock.ExpectBegin()
-
 		sqlMock.ExpectExec(regexp.QuoteMeta(`
 			UPDATE "points" 
-			SET "remaining"=$1,"updated_at"=$2 
-			WHERE (updated_at = $3 AND level = $4) 
+			SET "remaining"=$1,"version"=version + 1,"updated_at"=$2 
+			WHERE (id = $3 AND version = $4) 
 			AND "points"."deleted_at" IS NULL
-		`)).WithArgs(999, sqlmock.AnyArg(), sqlmock.AnyArg(), "bronze").
+		`)).WithArgs(999, sqlmock.AnyArg(), 1, 7).
 			WillReturnError(errors.New("update error"))
 
 		sqlMock.ExpectRollback()
 	})
 
-	repository := repository.NewPointRepository(db, time.Second, 3)
+	repository := repository.NewPointRepository(db, repository.OptimisticStrategy, time.Second, 3, nil)
 
-	err := repository.DecreaseBronzePoint(context.Background())
+	err := repository.Decrease(context.Background(), "bronze", 1)
 	suite.NotNil(err)
 }
 
@@ -200,7 +197,7 @@ func (suite *PointRepositoryTestSuite) TestPointRepository_AttemptIsOne() {
 	db := suite.setupDbMockCustomTrx("bronze", func(sqlMock sqlmock.Sqlmock) {
 		sqlMock.ExpectBegin()
 
-		firstRow := sqlmock.NewRows([]string{"id", "level", "remaining"}).AddRow(1, "bronze", 1000)
+		firstRow := sqlmock.NewRows([]string{"id", "level", "remaining", "version"}).AddRow(1, "bronze", 1000, 7)
 		sqlMock.ExpectQuery(regexp.QuoteMeta(`
 			SELECT * FROM "points" 
 			WHERE level = $1
@@ -209,19 +206,45 @@ func (suite *PointRepositoryTestSuite) TestPointRepository_AttemptIsOne() {
 			LIMIT 1
 		`)).WithArgs("bronze").WillReturnRows(firstRow)
 
+		sqlMock.ExpectExec(regexp.QuoteMeta(`
+			UPDATE "points" 
+			SET "remaining"=$1,"version"=version + 1,"updated_at"=$2 
+			WHERE (id = $3 AND version = $4) 
+			AND "points"."deleted_at" IS NULL
+		`)).WithArgs(999, sqlmock.AnyArg(), 1, 7).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		sqlMock.ExpectRollback()
+	})
+
+	repository := repository.NewPointRepository(db, repository.OptimisticStrategy, time.Second, 1, nil)
+
+	err := repository.Decrease(context.Background(), "bronze", 1)
+	suite.NotNil(err)
+}
+
+func (suite *PointRepositoryTestSuite) TestPointRepository_AttemptIsTwo() {
+	db := suite.setupDbMockCustomTrx("bronze", func(sqlMock sqlmock.Sqlmock) {
 		sqlMock.ExpectBegin()
 
+		firstRow := sqlmock.NewRows([]string{"id", "level", "remaining", "version"}).AddRow(1, "bronze", 1000, 7)
+		sqlMock.ExpectQuery(regexp.QuoteMeta(`
+			SELECT * FROM "points" 
+			WHERE level = $1
+			AND "points"."deleted_at" IS NULL 
+			ORDER BY "points"."id" 
+			LIMIT 1
+		`)).WithArgs("bronze").WillReturnRows(firstRow)
+
 		sqlMock.ExpectExec(regexp.QuoteMeta(`
 			UPDATE "points" 
-			SET "remaining"=$1,"updated_at"=$2 
-			WHERE (updated_at = $3 AND level = $4) 
+			SET "remaining"=$1,"version"=version + 1,"updated_at"=$2 
+			WHERE (id = $3 AND version = $4) 
 			AND "points"."deleted_at" IS NULL
-		`)).WithArgs(999, sqlmock.AnyArg(), sqlmock.AnyArg(), "bronze").
+		`)).WithArgs(999, sqlmock.AnyArg(), 1, 7).
 			WillReturnResult(sqlmock.NewResult(0, 0))
 
-		sqlMock.ExpectCommit()
-
-		secondRow := sqlmock.NewRows([]string{"id", "level", "remaining"}).AddRow(1, "bronze", 1000)
+		secondRow := sqlmock.NewRows([]string{"id", "level", "remaining", "version"}).AddRow(1, "bronze", 1000, 7)
 		sqlMock.ExpectQuery(regexp.QuoteMeta(`
 			SELECT * FROM "points" 
 			WHERE level = $1
@@ -229,55 +252,479 @@ func (suite *PointRepositoryTestSuite) TestPointRepository_AttemptIsOne() {
 			ORDER BY "points"."id" 
 			LIMIT 1
 		`)).WithArgs("bronze").WillReturnRows(secondRow)
+
+		sqlMock.ExpectExec(regexp.QuoteMeta(`
+			UPDATE "points" 
+			SET "remaining"=$1,"version"=version + 1,"updated_at"=$2 
+			WHERE (id = $3 AND version = $4) 
+			AND "points"."deleted_at" IS NULL
+		`)).WithArgs(999, sqlmock.AnyArg(), 1, 7).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		sqlMock.ExpectRollback()
 	})
 
-	repository := repository.NewPointRepository(db, time.Second, 1)
+	repository := repository.NewPointRepository(db, repository.OptimisticStrategy, time.Second, 2, nil)
 
-	err := repository.DecreaseBronzePoint(context.Background())
+	err := repository.Decrease(context.Background(), "bronze", 1)
 	suite.NotNil(err)
 }
 
-func (suite *PointRepositoryTestSuite) TestPointRepository_AttemptIsTwo() {
+func (suite *PointRepositoryTestSuite) TestPointRepository_HappyCase_SecondAttempt() {
+	db := suite.setupDbMockCustomTrx("bronze", func(sqlMock sqlmock.Sqlmock) {
+		sqlMock.ExpectBegin()
+
+		firstRow := sqlmock.NewRows([]string{"id", "level", "remaining", "version"}).AddRow(1, "bronze", 1000, 7)
+		sqlMock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "points"`)).WithArgs("bronze").WillReturnRows(firstRow)
+		sqlMock.ExpectExec(regexp.QuoteMeta(`UPDATE "points"`)).
+			WithArgs(999, sqlmock.AnyArg(), 1, 7).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		// another consumer decreased the point in between
+		secondRow := sqlmock.NewRows([]string{"id", "level", "remaining", "version"}).AddRow(1, "bronze", 999, 8)
+		sqlMock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "points"`)).WithArgs("bronze").WillReturnRows(secondRow)
+		sqlMock.ExpectExec(regexp.QuoteMeta(`UPDATE "points"`)).
+			WithArgs(998, sqlmock.AnyArg(), 1, 8).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		sqlMock.ExpectCommit()
+	})
+
+	retries := []string{}
+	repository := repository.NewPointRepository(db, repository.OptimisticStrategy, time.Millisecond, 3, func(level string) {
+		retries = append(retries, level)
+	})
+
+	err := repository.Decrease(context.Background(), "bronze", 1)
+	suite.Nil(err)
+	suite.Equal([]string{"bronze"}, retries)
+}
+
+func (suite *PointRepositoryTestSuite) TestPointRepository_ContextCancelledWhileWaiting() {
 	db := suite.setupDbMockCustomTrx("bronze", func(sqlMock sqlmock.Sqlmock) {
 		sqlMock.ExpectBegin()
 
-		firstRow := sqlmock.NewRows([]string{"id", "level", "remaining"}).AddRow(1, "bronze", 1000)
+		rows := sqlmock.NewRows([]string{"id", "level", "remaining", "version"}).AddRow(1, "bronze", 1000, 7)
+		sqlMock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "points"`)).WithArgs("bronze").WillReturnRows(rows)
+		sqlMock.ExpectExec(regexp.QuoteMeta(`UPDATE "points"`)).
+			WithArgs(999, sqlmock.AnyArg(), 1, 7).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		sqlMock.ExpectRollback()
+	})
+
+	repository := repository.NewPointRepository(db, repository.OptimisticStrategy, time.Hour, 3, nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*50)
+	defer cancel()
+
+	err := repository.Decrease(ctx, "bronze", 1)
+	suite.ErrorIs(err, context.DeadlineExceeded)
+}
+
+func (suite *PointRepositoryTestSuite) TestPointRepository_NotEnoughPointsForAmount() {
+	db := suite.setupDbMockCustomTrx("gold", func(sqlMock sqlmock.Sqlmock) {
+		sqlMock.ExpectBegin()
+
+		rows := sqlmock.NewRows([]string{"id", "level", "remaining"}).AddRow(1, "gold", 2)
+		sqlMock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "points"`)).WithArgs("gold").WillReturnRows(rows)
+
+		sqlMock.ExpectRollback()
+	})
+
+	pointRepository := repository.NewPointRepository(db, repository.OptimisticStrategy, time.Second, 3, nil)
+
+	err := pointRepository.Decrease(context.Background(), "gold", 3)
+	suite.ErrorIs(err, repository.ErrNotEnoughPoints)
+}
+
+func (suite *PointRepositoryTestSuite) TestPointRepository_HappyCase_Increase() {
+	db := suite.setupDbMockCustomTrx("gold", func(sqlMock sqlmock.Sqlmock) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectExec(regexp.QuoteMeta(`
+			UPDATE "points" 
+			SET "remaining"=remaining + $1,"version"=version + 1,"updated_at"=$2 
+			WHERE level = $3 
+			AND "points"."deleted_at" IS NULL
+		`)).WithArgs(5, sqlmock.AnyArg(), "gold").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		sqlMock.ExpectCommit()
+	})
+
+	repository := repository.NewPointRepository(db, repository.OptimisticStrategy, time.Second, 3, nil)
+
+	err := repository.Increase(context.Background(), "gold", 5)
+	suite.Nil(err)
+}
+
+func (suite *PointRepositoryTestSuite) TestPointRepository_IncreaseLevelNotFound() {
+	db := suite.setupDbMockCustomTrx("platinum", func(sqlMock sqlmock.Sqlmock) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectExec(regexp.QuoteMeta(`UPDATE "points"`)).
+			WithArgs(5, sqlmock.AnyArg(), "platinum").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		sqlMock.ExpectCommit()
+	})
+
+	repository := repository.NewPointRepository(db, repository.OptimisticStrategy, time.Second, 3, nil)
+
+	err := repository.Increase(context.Background(), "platinum", 5)
+	suite.ErrorIs(err, gorm.ErrRecordNotFound)
+}
+
+func (suite *PointRepositoryTestSuite) TestPointRepository_IncreaseError() {
+	db := suite.setupDbMockCustomTrx("gold", func(sqlMock sqlmock.Sqlmock) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectExec(regexp.QuoteMeta(`UPDATE "points"`)).
+			WithArgs(5, sqlmock.AnyArg(), "gold").
+			WillReturnError(errors.New("update error"))
+		sqlMock.ExpectRollback()
+	})
+
+	repository := repository.NewPointRepository(db, repository.OptimisticStrategy, time.Second, 3, nil)
+
+	err := repository.Increase(context.Background(), "gold", 5)
+	suite.NotNil(err)
+}
+
+func (suite *PointRepositoryTestSuite) TestPointRepository_HappyCase_Get() {
+	db := suite.setupDbMockCustomTrx("gold", func(sqlMock sqlmock.Sqlmock) {
+		rows := sqlmock.NewRows([]string{"id", "level", "remaining"}).AddRow(1, "gold", 10)
 		sqlMock.ExpectQuery(regexp.QuoteMeta(`
 			SELECT * FROM "points" 
 			WHERE level = $1
 			AND "points"."deleted_at" IS NULL 
 			ORDER BY "points"."id" 
 			LIMIT 1
-		`)).WithArgs("bronze").WillReturnRows(firstRow)
+		`)).WithArgs("gold").WillReturnRows(rows)
+	})
 
+	repository := repository.NewPointRepository(db, repository.OptimisticStrategy, time.Second, 3, nil)
+
+	point, err := repository.Get(context.Background(), "gold")
+	suite.Nil(err)
+	suite.Equal(uint(10), point.Remaining)
+}
+
+func (suite *PointRepositoryTestSuite) TestPointRepository_GetError() {
+	db := suite.setupDbMockCustomTrx("gold", func(sqlMock sqlmock.Sqlmock) {
+		sqlMock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "points"`)).
+			WithArgs("gold").
+			WillReturnError(errors.New("select error"))
+	})
+
+	repository := repository.NewPointRepository(db, repository.OptimisticStrategy, time.Second, 3, nil)
+
+	_, err := repository.Get(context.Background(), "gold")
+	suite.NotNil(err)
+}
+
+func (suite *PointRepositoryTestSuite) TestPointRepository_HappyCase_List() {
+	db := suite.setupDbMockCustomTrx("", func(sqlMock sqlmock.Sqlmock) {
+		rows := sqlmock.NewRows([]string{"id", "level", "remaining"}).
+			AddRow(1, "bronze", 1000).
+			AddRow(2, "silver", 500).
+			AddRow(3, "gold", 10)
+		sqlMock.ExpectQuery(regexp.QuoteMeta(`
+			SELECT * FROM "points" 
+			WHERE "points"."deleted_at" IS NULL 
+			ORDER BY id
+		`)).WillReturnRows(rows)
+	})
+
+	repository := repository.NewPointRepository(db, repository.OptimisticStrategy, time.Second, 3, nil)
+
+	points, err := repository.List(context.Background())
+	suite.Nil(err)
+	suite.Len(points, 3)
+	suite.Equal("gold", points[2].Level)
+}
+
+func (suite *PointRepositoryTestSuite) TestPointRepository_ListError() {
+	db := suite.setupDbMockCustomTrx("", func(sqlMock sqlmock.Sqlmock) {
+		sqlMock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "points"`)).
+			WillReturnError(errors.New("select error"))
+	})
+
+	repository := repository.NewPointRepository(db, repository.OptimisticStrategy, time.Second, 3, nil)
+
+	_, err := repository.List(context.Background())
+	suite.NotNil(err)
+}
+
+func (suite *PointRepositoryTestSuite) TestPointRepository_HappyCase_DecreasePessimistic() {
+	db := suite.setupDbMockCustomTrx("gold", func(sqlMock sqlmock.Sqlmock) {
 		sqlMock.ExpectBegin()
 
+		rows := sqlmock.NewRows([]string{"id", "level", "remaining", "version"}).AddRow(1, "gold", 10, 7)
+		sqlMock.ExpectQuery(regexp.QuoteMeta(`
+			SELECT * FROM "points" 
+			WHERE level = $1 
+			AND "points"."deleted_at" IS NULL 
+			ORDER BY "points"."id" 
+			LIMIT 1 
+			FOR UPDATE
+		`)).WithArgs("gold").WillReturnRows(rows)
+
 		sqlMock.ExpectExec(regexp.QuoteMeta(`
 			UPDATE "points" 
-			SET "remaining"=$1,"updated_at"=$2 
-			WHERE (updated_at = $3 AND level = $4) 
-			AND "points"."deleted_at" IS NULL
-		`)).WithArgs(999, sqlmock.AnyArg(), sqlmock.AnyArg(), "bronze").
-			WillReturnResult(sqlmock.NewResult(0, 0))
+			SET "remaining"=$1,"version"=version + 1,"updated_at"=$2 
+			WHERE "points"."deleted_at" IS NULL 
+			AND "id" = $3
+		`)).WithArgs(9, sqlmock.AnyArg(), 1).
+			WillReturnResult(sqlmock.NewResult(0, 1))
 
 		sqlMock.ExpectCommit()
+	})
 
-		secondRow := sqlmock.NewRows([]string{"id", "level", "remaining"}).AddRow(1, "bronze", 1000)
+	repository := repository.NewPointRepository(db, repository.PessimisticStrategy, time.Second, 3, nil)
+
+	err := repository.Decrease(context.Background(), "gold", 1)
+	suite.Nil(err)
+}
+
+func (suite *PointRepositoryTestSuite) TestPointRepository_NotEnoughPointsPessimistic() {
+	db := suite.setupDbMockCustomTrx("gold", func(sqlMock sqlmock.Sqlmock) {
+		sqlMock.ExpectBegin()
+
+		rows := sqlmock.NewRows([]string{"id", "level", "remaining", "version"}).AddRow(1, "gold", 0, 7)
+		sqlMock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "points"`)).WithArgs("gold").WillReturnRows(rows)
+
+		sqlMock.ExpectRollback()
+	})
+
+	pointRepository := repository.NewPointRepository(db, repository.PessimisticStrategy, time.Second, 3, nil)
+
+	err := pointRepository.Decrease(context.Background(), "gold", 1)
+	suite.ErrorIs(err, repository.ErrNotEnoughPoints)
+}
+
+func (suite *PointRepositoryTestSuite) TestPointRepository_SelectErrorPessimistic() {
+	db := suite.setupDbMockCustomTrx("gold", func(sqlMock sqlmock.Sqlmock) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "points"`)).WithArgs("gold").WillReturnError(errors.New("select error"))
+		sqlMock.ExpectRollback()
+	})
+
+	repository := repository.NewPointRepository(db, repository.PessimisticStrategy, time.Second, 3, nil)
+
+	err := repository.Decrease(context.Background(), "gold", 1)
+	suite.NotNil(err)
+}
+
+func (suite *PointRepositoryTestSuite) TestPointRepository_HappyCase_DecreaseAtomic() {
+	db := suite.setupDbMockCustomTrx("gold", func(sqlMock sqlmock.Sqlmock) {
+		sqlMock.ExpectBegin()
 		sqlMock.ExpectQuery(regexp.QuoteMeta(`
-			SELECT * FROM "points" 
-			WHERE level = $1
+			UPDATE "points" 
+			SET "remaining"=remaining - $1,"version"=version + 1,"updated_at"=$2 
+			WHERE (level = $3 AND remaining >= $4) 
 			AND "points"."deleted_at" IS NULL 
-			ORDER BY "points"."id" 
-			LIMIT 1
-		`)).WithArgs("bronze").WillReturnRows(secondRow)
+			RETURNING "remaining"
+		`)).WithArgs(1, sqlmock.AnyArg(), "gold", 1).
+			WillReturnRows(sqlmock.NewRows([]string{"remaining"}).AddRow(9))
+		sqlMock.ExpectCommit()
 	})
 
-	repository := repository.NewPointRepository(db, time.Second, 2)
+	repository := repository.NewPointRepository(db, repository.AtomicStrategy, time.Second, 3, nil)
 
-	err := repository.DecreaseBronzePoint(context.Background())
+	err := repository.Decrease(context.Background(), "gold", 1)
+	suite.Nil(err)
+}
+
+func (suite *PointRepositoryTestSuite) TestPointRepository_NotEnoughPointsAtomic() {
+	db := suite.setupDbMockCustomTrx("gold", func(sqlMock sqlmock.Sqlmock) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectQuery(regexp.QuoteMeta(`UPDATE "points"`)).
+			WithArgs(1, sqlmock.AnyArg(), "gold", 1).
+			WillReturnRows(sqlmock.NewRows([]string{"remaining"}))
+		sqlMock.ExpectCommit()
+
+		rows := sqlmock.NewRows([]string{"id", "level", "remaining", "version"}).AddRow(1, "gold", 0, 7)
+		sqlMock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "points"`)).WithArgs("gold").WillReturnRows(rows)
+	})
+
+	pointRepository := repository.NewPointRepository(db, repository.AtomicStrategy, time.Second, 3, nil)
+
+	err := pointRepository.Decrease(context.Background(), "gold", 1)
+	suite.ErrorIs(err, repository.ErrNotEnoughPoints)
+}
+
+func (suite *PointRepositoryTestSuite) TestPointRepository_LevelNotFoundAtomic() {
+	db := suite.setupDbMockCustomTrx("platinum", func(sqlMock sqlmock.Sqlmock) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectQuery(regexp.QuoteMeta(`UPDATE "points"`)).
+			WithArgs(1, sqlmock.AnyArg(), "platinum", 1).
+			WillReturnRows(sqlmock.NewRows([]string{"remaining"}))
+		sqlMock.ExpectCommit()
+
+		sqlMock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "points"`)).WithArgs("platinum").WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	})
+
+	repository := repository.NewPointRepository(db, repository.AtomicStrategy, time.Second, 3, nil)
+
+	err := repository.Decrease(context.Background(), "platinum", 1)
+	suite.ErrorIs(err, gorm.ErrRecordNotFound)
+}
+
+func (suite *PointRepositoryTestSuite) TestPointRepository_UpdateErrorAtomic() {
+	db := suite.setupDbMockCustomTrx("gold", func(sqlMock sqlmock.Sqlmock) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectQuery(regexp.QuoteMeta(`UPDATE "points"`)).
+			WithArgs(1, sqlmock.AnyArg(), "gold", 1).
+			WillReturnError(errors.New("update error"))
+		sqlMock.ExpectRollback()
+	})
+
+	repository := repository.NewPointRepository(db, repository.AtomicStrategy, time.Second, 3, nil)
+
+	err := repository.Decrease(context.Background(), "gold", 1)
 	suite.NotNil(err)
 }
 
+func (suite *PointRepositoryTestSuite) TestPointRepository_HappyCase_SetRemaining() {
+	db := suite.setupDbMockCustomTrx("gold", func(sqlMock sqlmock.Sqlmock) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectExec(regexp.QuoteMeta(`
+			UPDATE "points" 
+			SET "remaining"=$1,"version"=version + 1,"updated_at"=$2 
+			WHERE level = $3 
+			AND "points"."deleted_at" IS NULL
+		`)).WithArgs(50, sqlmock.AnyArg(), "gold").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		sqlMock.ExpectCommit()
+	})
+
+	repository := repository.NewPointRepository(db, repository.OptimisticStrategy, time.Second, 3, nil)
+
+	err := repository.SetRemaining(context.Background(), "gold", 50)
+	suite.Nil(err)
+}
+
+func (suite *PointRepositoryTestSuite) TestPointRepository_SetRemainingLevelNotFound() {
+	db := suite.setupDbMockCustomTrx("platinum", func(sqlMock sqlmock.Sqlmock) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectExec(regexp.QuoteMeta(`UPDATE "points"`)).
+			WithArgs(50, sqlmock.AnyArg(), "platinum").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		sqlMock.ExpectCommit()
+	})
+
+	repository := repository.NewPointRepository(db, repository.OptimisticStrategy, time.Second, 3, nil)
+
+	err := repository.SetRemaining(context.Background(), "platinum", 50)
+	suite.ErrorIs(err, gorm.ErrRecordNotFound)
+}
+
+func (suite *PointRepositoryTestSuite) TestPointRepository_SetRemainingError() {
+	db := suite.setupDbMockCustomTrx("gold", func(sqlMock sqlmock.Sqlmock) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectExec(regexp.QuoteMeta(`UPDATE "points"`)).
+			WithArgs(50, sqlmock.AnyArg(), "gold").
+			WillReturnError(errors.New("update error"))
+		sqlMock.ExpectRollback()
+	})
+
+	repository := repository.NewPointRepository(db, repository.OptimisticStrategy, time.Second, 3, nil)
+
+	err := repository.SetRemaining(context.Background(), "gold", 50)
+	suite.NotNil(err)
+}
+
+func (suite *PointRepositoryTestSuite) TestPointRepository_HappyCase_Create() {
+	db := suite.setupDbMockCustomTrx("platinum", func(sqlMock sqlmock.Sqlmock) {
+		sqlMock.ExpectBegin()
+		rows := sqlmock.NewRows([]string{"id"}).AddRow(4)
+		sqlMock.ExpectQuery(regexp.QuoteMeta(`
+			INSERT INTO "points" ("created_at","updated_at","deleted_at","level","remaining","version") 
+			VALUES ($1,$2,$3,$4,$5,$6) 
+			RETURNING "id"
+		`)).WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, "platinum", 100, 0).
+			WillReturnRows(rows)
+		sqlMock.ExpectCommit()
+	})
+
+	repository := repository.NewPointRepository(db, repository.OptimisticStrategy, time.Second, 3, nil)
+
+	point, err := repository.Create(context.Background(), model.Point{Level: "platinum", Remaining: 100})
+	suite.Nil(err)
+	suite.Equal(uint(4), point.ID)
+	suite.Equal("platinum", point.Level)
+}
+
+func (suite *PointRepositoryTestSuite) TestPointRepository_CreateError() {
+	db := suite.setupDbMockCustomTrx("platinum", func(sqlMock sqlmock.Sqlmock) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "points"`)).
+			WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, "platinum", 100, 0).
+			WillReturnError(errors.New("insert error"))
+		sqlMock.ExpectRollback()
+	})
+
+	repository := repository.NewPointRepository(db, repository.OptimisticStrategy, time.Second, 3, nil)
+
+	_, err := repository.Create(context.Background(), model.Point{Level: "platinum", Remaining: 100})
+	suite.NotNil(err)
+}
+
+func (suite *PointRepositoryTestSuite) TestPointRepository_CreateExists() {
+	db := suite.setupDbMockCustomTrx("platinum", func(sqlMock sqlmock.Sqlmock) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "points"`)).
+			WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, "platinum", 100, 0).
+			WillReturnError(&pgconn.PgError{Code: "23505", ConstraintName: "idx_points_level"})
+		sqlMock.ExpectRollback()
+	})
+
+	pointRepository := repository.NewPointRepository(db, repository.OptimisticStrategy, time.Second, 3, nil)
+
+	_, err := pointRepository.Create(context.Background(), model.Point{Level: "platinum", Remaining: 100})
+	suite.ErrorIs(err, repository.ErrPointExists)
+}
+
+func (suite *PointRepositoryTestSuite) TestPointRepository_HappyCase_Delete() {
+	db := suite.setupDbMockCustomTrx("gold", func(sqlMock sqlmock.Sqlmock) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectExec(regexp.QuoteMeta(`
+			UPDATE "points" 
+			SET "deleted_at"=$1 
+			WHERE level = $2 
+			AND "points"."deleted_at" IS NULL
+		`)).WithArgs(sqlmock.AnyArg(), "gold").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		sqlMock.ExpectCommit()
+	})
+
+	repository := repository.NewPointRepository(db, repository.OptimisticStrategy, time.Second, 3, nil)
+
+	err := repository.Delete(context.Background(), "gold")
+	suite.Nil(err)
+}
+
+func (suite *PointRepositoryTestSuite) TestPointRepository_DeleteLevelNotFound() {
+	db := suite.setupDbMockCustomTrx("platinum", func(sqlMock sqlmock.Sqlmock) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectExec(regexp.QuoteMeta(`UPDATE "points"`)).
+			WithArgs(sqlmock.AnyArg(), "platinum").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		sqlMock.ExpectCommit()
+	})
+
+	repository := repository.NewPointRepository(db, repository.OptimisticStrategy, time.Second, 3, nil)
+
+	err := repository.Delete(context.Background(), "platinum")
+	suite.ErrorIs(err, gorm.ErrRecordNotFound)
+}
+
+func (suite *PointRepositoryTestSuite) TestPointRepository_ParseDecreaseStrategy() {
+	strategy, err := repository.ParseDecreaseStrategy("atomic")
+	suite.Nil(err)
+	suite.Equal(repository.AtomicStrategy, strategy)
+
+	_, err = repository.ParseDecreaseStrategy("random")
+	suite.EqualError(err, "must be one of optimistic, pessimistic or atomic")
+}
+
 func TestPointRepositoryTestSuite(t *testing.T) {
 	suite.Run(t, new(PointRepositoryTestSuite))
 }
@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"point-service/app/internal/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrProcessedOrderRestored = errors.New("processed order already restored")
+	ErrProcessedOrderExists   = errors.New("processed order already exists")
+)
+
+type ProcessedOrderRepository interface {
+	GetProcessedOrderByOrderId(ctx context.Context, orderId uint) (model.ProcessedOrder, error)
+	CreateProcessedOrder(ctx context.Context, processedOrder model.ProcessedOrder) error
+	MarkProcessedOrderRestored(ctx context.Context, id uint, restoredAt time.Time) error
+}
+
+type processedOrderRepository struct {
+	db *gorm.DB
+}
+
+func NewProcessedOrderRepository(db *gorm.DB) ProcessedOrderRepository {
+	return &processedOrderRepository{
+		db: db,
+	}
+}
+
+func (repository *processedOrderRepository) GetProcessedOrderByOrderId(ctx context.Context, orderId uint) (model.ProcessedOrder, error) {
+	var processedOrder model.ProcessedOrder
+
+	err := conn(ctx, repository.db).Model(&model.ProcessedOrder{}).Preload("Points").Where("order_id = ?", orderId).First(&processedOrder).Error
+	if err != nil {
+		return processedOrder, err
+	}
+
+	return processedOrder, nil
+}
+
+// CreateProcessedOrder records a processed order, an order recorded by a concurrent delivery that
+// committed first returns ErrProcessedOrderExists
+func (repository *processedOrderRepository) CreateProcessedOrder(ctx context.Context, processedOrder model.ProcessedOrder) error {
+	err := conn(ctx, repository.db).Create(&processedOrder).Error
+	if isUniqueViolation(err) {
+		return ErrProcessedOrderExists
+	}
+
+	return err
+}
+
+// MarkProcessedOrderRestored sets the restored time of a processed order that was not restored yet,
+// a cancel and a refund of the same order racing each other cannot both give the points back
+func (repository *processedOrderRepository) MarkProcessedOrderRestored(ctx context.Context, id uint, restoredAt time.Time) error {
+	result := conn(ctx, repository.db).Model(&model.ProcessedOrder{}).
+		Where("id = ? AND restored_at IS NULL", id).
+		Update("restored_at", restoredAt)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return ErrProcessedOrderRestored
+	}
+
+	return nil
+}
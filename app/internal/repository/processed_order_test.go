@@ -0,0 +1,209 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"point-service/app/internal/model"
+	"point-service/app/internal/repository"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+type ProcessedOrderRepositoryTestSuite struct {
+	suite.Suite
+}
+
+func (suite *ProcessedOrderRepositoryTestSuite) SetupTest() {}
+
+func (suite *ProcessedOrderRepositoryTestSuite) setupDbMockCustomTrx(process func(sqlmock.Sqlmock)) *gorm.DB {
+	// new mock instance
+	mockDb, sqlMock, err := sqlmock.New()
+	if err != nil {
+		panic(err)
+	}
+
+	// new postgres dialector for gorm
+	dialector := postgres.New(postgres.Config{
+		Conn:       mockDb,
+		DriverName: "postgres",
+	})
+
+	process(sqlMock)
+
+	// initialize gorm database
+	db, err := gorm.Open(dialector, &gorm.Config{})
+	if err != nil {
+		panic(err)
+	}
+
+	return db
+}
+
+func (suite *ProcessedOrderRepositoryTestSuite) TestProcessedOrderRepository_HappyCase_Get() {
+	db := suite.setupDbMockCustomTrx(func(sqlMock sqlmock.Sqlmock) {
+		rows := sqlmock.NewRows([]string{"id", "order_id", "product_id", "point_level"}).AddRow(1, 10, 1, "gold")
+		sqlMock.ExpectQuery(regexp.QuoteMeta(`
+			SELECT * FROM "processed_orders" 
+			WHERE order_id = $1
+			AND "processed_orders"."deleted_at" IS NULL 
+			ORDER BY "processed_orders"."id" 
+			LIMIT 1
+		`)).WithArgs(10).WillReturnRows(rows)
+
+		pointRows := sqlmock.NewRows([]string{"id", "processed_order_id", "level", "amount"}).
+			AddRow(1, 1, "gold", 1).
+			AddRow(2, 1, "silver", 3)
+		sqlMock.ExpectQuery(regexp.QuoteMeta(`
+			SELECT * FROM "processed_order_points" 
+			WHERE "processed_order_points"."processed_order_id" = $1 
+			AND "processed_order_points"."deleted_at" IS NULL
+		`)).WithArgs(1).WillReturnRows(pointRows)
+	})
+	repository := repository.NewProcessedOrderRepository(db)
+
+	processedOrder, err := repository.GetProcessedOrderByOrderId(context.Background(), 10)
+	suite.Nil(err)
+	suite.Equal(uint(10), processedOrder.OrderId)
+	suite.Equal([]model.PointUsage{{Level: "gold", Amount: 1}, {Level: "silver", Amount: 3}}, processedOrder.PointUsages())
+}
+
+func (suite *ProcessedOrderRepositoryTestSuite) TestProcessedOrderRepository_HappyCase_GetLegacy() {
+	db := suite.setupDbMockCustomTrx(func(sqlMock sqlmock.Sqlmock) {
+		rows := sqlmock.NewRows([]string{"id", "order_id", "product_id", "point_level"}).AddRow(1, 10, 1, "gold")
+		sqlMock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "processed_orders"`)).WithArgs(10).WillReturnRows(rows)
+		sqlMock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "processed_order_points"`)).WithArgs(1).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	})
+	repository := repository.NewProcessedOrderRepository(db)
+
+	processedOrder, err := repository.GetProcessedOrderByOrderId(context.Background(), 10)
+	suite.Nil(err)
+	suite.Equal("gold", processedOrder.PointLevel)
+	suite.Equal([]model.PointUsage{{Level: "gold", Amount: 1}}, processedOrder.PointUsages())
+}
+
+func (suite *ProcessedOrderRepositoryTestSuite) TestProcessedOrderRepository_GetNotFound() {
+	db := suite.setupDbMockCustomTrx(func(sqlMock sqlmock.Sqlmock) {
+		sqlMock.ExpectQuery(regexp.QuoteMeta(`
+			SELECT * FROM "processed_orders" 
+			WHERE order_id = $1
+			AND "processed_orders"."deleted_at" IS NULL 
+			ORDER BY "processed_orders"."id" 
+			LIMIT 1
+		`)).WithArgs(10).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	})
+	repository := repository.NewProcessedOrderRepository(db)
+
+	_, err := repository.GetProcessedOrderByOrderId(context.Background(), 10)
+	suite.ErrorIs(err, gorm.ErrRecordNotFound)
+}
+
+func (suite *ProcessedOrderRepositoryTestSuite) TestProcessedOrderRepository_HappyCase_Create() {
+	db := suite.setupDbMockCustomTrx(func(sqlMock sqlmock.Sqlmock) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "processed_orders"`)).
+			WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, 10, 0, 1, "gold", nil, false).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+		sqlMock.ExpectCommit()
+	})
+	repository := repository.NewProcessedOrderRepository(db)
+
+	err := repository.CreateProcessedOrder(context.Background(), model.ProcessedOrder{OrderId: 10, ProductId: 1, PointLevel: "gold"})
+	suite.Nil(err)
+}
+
+func (suite *ProcessedOrderRepositoryTestSuite) TestProcessedOrderRepository_HappyCase_CreateWithPoints() {
+	db := suite.setupDbMockCustomTrx(func(sqlMock sqlmock.Sqlmock) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "processed_orders"`)).
+			WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, 10, 42, 0, "", nil, false).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+		sqlMock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "processed_order_points"`)).
+			WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, 1, "gold", 1, sqlmock.AnyArg(), sqlmock.AnyArg(), nil, 1, "silver", 3).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1).AddRow(2))
+		sqlMock.ExpectCommit()
+	})
+	repository := repository.NewProcessedOrderRepository(db)
+
+	err := repository.CreateProcessedOrder(context.Background(), model.ProcessedOrder{
+		OrderId: 10,
+		UserId:  42,
+		Points: []model.ProcessedOrderPoint{
+			{Level: "gold", Amount: 1},
+			{Level: "silver", Amount: 3},
+		},
+	})
+	suite.Nil(err)
+}
+
+func (suite *ProcessedOrderRepositoryTestSuite) TestProcessedOrderRepository_CreateError() {
+	db := suite.setupDbMockCustomTrx(func(sqlMock sqlmock.Sqlmock) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "processed_orders"`)).
+			WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, 10, 0, 1, "gold", nil, false).
+			WillReturnError(errors.New("duplicate key value violates unique constraint"))
+		sqlMock.ExpectRollback()
+	})
+	repository := repository.NewProcessedOrderRepository(db)
+
+	err := repository.CreateProcessedOrder(context.Background(), model.ProcessedOrder{OrderId: 10, ProductId: 1, PointLevel: "gold"})
+	suite.NotNil(err)
+}
+
+func (suite *ProcessedOrderRepositoryTestSuite) TestProcessedOrderRepository_CreateExists() {
+	db := suite.setupDbMockCustomTrx(func(sqlMock sqlmock.Sqlmock) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "processed_orders"`)).
+			WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, 10, 0, 1, "gold", nil, false).
+			WillReturnError(&pgconn.PgError{Code: "23505", ConstraintName: "idx_processed_orders_order_id"})
+		sqlMock.ExpectRollback()
+	})
+	processedOrderRepository := repository.NewProcessedOrderRepository(db)
+
+	err := processedOrderRepository.CreateProcessedOrder(context.Background(), model.ProcessedOrder{OrderId: 10, ProductId: 1, PointLevel: "gold"})
+	suite.ErrorIs(err, repository.ErrProcessedOrderExists)
+}
+
+func (suite *ProcessedOrderRepositoryTestSuite) TestProcessedOrderRepository_HappyCase_MarkRestored() {
+	restoredAt := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	db := suite.setupDbMockCustomTrx(func(sqlMock sqlmock.Sqlmock) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectExec(regexp.QuoteMeta(`
+			UPDATE "processed_orders" 
+			SET "restored_at"=$1,"updated_at"=$2 
+			WHERE (id = $3 AND restored_at IS NULL) 
+			AND "processed_orders"."deleted_at" IS NULL
+		`)).WithArgs(restoredAt, sqlmock.AnyArg(), 1).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		sqlMock.ExpectCommit()
+	})
+	processedOrderRepository := repository.NewProcessedOrderRepository(db)
+
+	err := processedOrderRepository.MarkProcessedOrderRestored(context.Background(), 1, restoredAt)
+	suite.Nil(err)
+}
+
+func (suite *ProcessedOrderRepositoryTestSuite) TestProcessedOrderRepository_MarkRestoredTwice() {
+	db := suite.setupDbMockCustomTrx(func(sqlMock sqlmock.Sqlmock) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectExec(regexp.QuoteMeta(`UPDATE "processed_orders"`)).
+			WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), 1).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		sqlMock.ExpectCommit()
+	})
+	processedOrderRepository := repository.NewProcessedOrderRepository(db)
+
+	err := processedOrderRepository.MarkProcessedOrderRestored(context.Background(), 1, time.Now())
+	suite.ErrorIs(err, repository.ErrProcessedOrderRestored)
+}
+
+func TestProcessedOrderRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(ProcessedOrderRepositoryTestSuite))
+}
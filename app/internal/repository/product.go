@@ -9,6 +9,10 @@ import (
 
 type ProductRepository interface {
 	GetProductById(ctx context.Context, productId uint) (model.Product, error)
+	ListProducts(ctx context.Context, offset int, limit int) ([]model.Product, int64, error)
+	CreateProduct(ctx context.Context, product model.Product) (model.Product, error)
+	UpdateProduct(ctx context.Context, product model.Product) error
+	DeleteProduct(ctx context.Context, productId uint) error
 }
 
 type productRepository struct {
@@ -24,10 +28,71 @@ func NewProductRepository(db *gorm.DB) ProductRepository {
 func (repository *productRepository) GetProductById(ctx context.Context, productId uint) (model.Product, error) {
 	var product model.Product
 
-	err := repository.db.WithContext(ctx).Model(&model.Product{}).Where("id = ?", productId).First(&product).Error
+	err := conn(ctx, repository.db).Model(&model.Product{}).Where("id = ?", productId).First(&product).Error
 	if err != nil {
 		return product, err
 	}
 
 	return product, nil
 }
+
+// ListProducts returns a page of products ordered by id and the total count, deleted products are left out
+func (repository *productRepository) ListProducts(ctx context.Context, offset int, limit int) ([]model.Product, int64, error) {
+	var products []model.Product
+	var total int64
+
+	err := conn(ctx, repository.db).Model(&model.Product{}).Count(&total).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	err = conn(ctx, repository.db).Model(&model.Product{}).Order("id").Offset(offset).Limit(limit).Find(&products).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return products, total, nil
+}
+
+func (repository *productRepository) CreateProduct(ctx context.Context, product model.Product) (model.Product, error) {
+	err := conn(ctx, repository.db).Create(&product).Error
+	if err != nil {
+		return product, err
+	}
+
+	return product, nil
+}
+
+// UpdateProduct saves the name and price of the product with the same id, a deleted product is not found
+func (repository *productRepository) UpdateProduct(ctx context.Context, product model.Product) error {
+	result := conn(ctx, repository.db).Model(&model.Product{}).
+		Where("id = ?", product.ID).
+		Updates(map[string]interface{}{
+			"name":  product.Name,
+			"price": product.Price,
+		})
+
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// DeleteProduct soft deletes the product, orders of it no longer earn points
+func (repository *productRepository) DeleteProduct(ctx context.Context, productId uint) error {
+	result := conn(ctx, repository.db).Where("id = ?", productId).Delete(&model.Product{})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
@@ -3,6 +3,7 @@ package repository_test
 import (
 	"context"
 	"errors"
+	"point-service/app/internal/model"
 	"point-service/app/internal/repository"
 	"regexp"
 	"testing"
@@ -80,6 +81,185 @@ func (suite *ProductRepositoryTestSuite) TestProductRepository_GetProductError()
 	suite.NotNil(err)
 }
 
+func (suite *ProductRepositoryTestSuite) TestProductRepository_HappyCase_ListProducts() {
+	db := suite.setupDbMockCustomTrx(func(sqlMock sqlmock.Sqlmock) {
+		sqlMock.ExpectQuery(regexp.QuoteMeta(`
+			SELECT count(*) FROM "products" 
+			WHERE "products"."deleted_at" IS NULL
+		`)).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(25))
+
+		rows := sqlmock.NewRows([]string{"id", "name", "price"}).AddRow(21, "car", 77).AddRow(22, "bike", 1000.5)
+		sqlMock.ExpectQuery(regexp.QuoteMeta(`
+			SELECT * FROM "products" 
+			WHERE "products"."deleted_at" IS NULL 
+			ORDER BY id 
+			LIMIT 20 OFFSET 20
+		`)).WillReturnRows(rows)
+	})
+	repository := repository.NewProductRepository(db)
+
+	products, total, err := repository.ListProducts(context.Background(), 20, 20)
+	suite.Nil(err)
+	suite.Equal(int64(25), total)
+	suite.Len(products, 2)
+	suite.Equal("car", products[0].Name)
+}
+
+func (suite *ProductRepositoryTestSuite) TestProductRepository_ListProductsCountError() {
+	db := suite.setupDbMockCustomTrx(func(sqlMock sqlmock.Sqlmock) {
+		sqlMock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "products"`)).WillReturnError(errors.New("count error"))
+	})
+	repository := repository.NewProductRepository(db)
+
+	_, _, err := repository.ListProducts(context.Background(), 0, 20)
+	suite.NotNil(err)
+}
+
+func (suite *ProductRepositoryTestSuite) TestProductRepository_ListProductsError() {
+	db := suite.setupDbMockCustomTrx(func(sqlMock sqlmock.Sqlmock) {
+		sqlMock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "products"`)).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(25))
+		sqlMock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "products"`)).WillReturnError(errors.New("select error"))
+	})
+	repository := repository.NewProductRepository(db)
+
+	_, _, err := repository.ListProducts(context.Background(), 0, 20)
+	suite.NotNil(err)
+}
+
+func (suite *ProductRepositoryTestSuite) TestProductRepository_HappyCase_CreateProduct() {
+	db := suite.setupDbMockCustomTrx(func(sqlMock sqlmock.Sqlmock) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectQuery(regexp.QuoteMeta(`
+			INSERT INTO "products" ("created_at","updated_at","deleted_at","name","price") 
+			VALUES ($1,$2,$3,$4,$5) 
+			RETURNING "id"
+		`)).WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, "car", 77.0).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+		sqlMock.ExpectCommit()
+	})
+	repository := repository.NewProductRepository(db)
+
+	product, err := repository.CreateProduct(context.Background(), model.Product{Name: "car", Price: 77})
+	suite.Nil(err)
+	suite.Equal(uint(3), product.ID)
+}
+
+func (suite *ProductRepositoryTestSuite) TestProductRepository_CreateProductError() {
+	db := suite.setupDbMockCustomTrx(func(sqlMock sqlmock.Sqlmock) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "products"`)).
+			WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, "car", 77.0).
+			WillReturnError(errors.New("insert error"))
+		sqlMock.ExpectRollback()
+	})
+	repository := repository.NewProductRepository(db)
+
+	_, err := repository.CreateProduct(context.Background(), model.Product{Name: "car", Price: 77})
+	suite.NotNil(err)
+}
+
+func (suite *ProductRepositoryTestSuite) TestProductRepository_HappyCase_UpdateProduct() {
+	db := suite.setupDbMockCustomTrx(func(sqlMock sqlmock.Sqlmock) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectExec(regexp.QuoteMeta(`
+			UPDATE "products" 
+			SET "name"=$1,"price"=$2,"updated_at"=$3 
+			WHERE id = $4 
+			AND "products"."deleted_at" IS NULL
+		`)).WithArgs("car", 80.0, sqlmock.AnyArg(), 3).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		sqlMock.ExpectCommit()
+	})
+	repository := repository.NewProductRepository(db)
+
+	product := model.Product{Name: "car", Price: 80}
+	product.ID = 3
+
+	err := repository.UpdateProduct(context.Background(), product)
+	suite.Nil(err)
+}
+
+func (suite *ProductRepositoryTestSuite) TestProductRepository_UpdateProductNotFound() {
+	db := suite.setupDbMockCustomTrx(func(sqlMock sqlmock.Sqlmock) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectExec(regexp.QuoteMeta(`UPDATE "products"`)).
+			WithArgs("car", 80.0, sqlmock.AnyArg(), 3).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		sqlMock.ExpectCommit()
+	})
+	repository := repository.NewProductRepository(db)
+
+	product := model.Product{Name: "car", Price: 80}
+	product.ID = 3
+
+	err := repository.UpdateProduct(context.Background(), product)
+	suite.ErrorIs(err, gorm.ErrRecordNotFound)
+}
+
+func (suite *ProductRepositoryTestSuite) TestProductRepository_UpdateProductError() {
+	db := suite.setupDbMockCustomTrx(func(sqlMock sqlmock.Sqlmock) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectExec(regexp.QuoteMeta(`UPDATE "products"`)).
+			WithArgs("car", 80.0, sqlmock.AnyArg(), 3).
+			WillReturnError(errors.New("update error"))
+		sqlMock.ExpectRollback()
+	})
+	repository := repository.NewProductRepository(db)
+
+	product := model.Product{Name: "car", Price: 80}
+	product.ID = 3
+
+	err := repository.UpdateProduct(context.Background(), product)
+	suite.NotNil(err)
+}
+
+func (suite *ProductRepositoryTestSuite) TestProductRepository_HappyCase_DeleteProduct() {
+	db := suite.setupDbMockCustomTrx(func(sqlMock sqlmock.Sqlmock) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectExec(regexp.QuoteMeta(`
+			UPDATE "products" 
+			SET "deleted_at"=$1 
+			WHERE id = $2 
+			AND "products"."deleted_at" IS NULL
+		`)).WithArgs(sqlmock.AnyArg(), 3).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		sqlMock.ExpectCommit()
+	})
+	repository := repository.NewProductRepository(db)
+
+	err := repository.DeleteProduct(context.Background(), 3)
+	suite.Nil(err)
+}
+
+func (suite *ProductRepositoryTestSuite) TestProductRepository_DeleteProductNotFound() {
+	db := suite.setupDbMockCustomTrx(func(sqlMock sqlmock.Sqlmock) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectExec(regexp.QuoteMeta(`UPDATE "products"`)).
+			WithArgs(sqlmock.AnyArg(), 3).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		sqlMock.ExpectCommit()
+	})
+	repository := repository.NewProductRepository(db)
+
+	err := repository.DeleteProduct(context.Background(), 3)
+	suite.ErrorIs(err, gorm.ErrRecordNotFound)
+}
+
+func (suite *ProductRepositoryTestSuite) TestProductRepository_DeleteProductError() {
+	db := suite.setupDbMockCustomTrx(func(sqlMock sqlmock.Sqlmock) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectExec(regexp.QuoteMeta(`UPDATE "products"`)).
+			WithArgs(sqlmock.AnyArg(), 3).
+			WillReturnError(errors.New("delete error"))
+		sqlMock.ExpectRollback()
+	})
+	repository := repository.NewProductRepository(db)
+
+	err := repository.DeleteProduct(context.Background(), 3)
+	suite.NotNil(err)
+}
+
 func TestProductRepositoryTestSuite(t *testing.T) {
 	suite.Run(t, new(ProductRepositoryTestSuite))
 }
@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"context"
+	"point-service/app/internal/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type TierRuleRepository interface {
+	GetActiveTierRules(ctx context.Context, at time.Time) ([]model.TierRule, error)
+	CountTierRules(ctx context.Context) (int64, error)
+	CreateTierRule(ctx context.Context, tierRule model.TierRule) error
+}
+
+type tierRuleRepository struct {
+	db *gorm.DB
+}
+
+func NewTierRuleRepository(db *gorm.DB) TierRuleRepository {
+	return &tierRuleRepository{
+		db: db,
+	}
+}
+
+func (repository *tierRuleRepository) GetActiveTierRules(ctx context.Context, at time.Time) ([]model.TierRule, error) {
+	var tierRules []model.TierRule
+
+	err := conn(ctx, repository.db).Model(&model.TierRule{}).
+		Where("(active_from IS NULL OR active_from <= ?) AND (active_to IS NULL OR active_to > ?)", at, at).
+		Order("priority DESC, min_price").
+		Find(&tierRules).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return tierRules, nil
+}
+
+func (repository *tierRuleRepository) CountTierRules(ctx context.Context) (int64, error) {
+	var count int64
+
+	err := conn(ctx, repository.db).Model(&model.TierRule{}).Count(&count).Error
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func (repository *tierRuleRepository) CreateTierRule(ctx context.Context, tierRule model.TierRule) error {
+	return conn(ctx, repository.db).Create(&tierRule).Error
+}
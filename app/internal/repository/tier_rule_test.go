@@ -0,0 +1,122 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"point-service/app/internal/model"
+	"point-service/app/internal/repository"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+type TierRuleRepositoryTestSuite struct {
+	suite.Suite
+}
+
+func (suite *TierRuleRepositoryTestSuite) SetupTest() {}
+
+func (suite *TierRuleRepositoryTestSuite) setupDbMockCustomTrx(process func(sqlmock.Sqlmock)) *gorm.DB {
+	// new mock instance
+	mockDb, sqlMock, err := sqlmock.New()
+	if err != nil {
+		panic(err)
+	}
+
+	// new postgres dialector for gorm
+	dialector := postgres.New(postgres.Config{
+		Conn:       mockDb,
+		DriverName: "postgres",
+	})
+
+	process(sqlMock)
+
+	// initialize gorm database
+	db, err := gorm.Open(dialector, &gorm.Config{})
+	if err != nil {
+		panic(err)
+	}
+
+	return db
+}
+
+func (suite *TierRuleRepositoryTestSuite) TestTierRuleRepository_HappyCase_GetActive() {
+	now := time.Now()
+	db := suite.setupDbMockCustomTrx(func(sqlMock sqlmock.Sqlmock) {
+		rows := sqlmock.NewRows([]string{"id", "level", "min_price", "min_inclusive", "max_price", "max_inclusive", "priority"}).
+			AddRow(1, "bronze", 0, false, 100, true, 0).
+			AddRow(2, "gold", 100, false, nil, false, 0)
+		sqlMock.ExpectQuery(regexp.QuoteMeta(`
+			SELECT * FROM "tier_rules" 
+			WHERE ((active_from IS NULL OR active_from <= $1) AND (active_to IS NULL OR active_to > $2)) 
+			AND "tier_rules"."deleted_at" IS NULL 
+			ORDER BY priority DESC, min_price
+		`)).WithArgs(now, now).WillReturnRows(rows)
+	})
+	repository := repository.NewTierRuleRepository(db)
+
+	tierRules, err := repository.GetActiveTierRules(context.Background(), now)
+	suite.Nil(err)
+	suite.Len(tierRules, 2)
+	suite.Equal(float64(100), *tierRules[0].MaxPrice)
+	suite.Nil(tierRules[1].MaxPrice)
+}
+
+func (suite *TierRuleRepositoryTestSuite) TestTierRuleRepository_GetActiveError() {
+	db := suite.setupDbMockCustomTrx(func(sqlMock sqlmock.Sqlmock) {
+		sqlMock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "tier_rules"`)).
+			WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg()).
+			WillReturnError(errors.New("select error"))
+	})
+	repository := repository.NewTierRuleRepository(db)
+
+	_, err := repository.GetActiveTierRules(context.Background(), time.Now())
+	suite.NotNil(err)
+}
+
+func (suite *TierRuleRepositoryTestSuite) TestTierRuleRepository_HappyCase_Count() {
+	db := suite.setupDbMockCustomTrx(func(sqlMock sqlmock.Sqlmock) {
+		sqlMock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "tier_rules" WHERE "tier_rules"."deleted_at" IS NULL`)).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+	})
+	repository := repository.NewTierRuleRepository(db)
+
+	count, err := repository.CountTierRules(context.Background())
+	suite.Nil(err)
+	suite.Equal(int64(3), count)
+}
+
+func (suite *TierRuleRepositoryTestSuite) TestTierRuleRepository_CountError() {
+	db := suite.setupDbMockCustomTrx(func(sqlMock sqlmock.Sqlmock) {
+		sqlMock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "tier_rules"`)).
+			WillReturnError(errors.New("count error"))
+	})
+	repository := repository.NewTierRuleRepository(db)
+
+	_, err := repository.CountTierRules(context.Background())
+	suite.NotNil(err)
+}
+
+func (suite *TierRuleRepositoryTestSuite) TestTierRuleRepository_HappyCase_Create() {
+	maxPrice := 100.0
+	db := suite.setupDbMockCustomTrx(func(sqlMock sqlmock.Sqlmock) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "tier_rules"`)).
+			WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, "bronze", float64(0), false, maxPrice, true, 0, nil, nil).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+		sqlMock.ExpectCommit()
+	})
+	repository := repository.NewTierRuleRepository(db)
+
+	err := repository.CreateTierRule(context.Background(), model.TierRule{Level: "bronze", MaxPrice: &maxPrice, MaxInclusive: true})
+	suite.Nil(err)
+}
+
+func TestTierRuleRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(TierRuleRepositoryTestSuite))
+}
@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+type transactionKey struct{}
+
+type Transaction interface {
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+type transaction struct {
+	db *gorm.DB
+}
+
+func NewTransaction(db *gorm.DB) Transaction {
+	return &transaction{
+		db: db,
+	}
+}
+
+// WithinTransaction runs fn in a database transaction, every repository called with
+// the context passed to fn joins the same transaction.
+func (transaction *transaction) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return conn(ctx, transaction.db).Transaction(func(tx *gorm.DB) error {
+		return fn(context.WithValue(ctx, transactionKey{}, tx))
+	})
+}
+
+// conn returns the transaction carried by ctx, or db when there is none.
+func conn(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if tx, ok := ctx.Value(transactionKey{}).(*gorm.DB); ok {
+		return tx.WithContext(ctx)
+	}
+
+	return db.WithContext(ctx)
+}
+
+// isUniqueViolation reports whether err is a postgres unique violation
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
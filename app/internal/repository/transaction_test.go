@@ -0,0 +1,97 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"point-service/app/internal/model"
+	"point-service/app/internal/repository"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+type TransactionTestSuite struct {
+	suite.Suite
+}
+
+func (suite *TransactionTestSuite) SetupTest() {}
+
+func (suite *TransactionTestSuite) setupDbMockCustomTrx(process func(sqlmock.Sqlmock)) (*gorm.DB, sqlmock.Sqlmock) {
+	// new mock instance
+	mockDb, sqlMock, err := sqlmock.New()
+	if err != nil {
+		panic(err)
+	}
+
+	// new postgres dialector for gorm
+	dialector := postgres.New(postgres.Config{
+		Conn:       mockDb,
+		DriverName: "postgres",
+	})
+
+	process(sqlMock)
+
+	// initialize gorm database
+	db, err := gorm.Open(dialector, &gorm.Config{})
+	if err != nil {
+		panic(err)
+	}
+
+	return db, sqlMock
+}
+
+func (suite *TransactionTestSuite) TestTransaction_HappyCase_JoinTransaction() {
+	db, sqlMock := suite.setupDbMockCustomTrx(func(sqlMock sqlmock.Sqlmock) {
+		sqlMock.ExpectBegin()
+
+		rows := sqlmock.NewRows([]string{"id", "level", "remaining"}).AddRow(1, "gold", 1000)
+		sqlMock.ExpectExec("SAVEPOINT").WillReturnResult(sqlmock.NewResult(0, 0))
+		sqlMock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "points"`)).WithArgs("gold").WillReturnRows(rows)
+		sqlMock.ExpectExec(regexp.QuoteMeta(`UPDATE "points"`)).
+			WithArgs(999, sqlmock.AnyArg(), 1, 0).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		sqlMock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "processed_orders"`)).
+			WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, 1, 0, 1, "gold", nil, false).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+
+		sqlMock.ExpectCommit()
+	})
+	transaction := repository.NewTransaction(db)
+	pointRepository := repository.NewPointRepository(db, repository.OptimisticStrategy, time.Second, 3, nil)
+	processedOrderRepository := repository.NewProcessedOrderRepository(db)
+
+	err := transaction.WithinTransaction(context.Background(), func(ctx context.Context) error {
+		err := pointRepository.Decrease(ctx, "gold", 1)
+		if err != nil {
+			return err
+		}
+
+		return processedOrderRepository.CreateProcessedOrder(ctx, model.ProcessedOrder{OrderId: 1, ProductId: 1, PointLevel: "gold"})
+	})
+	suite.Nil(err)
+	suite.Nil(sqlMock.ExpectationsWereMet())
+}
+
+func (suite *TransactionTestSuite) TestTransaction_RollbackOnError() {
+	db, sqlMock := suite.setupDbMockCustomTrx(func(sqlMock sqlmock.Sqlmock) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectRollback()
+	})
+	transaction := repository.NewTransaction(db)
+
+	err := transaction.WithinTransaction(context.Background(), func(ctx context.Context) error {
+		return errors.New("process error")
+	})
+	suite.NotNil(err)
+	suite.Nil(sqlMock.ExpectationsWereMet())
+}
+
+func TestTransactionTestSuite(t *testing.T) {
+	suite.Run(t, new(TransactionTestSuite))
+}
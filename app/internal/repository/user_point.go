@@ -0,0 +1,199 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"point-service/app/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrNotEnoughBalance = errors.New("not enough user point balance")
+
+type UserPointRepository interface {
+	Credit(ctx context.Context, userId uint, level string, orderId uint, amount uint) error
+	Debit(ctx context.Context, userId uint, level string, orderId uint, amount uint) error
+	Reserve(ctx context.Context, userId uint, level string, orderId uint, amount uint) error
+	Release(ctx context.Context, userId uint, level string, orderId uint, amount uint) error
+	Redeem(ctx context.Context, userId uint, level string, orderId uint, amount uint) error
+	Refund(ctx context.Context, userId uint, level string, orderId uint, amount uint) error
+	Clawback(ctx context.Context, userId uint, level string, orderId uint, amount uint) (uint, error)
+	GetUserPoints(ctx context.Context, userId uint) ([]model.UserPoint, error)
+	ListLedger(ctx context.Context, userId uint, offset int, limit int) ([]model.UserPointLedger, int64, error)
+}
+
+type userPointRepository struct {
+	db *gorm.DB
+}
+
+func NewUserPointRepository(db *gorm.DB) UserPointRepository {
+	return &userPointRepository{
+		db: db,
+	}
+}
+
+// Credit adds amount to the level balance of the user, opening the account on the first credit,
+// and appends the ledger entry of the order
+func (repository *userPointRepository) Credit(ctx context.Context, userId uint, level string, orderId uint, amount uint) error {
+	return repository.addBalance(ctx, model.LedgerCredit, userId, level, orderId, amount)
+}
+
+// Debit takes amount from the level balance of the user and appends the ledger entry of the order,
+// ErrNotEnoughBalance leaves the balance as it was
+func (repository *userPointRepository) Debit(ctx context.Context, userId uint, level string, orderId uint, amount uint) error {
+	return repository.takeBalance(ctx, model.LedgerDebit, userId, level, orderId, amount)
+}
+
+// Reserve takes amount from the level balance of the user for a point reservation of the order
+func (repository *userPointRepository) Reserve(ctx context.Context, userId uint, level string, orderId uint, amount uint) error {
+	return repository.takeBalance(ctx, model.LedgerReserve, userId, level, orderId, amount)
+}
+
+// Release gives back the amount a point reservation of the order took
+func (repository *userPointRepository) Release(ctx context.Context, userId uint, level string, orderId uint, amount uint) error {
+	return repository.addBalance(ctx, model.LedgerRelease, userId, level, orderId, amount)
+}
+
+// Redeem takes amount from the level balance of the user again for an order whose reservation was
+// released before the order succeeded
+func (repository *userPointRepository) Redeem(ctx context.Context, userId uint, level string, orderId uint, amount uint) error {
+	return repository.takeBalance(ctx, model.LedgerRedeem, userId, level, orderId, amount)
+}
+
+// Refund gives back the amount a confirmed point reservation of a cancelled order spent
+func (repository *userPointRepository) Refund(ctx context.Context, userId uint, level string, orderId uint, amount uint) error {
+	return repository.addBalance(ctx, model.LedgerRefund, userId, level, orderId, amount)
+}
+
+// Clawback takes up to amount from the level balance of the user for a restored order whose points
+// the user already spent, it returns the shortfall the balance did not cover
+func (repository *userPointRepository) Clawback(ctx context.Context, userId uint, level string, orderId uint, amount uint) (uint, error) {
+	var shortfall uint
+
+	err := conn(ctx, repository.db).Transaction(func(tx *gorm.DB) error {
+		var userPoint model.UserPoint
+
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("user_id = ? AND level = ?", userId, level).
+			Take(&userPoint).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		taken := min(userPoint.Balance, amount)
+		if taken > 0 {
+			err = tx.Model(&userPoint).Update("balance", gorm.Expr("balance - ?", taken)).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		shortfall = amount - taken
+		return tx.Create(&model.UserPointLedger{
+			UserId:       userId,
+			OrderId:      orderId,
+			Level:        level,
+			Type:         model.LedgerClawback,
+			Amount:       -int64(taken),
+			BalanceAfter: userPoint.Balance - taken,
+			Shortfall:    shortfall,
+		}).Error
+	})
+
+	return shortfall, err
+}
+
+func (repository *userPointRepository) addBalance(ctx context.Context, entryType string, userId uint, level string, orderId uint, amount uint) error {
+	return conn(ctx, repository.db).Transaction(func(tx *gorm.DB) error {
+		userPoint := model.UserPoint{
+			UserId:  userId,
+			Level:   level,
+			Balance: amount,
+		}
+
+		err := tx.Clauses(
+			clause.OnConflict{
+				Columns: []clause.Column{{Name: "user_id"}, {Name: "level"}},
+				DoUpdates: clause.Assignments(map[string]interface{}{
+					"balance":    gorm.Expr("user_points.balance + ?", amount),
+					"updated_at": gorm.Expr("excluded.updated_at"),
+				}),
+			},
+			clause.Returning{Columns: []clause.Column{{Name: "id"}, {Name: "balance"}}},
+		).Create(&userPoint).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Create(&model.UserPointLedger{
+			UserId:       userId,
+			OrderId:      orderId,
+			Level:        level,
+			Type:         entryType,
+			Amount:       int64(amount),
+			BalanceAfter: userPoint.Balance,
+		}).Error
+	})
+}
+
+func (repository *userPointRepository) takeBalance(ctx context.Context, entryType string, userId uint, level string, orderId uint, amount uint) error {
+	return conn(ctx, repository.db).Transaction(func(tx *gorm.DB) error {
+		var userPoint model.UserPoint
+
+		result := tx.Model(&userPoint).
+			Clauses(clause.Returning{Columns: []clause.Column{{Name: "balance"}}}).
+			Where("user_id = ? AND level = ? AND balance >= ?", userId, level, amount).
+			Update("balance", gorm.Expr("balance - ?", amount))
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			return ErrNotEnoughBalance
+		}
+
+		return tx.Create(&model.UserPointLedger{
+			UserId:       userId,
+			OrderId:      orderId,
+			Level:        level,
+			Type:         entryType,
+			Amount:       -int64(amount),
+			BalanceAfter: userPoint.Balance,
+		}).Error
+	})
+}
+
+func (repository *userPointRepository) GetUserPoints(ctx context.Context, userId uint) ([]model.UserPoint, error) {
+	var userPoints []model.UserPoint
+
+	err := conn(ctx, repository.db).Model(&model.UserPoint{}).Where("user_id = ?", userId).Order("level").Find(&userPoints).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return userPoints, nil
+}
+
+// ListLedger returns a page of the ledger of the user, newest first, and the total count
+func (repository *userPointRepository) ListLedger(ctx context.Context, userId uint, offset int, limit int) ([]model.UserPointLedger, int64, error) {
+	var ledger []model.UserPointLedger
+	var total int64
+
+	err := conn(ctx, repository.db).Model(&model.UserPointLedger{}).Where("user_id = ?", userId).Count(&total).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	err = conn(ctx, repository.db).Model(&model.UserPointLedger{}).
+		Where("user_id = ?", userId).
+		Order("id DESC").
+		Offset(offset).
+		Limit(limit).
+		Find(&ledger).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return ledger, total, nil
+}
@@ -0,0 +1,342 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"point-service/app/internal/repository"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+type UserPointRepositoryTestSuite struct {
+	suite.Suite
+}
+
+func (suite *UserPointRepositoryTestSuite) SetupTest() {}
+
+func (suite *UserPointRepositoryTestSuite) setupDbMockCustomTrx(process func(sqlmock.Sqlmock)) *gorm.DB {
+	// new mock instance
+	mockDb, sqlMock, err := sqlmock.New()
+	if err != nil {
+		panic(err)
+	}
+
+	// new postgres dialector for gorm
+	dialector := postgres.New(postgres.Config{
+		Conn:       mockDb,
+		DriverName: "postgres",
+	})
+
+	process(sqlMock)
+
+	// initialize gorm database
+	db, err := gorm.Open(dialector, &gorm.Config{})
+	if err != nil {
+		panic(err)
+	}
+
+	return db
+}
+
+func (suite *UserPointRepositoryTestSuite) TestUserPointRepository_HappyCase_Credit() {
+	db := suite.setupDbMockCustomTrx(func(sqlMock sqlmock.Sqlmock) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectQuery(regexp.QuoteMeta(`
+			INSERT INTO "user_points" ("created_at","updated_at","deleted_at","user_id","level","balance") 
+			VALUES ($1,$2,$3,$4,$5,$6) 
+			ON CONFLICT ("user_id","level") DO UPDATE SET "balance"=user_points.balance + $7,"updated_at"=excluded.updated_at 
+			RETURNING "id","balance"
+		`)).WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, 42, "gold", 2, 2).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "balance"}).AddRow(1, 7))
+		sqlMock.ExpectQuery(regexp.QuoteMeta(`
+			INSERT INTO "user_point_ledgers" ("created_at","updated_at","deleted_at","user_id","order_id","level","type","amount","balance_after","shortfall") 
+			VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10) 
+			RETURNING "id"
+		`)).WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, 42, 10, "gold", "credit", 2, 7, 0).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+		sqlMock.ExpectCommit()
+	})
+	userPointRepository := repository.NewUserPointRepository(db)
+
+	err := userPointRepository.Credit(context.Background(), 42, "gold", 10, 2)
+	suite.Nil(err)
+}
+
+func (suite *UserPointRepositoryTestSuite) TestUserPointRepository_CreditLedgerError() {
+	db := suite.setupDbMockCustomTrx(func(sqlMock sqlmock.Sqlmock) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "user_points"`)).
+			WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, 42, "gold", 2, 2).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "balance"}).AddRow(1, 7))
+		sqlMock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "user_point_ledgers"`)).
+			WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, 42, 10, "gold", "credit", 2, 7, 0).
+			WillReturnError(errors.New("duplicate key value violates unique constraint"))
+		sqlMock.ExpectRollback()
+	})
+	userPointRepository := repository.NewUserPointRepository(db)
+
+	err := userPointRepository.Credit(context.Background(), 42, "gold", 10, 2)
+	suite.NotNil(err)
+}
+
+func (suite *UserPointRepositoryTestSuite) TestUserPointRepository_CreditError() {
+	db := suite.setupDbMockCustomTrx(func(sqlMock sqlmock.Sqlmock) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "user_points"`)).
+			WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, 42, "gold", 2, 2).
+			WillReturnError(errors.New("insert error"))
+		sqlMock.ExpectRollback()
+	})
+	userPointRepository := repository.NewUserPointRepository(db)
+
+	err := userPointRepository.Credit(context.Background(), 42, "gold", 10, 2)
+	suite.NotNil(err)
+}
+
+func (suite *UserPointRepositoryTestSuite) TestUserPointRepository_HappyCase_Debit() {
+	db := suite.setupDbMockCustomTrx(func(sqlMock sqlmock.Sqlmock) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectQuery(regexp.QuoteMeta(`
+			UPDATE "user_points" 
+			SET "balance"=balance - $1,"updated_at"=$2 
+			WHERE (user_id = $3 AND level = $4 AND balance >= $5) 
+			AND "user_points"."deleted_at" IS NULL 
+			RETURNING "balance"
+		`)).WithArgs(2, sqlmock.AnyArg(), 42, "gold", 2).
+			WillReturnRows(sqlmock.NewRows([]string{"balance"}).AddRow(5))
+		sqlMock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "user_point_ledgers"`)).
+			WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, 42, 10, "gold", "debit", -2, 5, 0).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2))
+		sqlMock.ExpectCommit()
+	})
+	userPointRepository := repository.NewUserPointRepository(db)
+
+	err := userPointRepository.Debit(context.Background(), 42, "gold", 10, 2)
+	suite.Nil(err)
+}
+
+func (suite *UserPointRepositoryTestSuite) TestUserPointRepository_DebitNotEnoughBalance() {
+	db := suite.setupDbMockCustomTrx(func(sqlMock sqlmock.Sqlmock) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectQuery(regexp.QuoteMeta(`UPDATE "user_points"`)).
+			WithArgs(2, sqlmock.AnyArg(), 42, "gold", 2).
+			WillReturnRows(sqlmock.NewRows([]string{"balance"}))
+		sqlMock.ExpectRollback()
+	})
+	userPointRepository := repository.NewUserPointRepository(db)
+
+	err := userPointRepository.Debit(context.Background(), 42, "gold", 10, 2)
+	suite.ErrorIs(err, repository.ErrNotEnoughBalance)
+}
+
+func (suite *UserPointRepositoryTestSuite) TestUserPointRepository_DebitError() {
+	db := suite.setupDbMockCustomTrx(func(sqlMock sqlmock.Sqlmock) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectQuery(regexp.QuoteMeta(`UPDATE "user_points"`)).
+			WithArgs(2, sqlmock.AnyArg(), 42, "gold", 2).
+			WillReturnError(errors.New("update error"))
+		sqlMock.ExpectRollback()
+	})
+	userPointRepository := repository.NewUserPointRepository(db)
+
+	err := userPointRepository.Debit(context.Background(), 42, "gold", 10, 2)
+	suite.NotNil(err)
+}
+
+func (suite *UserPointRepositoryTestSuite) TestUserPointRepository_HappyCase_Reserve() {
+	db := suite.setupDbMockCustomTrx(func(sqlMock sqlmock.Sqlmock) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectQuery(regexp.QuoteMeta(`UPDATE "user_points"`)).
+			WithArgs(5, sqlmock.AnyArg(), 42, "gold", 5).
+			WillReturnRows(sqlmock.NewRows([]string{"balance"}).AddRow(2))
+		sqlMock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "user_point_ledgers"`)).
+			WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, 42, 11, "gold", "reserve", -5, 2, 0).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+		sqlMock.ExpectCommit()
+	})
+	userPointRepository := repository.NewUserPointRepository(db)
+
+	err := userPointRepository.Reserve(context.Background(), 42, "gold", 11, 5)
+	suite.Nil(err)
+}
+
+func (suite *UserPointRepositoryTestSuite) TestUserPointRepository_HappyCase_Redeem() {
+	db := suite.setupDbMockCustomTrx(func(sqlMock sqlmock.Sqlmock) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectQuery(regexp.QuoteMeta(`UPDATE "user_points"`)).
+			WithArgs(5, sqlmock.AnyArg(), 42, "gold", 5).
+			WillReturnRows(sqlmock.NewRows([]string{"balance"}).AddRow(2))
+		sqlMock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "user_point_ledgers"`)).
+			WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, 42, 11, "gold", "redeem", -5, 2, 0).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+		sqlMock.ExpectCommit()
+	})
+	userPointRepository := repository.NewUserPointRepository(db)
+
+	err := userPointRepository.Redeem(context.Background(), 42, "gold", 11, 5)
+	suite.Nil(err)
+}
+
+func (suite *UserPointRepositoryTestSuite) TestUserPointRepository_HappyCase_Release() {
+	db := suite.setupDbMockCustomTrx(func(sqlMock sqlmock.Sqlmock) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "user_points"`)).
+			WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, 42, "gold", 5, 5).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "balance"}).AddRow(1, 7))
+		sqlMock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "user_point_ledgers"`)).
+			WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, 42, 11, "gold", "release", 5, 7, 0).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(4))
+		sqlMock.ExpectCommit()
+	})
+	userPointRepository := repository.NewUserPointRepository(db)
+
+	err := userPointRepository.Release(context.Background(), 42, "gold", 11, 5)
+	suite.Nil(err)
+}
+
+func (suite *UserPointRepositoryTestSuite) TestUserPointRepository_HappyCase_Refund() {
+	db := suite.setupDbMockCustomTrx(func(sqlMock sqlmock.Sqlmock) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "user_points"`)).
+			WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, 42, "gold", 5, 5).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "balance"}).AddRow(1, 7))
+		sqlMock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "user_point_ledgers"`)).
+			WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, 42, 11, "gold", "refund", 5, 7, 0).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(4))
+		sqlMock.ExpectCommit()
+	})
+	userPointRepository := repository.NewUserPointRepository(db)
+
+	err := userPointRepository.Refund(context.Background(), 42, "gold", 11, 5)
+	suite.Nil(err)
+}
+
+func (suite *UserPointRepositoryTestSuite) TestUserPointRepository_HappyCase_Clawback() {
+	db := suite.setupDbMockCustomTrx(func(sqlMock sqlmock.Sqlmock) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "user_points" WHERE (user_id = $1 AND level = $2) AND "user_points"."deleted_at" IS NULL LIMIT 1 FOR UPDATE`)).
+			WithArgs(42, "gold").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "level", "balance"}).AddRow(1, 42, "gold", 2))
+		sqlMock.ExpectExec(regexp.QuoteMeta(`UPDATE "user_points" SET "balance"=balance - $1,"updated_at"=$2 WHERE "user_points"."deleted_at" IS NULL AND "id" = $3`)).
+			WithArgs(2, sqlmock.AnyArg(), 1).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		sqlMock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "user_point_ledgers"`)).
+			WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, 42, 11, "gold", "clawback", -2, 0, 3).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(5))
+		sqlMock.ExpectCommit()
+	})
+	userPointRepository := repository.NewUserPointRepository(db)
+
+	shortfall, err := userPointRepository.Clawback(context.Background(), 42, "gold", 11, 5)
+	suite.Nil(err)
+	suite.Equal(uint(3), shortfall)
+}
+
+func (suite *UserPointRepositoryTestSuite) TestUserPointRepository_HappyCase_ClawbackNoBalance() {
+	db := suite.setupDbMockCustomTrx(func(sqlMock sqlmock.Sqlmock) {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "user_points"`)).
+			WithArgs(42, "gold").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "level", "balance"}))
+		sqlMock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "user_point_ledgers"`)).
+			WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, 42, 11, "gold", "clawback", 0, 0, 5).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(5))
+		sqlMock.ExpectCommit()
+	})
+	userPointRepository := repository.NewUserPointRepository(db)
+
+	shortfall, err := userPointRepository.Clawback(context.Background(), 42, "gold", 11, 5)
+	suite.Nil(err)
+	suite.Equal(uint(5), shortfall)
+}
+
+func (suite *UserPointRepositoryTestSuite) TestUserPointRepository_HappyCase_GetUserPoints() {
+	db := suite.setupDbMockCustomTrx(func(sqlMock sqlmock.Sqlmock) {
+		rows := sqlmock.NewRows([]string{"id", "user_id", "level", "balance"}).
+			AddRow(2, 42, "bronze", 3).
+			AddRow(1, 42, "gold", 7)
+		sqlMock.ExpectQuery(regexp.QuoteMeta(`
+			SELECT * FROM "user_points" 
+			WHERE user_id = $1 
+			AND "user_points"."deleted_at" IS NULL 
+			ORDER BY level
+		`)).WithArgs(42).WillReturnRows(rows)
+	})
+	userPointRepository := repository.NewUserPointRepository(db)
+
+	userPoints, err := userPointRepository.GetUserPoints(context.Background(), 42)
+	suite.Nil(err)
+	suite.Len(userPoints, 2)
+	suite.Equal(uint(7), userPoints[1].Balance)
+}
+
+func (suite *UserPointRepositoryTestSuite) TestUserPointRepository_GetUserPointsError() {
+	db := suite.setupDbMockCustomTrx(func(sqlMock sqlmock.Sqlmock) {
+		sqlMock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "user_points"`)).
+			WithArgs(42).WillReturnError(errors.New("select error"))
+	})
+	userPointRepository := repository.NewUserPointRepository(db)
+
+	_, err := userPointRepository.GetUserPoints(context.Background(), 42)
+	suite.NotNil(err)
+}
+
+func (suite *UserPointRepositoryTestSuite) TestUserPointRepository_HappyCase_ListLedger() {
+	db := suite.setupDbMockCustomTrx(func(sqlMock sqlmock.Sqlmock) {
+		sqlMock.ExpectQuery(regexp.QuoteMeta(`
+			SELECT count(*) FROM "user_point_ledgers" 
+			WHERE user_id = $1 
+			AND "user_point_ledgers"."deleted_at" IS NULL
+		`)).WithArgs(42).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+
+		rows := sqlmock.NewRows([]string{"id", "user_id", "order_id", "level", "type", "amount", "balance_after"}).
+			AddRow(2, 42, 10, "gold", "debit", -2, 5).
+			AddRow(1, 42, 10, "gold", "credit", 2, 7)
+		sqlMock.ExpectQuery(regexp.QuoteMeta(`
+			SELECT * FROM "user_point_ledgers" 
+			WHERE user_id = $1 
+			AND "user_point_ledgers"."deleted_at" IS NULL 
+			ORDER BY id DESC 
+			LIMIT 20
+		`)).WithArgs(42).WillReturnRows(rows)
+	})
+	userPointRepository := repository.NewUserPointRepository(db)
+
+	ledger, total, err := userPointRepository.ListLedger(context.Background(), 42, 0, 20)
+	suite.Nil(err)
+	suite.Equal(int64(2), total)
+	suite.Equal(int64(-2), ledger[0].Amount)
+}
+
+func (suite *UserPointRepositoryTestSuite) TestUserPointRepository_ListLedgerCountError() {
+	db := suite.setupDbMockCustomTrx(func(sqlMock sqlmock.Sqlmock) {
+		sqlMock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "user_point_ledgers"`)).
+			WithArgs(42).WillReturnError(errors.New("count error"))
+	})
+	userPointRepository := repository.NewUserPointRepository(db)
+
+	_, _, err := userPointRepository.ListLedger(context.Background(), 42, 0, 20)
+	suite.NotNil(err)
+}
+
+func (suite *UserPointRepositoryTestSuite) TestUserPointRepository_ListLedgerError() {
+	db := suite.setupDbMockCustomTrx(func(sqlMock sqlmock.Sqlmock) {
+		sqlMock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "user_point_ledgers"`)).
+			WithArgs(42).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+		sqlMock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "user_point_ledgers"`)).
+			WithArgs(42).WillReturnError(errors.New("select error"))
+	})
+	userPointRepository := repository.NewUserPointRepository(db)
+
+	_, _, err := userPointRepository.ListLedger(context.Background(), 42, 0, 20)
+	suite.NotNil(err)
+}
+
+func TestUserPointRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(UserPointRepositoryTestSuite))
+}
@@ -0,0 +1,13 @@
+package service
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// OrdersCounter is the counter of orders with event, for the tests of service_test
+func (metrics *PointMetrics) OrdersCounter(event string) prometheus.Counter {
+	return metrics.orders.WithLabelValues(event)
+}
+
+// PointsCounter is the counter of the points of level moved by operation, for the tests of service_test
+func (metrics *PointMetrics) PointsCounter(level string, operation string) prometheus.Counter {
+	return metrics.points.WithLabelValues(level, operation)
+}
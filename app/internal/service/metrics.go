@@ -0,0 +1,90 @@
+package service
+
+import (
+	"context"
+	"log"
+	"point-service/app/internal/model"
+	"point-service/app/internal/repository"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// events of an order counted by PointMetrics
+const (
+	OrderDecreased = "decreased"
+	OrderFailed    = "failed"
+	OrderDuplicate = "duplicate"
+	OrderRestored  = "restored"
+	OrderCancelled = "cancelled"
+)
+
+// operations on the points of a level
+const (
+	PointDecrease = "decrease"
+	PointIncrease = "increase"
+)
+
+// PointMetrics counts the orders of the point service and the points they move by level, only once
+// their transaction is committed
+type PointMetrics struct {
+	orders *prometheus.CounterVec
+	points *prometheus.CounterVec
+}
+
+// NewPointMetrics registers the point metrics with registerer, the remaining points of every level are
+// read from pointRepository on each scrape
+func NewPointMetrics(registerer prometheus.Registerer, pointRepository repository.PointRepository) *PointMetrics {
+	metrics := &PointMetrics{
+		orders: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "point_orders_total",
+			Help: "Orders by event: decreased, failed, duplicate, restored or cancelled.",
+		}, []string{"event"}),
+		points: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "point_tier_points_total",
+			Help: "Points taken from or given back to each level by operation.",
+		}, []string{"level", "operation"}),
+	}
+	registerer.MustRegister(metrics.orders, metrics.points, &remainingCollector{pointRepository: pointRepository})
+
+	return metrics
+}
+
+// order counts an order event, a service built without metrics counts nothing
+func (metrics *PointMetrics) order(event string, operation string, pointUsages []model.PointUsage) {
+	if metrics == nil {
+		return
+	}
+
+	metrics.orders.WithLabelValues(event).Inc()
+	for _, pointUsage := range pointUsages {
+		metrics.points.WithLabelValues(pointUsage.Level, operation).Add(float64(pointUsage.Amount))
+	}
+}
+
+var remainingDesc = prometheus.NewDesc("point_remaining", "Remaining points by level.", []string{"level"}, nil)
+
+// remainingCollector reads the remaining points of every level when scraped, nothing is collected
+// when they cannot be listed
+type remainingCollector struct {
+	pointRepository repository.PointRepository
+}
+
+func (collector *remainingCollector) Describe(descs chan<- *prometheus.Desc) {
+	descs <- remainingDesc
+}
+
+func (collector *remainingCollector) Collect(metrics chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+
+	points, err := collector.pointRepository.List(ctx)
+	if err != nil {
+		log.Printf("list points for metrics error: %s", err.Error())
+		return
+	}
+
+	for _, point := range points {
+		metrics <- prometheus.MustNewConstMetric(remainingDesc, prometheus.GaugeValue, float64(point.Remaining), point.Level)
+	}
+}
@@ -0,0 +1,59 @@
+package service_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"point-service/app/internal/model"
+	mockRepository "point-service/app/internal/repository/mocks"
+	"point-service/app/internal/service"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type PointMetricsTestSuite struct {
+	suite.Suite
+}
+
+func (suite *PointMetricsTestSuite) scrape(registry *prometheus.Registry) string {
+	recorder := httptest.NewRecorder()
+	promhttp.HandlerFor(registry, promhttp.HandlerOpts{}).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	return recorder.Body.String()
+}
+
+func (suite *PointMetricsTestSuite) TestPointMetrics_HappyCase_Remaining() {
+	pointRepository := new(mockRepository.PointRepository)
+	pointRepository.On("List", mock.Anything).Return([]model.Point{{Level: "bronze", Remaining: 10}, {Level: "gold", Remaining: 1}}, nil)
+
+	registry := prometheus.NewRegistry()
+	metrics := service.NewPointMetrics(registry, pointRepository)
+	suite.Equal(0.0, testutil.ToFloat64(metrics.OrdersCounter(service.OrderDecreased)))
+
+	scraped := suite.scrape(registry)
+	suite.Contains(scraped, "# TYPE point_remaining gauge\npoint_remaining{level=\"bronze\"} 10\npoint_remaining{level=\"gold\"} 1\n")
+	suite.Contains(scraped, "# TYPE point_orders_total counter\npoint_orders_total{event=\"decreased\"} 0\n")
+}
+
+func (suite *PointMetricsTestSuite) TestPointMetrics_ListError() {
+	pointRepository := new(mockRepository.PointRepository)
+	pointRepository.On("List", mock.Anything).Return(nil, errors.New("list point error"))
+
+	registry := prometheus.NewRegistry()
+	metrics := service.NewPointMetrics(registry, pointRepository)
+	suite.Equal(0.0, testutil.ToFloat64(metrics.OrdersCounter(service.OrderDecreased)))
+
+	// a failed read leaves the gauge without samples instead of failing the scrape
+	scraped := suite.scrape(registry)
+	suite.NotContains(scraped, "point_remaining")
+	suite.Contains(scraped, "point_orders_total{event=\"decreased\"} 0\n")
+}
+
+func TestPointMetricsTestSuite(t *testing.T) {
+	suite.Run(t, new(PointMetricsTestSuite))
+}
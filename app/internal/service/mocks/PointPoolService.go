@@ -0,0 +1,189 @@
+// Code generated by mockery v2.39.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	model "point-service/app/internal/model"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// PointPoolService is an autogenerated mock type for the PointPoolService type
+type PointPoolService struct {
+	mock.Mock
+}
+
+// CreatePointPool provides a mock function with given fields: ctx, level, remaining
+func (_m *PointPoolService) CreatePointPool(ctx context.Context, level string, remaining uint) (model.Point, error) {
+	ret := _m.Called(ctx, level, remaining)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreatePointPool")
+	}
+
+	var r0 model.Point
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, uint) (model.Point, error)); ok {
+		return rf(ctx, level, remaining)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, uint) model.Point); ok {
+		r0 = rf(ctx, level, remaining)
+	} else {
+		r0 = ret.Get(0).(model.Point)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, uint) error); ok {
+		r1 = rf(ctx, level, remaining)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeletePointPool provides a mock function with given fields: ctx, level
+func (_m *PointPoolService) DeletePointPool(ctx context.Context, level string) error {
+	ret := _m.Called(ctx, level)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeletePointPool")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, level)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetPointPool provides a mock function with given fields: ctx, level
+func (_m *PointPoolService) GetPointPool(ctx context.Context, level string) (model.Point, error) {
+	ret := _m.Called(ctx, level)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetPointPool")
+	}
+
+	var r0 model.Point
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (model.Point, error)); ok {
+		return rf(ctx, level)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) model.Point); ok {
+		r0 = rf(ctx, level)
+	} else {
+		r0 = ret.Get(0).(model.Point)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, level)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListPointPools provides a mock function with given fields: ctx
+func (_m *PointPoolService) ListPointPools(ctx context.Context) ([]model.Point, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListPointPools")
+	}
+
+	var r0 []model.Point
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]model.Point, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []model.Point); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.Point)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SetPointPoolRemaining provides a mock function with given fields: ctx, level, remaining
+func (_m *PointPoolService) SetPointPoolRemaining(ctx context.Context, level string, remaining uint) (model.Point, error) {
+	ret := _m.Called(ctx, level, remaining)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetPointPoolRemaining")
+	}
+
+	var r0 model.Point
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, uint) (model.Point, error)); ok {
+		return rf(ctx, level, remaining)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, uint) model.Point); ok {
+		r0 = rf(ctx, level, remaining)
+	} else {
+		r0 = ret.Get(0).(model.Point)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, uint) error); ok {
+		r1 = rf(ctx, level, remaining)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// TopUpPointPool provides a mock function with given fields: ctx, level, amount
+func (_m *PointPoolService) TopUpPointPool(ctx context.Context, level string, amount uint) (model.Point, error) {
+	ret := _m.Called(ctx, level, amount)
+
+	if len(ret) == 0 {
+		panic("no return value specified for TopUpPointPool")
+	}
+
+	var r0 model.Point
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, uint) (model.Point, error)); ok {
+		return rf(ctx, level, amount)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, uint) model.Point); ok {
+		r0 = rf(ctx, level, amount)
+	} else {
+		r0 = ret.Get(0).(model.Point)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, uint) error); ok {
+		r1 = rf(ctx, level, amount)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewPointPoolService creates a new instance of PointPoolService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewPointPoolService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *PointPoolService {
+	mock := &PointPoolService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -32,6 +32,24 @@ func (_m *PointService) DecreasePoint(ctx context.Context, successOrder model.Su
 	return r0
 }
 
+// RestorePoint provides a mock function with given fields: ctx, orderId
+func (_m *PointService) RestorePoint(ctx context.Context, orderId uint) error {
+	ret := _m.Called(ctx, orderId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RestorePoint")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint) error); ok {
+		r0 = rf(ctx, orderId)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // NewPointService creates a new instance of PointService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewPointService(t interface {
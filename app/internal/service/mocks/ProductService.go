@@ -0,0 +1,160 @@
+// Code generated by mockery v2.39.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	model "point-service/app/internal/model"
+	service "point-service/app/internal/service"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// ProductService is an autogenerated mock type for the ProductService type
+type ProductService struct {
+	mock.Mock
+}
+
+// CreateProduct provides a mock function with given fields: ctx, name, price
+func (_m *ProductService) CreateProduct(ctx context.Context, name string, price float64) (model.Product, error) {
+	ret := _m.Called(ctx, name, price)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateProduct")
+	}
+
+	var r0 model.Product
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, float64) (model.Product, error)); ok {
+		return rf(ctx, name, price)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, float64) model.Product); ok {
+		r0 = rf(ctx, name, price)
+	} else {
+		r0 = ret.Get(0).(model.Product)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, float64) error); ok {
+		r1 = rf(ctx, name, price)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeleteProduct provides a mock function with given fields: ctx, productId
+func (_m *ProductService) DeleteProduct(ctx context.Context, productId uint) error {
+	ret := _m.Called(ctx, productId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteProduct")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint) error); ok {
+		r0 = rf(ctx, productId)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetProduct provides a mock function with given fields: ctx, productId
+func (_m *ProductService) GetProduct(ctx context.Context, productId uint) (model.Product, error) {
+	ret := _m.Called(ctx, productId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetProduct")
+	}
+
+	var r0 model.Product
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint) (model.Product, error)); ok {
+		return rf(ctx, productId)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint) model.Product); ok {
+		r0 = rf(ctx, productId)
+	} else {
+		r0 = ret.Get(0).(model.Product)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint) error); ok {
+		r1 = rf(ctx, productId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListProducts provides a mock function with given fields: ctx, page, pageSize
+func (_m *ProductService) ListProducts(ctx context.Context, page int, pageSize int) (service.ProductPage, error) {
+	ret := _m.Called(ctx, page, pageSize)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListProducts")
+	}
+
+	var r0 service.ProductPage
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) (service.ProductPage, error)); ok {
+		return rf(ctx, page, pageSize)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) service.ProductPage); ok {
+		r0 = rf(ctx, page, pageSize)
+	} else {
+		r0 = ret.Get(0).(service.ProductPage)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, int) error); ok {
+		r1 = rf(ctx, page, pageSize)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdateProduct provides a mock function with given fields: ctx, productId, name, price
+func (_m *ProductService) UpdateProduct(ctx context.Context, productId uint, name string, price float64) (model.Product, error) {
+	ret := _m.Called(ctx, productId, name, price)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateProduct")
+	}
+
+	var r0 model.Product
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, string, float64) (model.Product, error)); ok {
+		return rf(ctx, productId, name, price)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint, string, float64) model.Product); ok {
+		r0 = rf(ctx, productId, name, price)
+	} else {
+		r0 = ret.Get(0).(model.Product)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint, string, float64) error); ok {
+		r1 = rf(ctx, productId, name, price)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewProductService creates a new instance of ProductService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewProductService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *ProductService {
+	mock := &ProductService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
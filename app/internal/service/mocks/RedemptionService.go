@@ -0,0 +1,182 @@
+// Code generated by mockery v2.39.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	model "point-service/app/internal/model"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// RedemptionService is an autogenerated mock type for the RedemptionService type
+type RedemptionService struct {
+	mock.Mock
+}
+
+// Cancel provides a mock function with given fields: ctx, orderId
+func (_m *RedemptionService) Cancel(ctx context.Context, orderId uint) error {
+	ret := _m.Called(ctx, orderId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Cancel")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint) error); ok {
+		r0 = rf(ctx, orderId)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Confirm provides a mock function with given fields: ctx, userId, orderId
+func (_m *RedemptionService) Confirm(ctx context.Context, userId uint, orderId uint) (model.PointReservation, error) {
+	ret := _m.Called(ctx, userId, orderId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Confirm")
+	}
+
+	var r0 model.PointReservation
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, uint) (model.PointReservation, error)); ok {
+		return rf(ctx, userId, orderId)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint, uint) model.PointReservation); ok {
+		r0 = rf(ctx, userId, orderId)
+	} else {
+		r0 = ret.Get(0).(model.PointReservation)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint, uint) error); ok {
+		r1 = rf(ctx, userId, orderId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetReservation provides a mock function with given fields: ctx, userId, orderId
+func (_m *RedemptionService) GetReservation(ctx context.Context, userId uint, orderId uint) (model.PointReservation, error) {
+	ret := _m.Called(ctx, userId, orderId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetReservation")
+	}
+
+	var r0 model.PointReservation
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, uint) (model.PointReservation, error)); ok {
+		return rf(ctx, userId, orderId)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint, uint) model.PointReservation); ok {
+		r0 = rf(ctx, userId, orderId)
+	} else {
+		r0 = ret.Get(0).(model.PointReservation)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint, uint) error); ok {
+		r1 = rf(ctx, userId, orderId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Release provides a mock function with given fields: ctx, userId, orderId
+func (_m *RedemptionService) Release(ctx context.Context, userId uint, orderId uint) (model.PointReservation, error) {
+	ret := _m.Called(ctx, userId, orderId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Release")
+	}
+
+	var r0 model.PointReservation
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, uint) (model.PointReservation, error)); ok {
+		return rf(ctx, userId, orderId)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint, uint) model.PointReservation); ok {
+		r0 = rf(ctx, userId, orderId)
+	} else {
+		r0 = ret.Get(0).(model.PointReservation)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint, uint) error); ok {
+		r1 = rf(ctx, userId, orderId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ReleaseExpired provides a mock function with given fields: ctx
+func (_m *RedemptionService) ReleaseExpired(ctx context.Context) error {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ReleaseExpired")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context) error); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Reserve provides a mock function with given fields: ctx, userId, orderId, level, amount
+func (_m *RedemptionService) Reserve(ctx context.Context, userId uint, orderId uint, level string, amount uint) (model.PointReservation, error) {
+	ret := _m.Called(ctx, userId, orderId, level, amount)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Reserve")
+	}
+
+	var r0 model.PointReservation
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, uint, string, uint) (model.PointReservation, error)); ok {
+		return rf(ctx, userId, orderId, level, amount)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint, uint, string, uint) model.PointReservation); ok {
+		r0 = rf(ctx, userId, orderId, level, amount)
+	} else {
+		r0 = ret.Get(0).(model.PointReservation)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint, uint, string, uint) error); ok {
+		r1 = rf(ctx, userId, orderId, level, amount)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Run provides a mock function with given fields: ctx
+func (_m *RedemptionService) Run(ctx context.Context) {
+	_m.Called(ctx)
+}
+
+// NewRedemptionService creates a new instance of RedemptionService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewRedemptionService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *RedemptionService {
+	mock := &RedemptionService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
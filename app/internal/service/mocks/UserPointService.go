@@ -0,0 +1,85 @@
+// Code generated by mockery v2.39.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	service "point-service/app/internal/service"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// UserPointService is an autogenerated mock type for the UserPointService type
+type UserPointService struct {
+	mock.Mock
+}
+
+// GetUserPointBalance provides a mock function with given fields: ctx, userId
+func (_m *UserPointService) GetUserPointBalance(ctx context.Context, userId uint) (service.UserPointBalance, error) {
+	ret := _m.Called(ctx, userId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetUserPointBalance")
+	}
+
+	var r0 service.UserPointBalance
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint) (service.UserPointBalance, error)); ok {
+		return rf(ctx, userId)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint) service.UserPointBalance); ok {
+		r0 = rf(ctx, userId)
+	} else {
+		r0 = ret.Get(0).(service.UserPointBalance)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint) error); ok {
+		r1 = rf(ctx, userId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListUserPointLedger provides a mock function with given fields: ctx, userId, page, pageSize
+func (_m *UserPointService) ListUserPointLedger(ctx context.Context, userId uint, page int, pageSize int) (service.UserPointLedgerPage, error) {
+	ret := _m.Called(ctx, userId, page, pageSize)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListUserPointLedger")
+	}
+
+	var r0 service.UserPointLedgerPage
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, int, int) (service.UserPointLedgerPage, error)); ok {
+		return rf(ctx, userId, page, pageSize)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint, int, int) service.UserPointLedgerPage); ok {
+		r0 = rf(ctx, userId, page, pageSize)
+	} else {
+		r0 = ret.Get(0).(service.UserPointLedgerPage)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint, int, int) error); ok {
+		r1 = rf(ctx, userId, page, pageSize)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewUserPointService creates a new instance of UserPointService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewUserPointService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *UserPointService {
+	mock := &UserPointService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,164 @@
+package service
+
+import (
+	"context"
+	"log"
+	"point-service/app/internal/repository"
+	"point-service/app/pkg/kafka"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+type OutboxRelay interface {
+	Run(ctx context.Context)
+	RelayPending(ctx context.Context) error
+	CleanupDelivered(ctx context.Context) error
+}
+
+type outboxRelay struct {
+	transaction      repository.Transaction
+	outboxRepository repository.OutboxRepository
+	producer         kafka.Producer
+	batchSize        int
+	pollInterval     time.Duration
+	cleanupInterval  time.Duration
+	retention        time.Duration
+}
+
+func NewOutboxRelay(
+	transaction repository.Transaction,
+	outboxRepository repository.OutboxRepository,
+	producer kafka.Producer,
+	batchSize int,
+	pollInterval time.Duration,
+	cleanupInterval time.Duration,
+	retention time.Duration,
+) OutboxRelay {
+	return &outboxRelay{
+		transaction:      transaction,
+		outboxRepository: outboxRepository,
+		producer:         producer,
+		batchSize:        batchSize,
+		pollInterval:     pollInterval,
+		cleanupInterval:  cleanupInterval,
+		retention:        retention,
+	}
+}
+
+// Run relays pending outboxes and cleans up delivered ones until ctx is done
+func (relay *outboxRelay) Run(ctx context.Context) {
+	pollTicker := time.NewTicker(relay.pollInterval)
+	defer pollTicker.Stop()
+
+	cleanupTicker := time.NewTicker(relay.cleanupInterval)
+	defer cleanupTicker.Stop()
+
+	for {
+		select {
+		case <-pollTicker.C:
+			err := relay.RelayPending(ctx)
+			if err != nil {
+				log.Printf("relay pending outbox error: %s", err.Error())
+			}
+
+		case <-cleanupTicker.C:
+			err := relay.CleanupDelivered(ctx)
+			if err != nil {
+				log.Printf("cleanup delivered outbox error: %s", err.Error())
+			}
+
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// RelayPending publishes one batch of pending outboxes in insertion order. A row that fails to publish
+// holds back the later rows of the same order until the next poll, other orders carry on. An async
+// producer only reports a failure at flush, so a row is marked delivered once it is flushed and the
+// relay flushes before it queues a second row of an order, a later row of an order is never written
+// before an earlier one. A failed flush ends the batch, the rows flushed before it stay delivered and
+// the others are published again on the next poll.
+func (relay *outboxRelay) RelayPending(ctx context.Context) error {
+	var flushErr error
+
+	err := relay.transaction.WithinTransaction(ctx, func(ctx context.Context) error {
+		outboxes, err := relay.outboxRepository.GetPendingOutboxes(ctx, relay.batchSize)
+		if err != nil {
+			return errors.Wrap(err, "get pending outboxes error")
+		}
+
+		blocked := map[string]bool{}
+		queued := map[string]bool{}
+		var unflushed []uint
+
+		// flush returns false when the producer failed, the queued rows are then left pending
+		flush := func() (bool, error) {
+			flushErr = relay.producer.Flush()
+			if flushErr != nil {
+				return false, nil
+			}
+
+			for _, id := range unflushed {
+				// a crash before commit publishes the row again, consumers see it at least once
+				err := relay.outboxRepository.MarkOutboxDelivered(ctx, id, time.Now())
+				if err != nil {
+					return false, errors.Wrap(err, "mark outbox delivered error")
+				}
+			}
+			unflushed = nil
+			clear(queued)
+
+			return true, nil
+		}
+
+		for _, outbox := range outboxes {
+			if blocked[outbox.AggregateId] {
+				continue
+			}
+
+			if queued[outbox.AggregateId] {
+				flushed, err := flush()
+				if !flushed {
+					return err
+				}
+			}
+
+			err := relay.producer.SendMessage(outbox.Topic, outbox.AggregateId, string(outbox.Payload), outbox.Headers)
+			if err != nil {
+				log.Printf("send outbox %d error: %s", outbox.ID, err.Error())
+				blocked[outbox.AggregateId] = true
+				continue
+			}
+
+			queued[outbox.AggregateId] = true
+			unflushed = append(unflushed, outbox.ID)
+		}
+
+		_, err = flush()
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	if flushErr != nil {
+		return errors.Wrap(flushErr, "flush outbox messages error")
+	}
+
+	return nil
+}
+
+func (relay *outboxRelay) CleanupDelivered(ctx context.Context) error {
+	deleted, err := relay.outboxRepository.DeleteDeliveredOutboxes(ctx, time.Now().Add(-relay.retention))
+	if err != nil {
+		return errors.Wrap(err, "delete delivered outboxes error")
+	}
+
+	if deleted > 0 {
+		log.Printf("deleted %d delivered outboxes", deleted)
+	}
+
+	return nil
+}
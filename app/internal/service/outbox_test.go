@@ -0,0 +1,187 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"point-service/app/internal/model"
+	mockRepository "point-service/app/internal/repository/mocks"
+	"point-service/app/internal/service"
+	mockKafka "point-service/app/pkg/kafka/mocks"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+)
+
+type OutboxRelayTestSuite struct {
+	suite.Suite
+
+	transaction      *mockRepository.Transaction
+	outboxRepository *mockRepository.OutboxRepository
+	producer         *mockKafka.Producer
+}
+
+func (suite *OutboxRelayTestSuite) SetupTest() {
+	transaction := new(mockRepository.Transaction)
+	transaction.On("WithinTransaction", mock.Anything, mock.Anything).Return(func(ctx context.Context, fn func(context.Context) error) error {
+		return fn(ctx)
+	})
+
+	suite.transaction = transaction
+	suite.outboxRepository = new(mockRepository.OutboxRepository)
+	suite.producer = new(mockKafka.Producer)
+	suite.producer.On("Flush").Return(nil).Maybe()
+}
+
+func (suite *OutboxRelayTestSuite) newOutboxRelay() service.OutboxRelay {
+	return service.NewOutboxRelay(suite.transaction, suite.outboxRepository, suite.producer, 100, time.Second, time.Hour, time.Hour*24)
+}
+
+func (suite *OutboxRelayTestSuite) TestOutboxRelay_HappyCase_RelayPending() {
+	outboxes := []model.Outbox{
+		{Model: gorm.Model{ID: 1}, AggregateId: "1", Topic: "decrease.point.success", Payload: []byte(`{"order_id":1}`)},
+		{Model: gorm.Model{ID: 2}, AggregateId: "2", Topic: "decrease.point.success", Payload: []byte(`{"order_id":2}`)},
+	}
+	suite.outboxRepository.On("GetPendingOutboxes", mock.Anything, 100).Return(outboxes, nil)
+	suite.outboxRepository.On("MarkOutboxDelivered", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	suite.producer.On("SendMessage", "decrease.point.success", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	err := suite.newOutboxRelay().RelayPending(context.Background())
+	suite.Nil(err)
+	suite.producer.AssertCalled(suite.T(), "SendMessage", "decrease.point.success", "1", `{"order_id":1}`, mock.Anything)
+	suite.producer.AssertCalled(suite.T(), "SendMessage", "decrease.point.success", "2", `{"order_id":2}`, mock.Anything)
+	suite.producer.AssertCalled(suite.T(), "Flush")
+	suite.outboxRepository.AssertCalled(suite.T(), "MarkOutboxDelivered", mock.Anything, uint(1), mock.Anything)
+	suite.outboxRepository.AssertCalled(suite.T(), "MarkOutboxDelivered", mock.Anything, uint(2), mock.Anything)
+}
+
+func (suite *OutboxRelayTestSuite) TestOutboxRelay_SendError_HoldBackSameOrder() {
+	outboxes := []model.Outbox{
+		{Model: gorm.Model{ID: 1}, AggregateId: "1", Topic: "decrease.point.success", Payload: []byte(`{"order_id":1}`)},
+		{Model: gorm.Model{ID: 2}, AggregateId: "2", Topic: "decrease.point.success", Payload: []byte(`{"order_id":2}`)},
+		{Model: gorm.Model{ID: 3}, AggregateId: "1", Topic: "decrease.point.failed", Payload: []byte(`{"order_id":1}`)},
+	}
+	suite.outboxRepository.On("GetPendingOutboxes", mock.Anything, 100).Return(outboxes, nil)
+	suite.outboxRepository.On("MarkOutboxDelivered", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	suite.producer.On("SendMessage", "decrease.point.success", "1", `{"order_id":1}`, mock.Anything).Return(errors.New("send message error"))
+	suite.producer.On("SendMessage", "decrease.point.success", "2", `{"order_id":2}`, mock.Anything).Return(nil)
+
+	err := suite.newOutboxRelay().RelayPending(context.Background())
+	suite.Nil(err)
+	suite.producer.AssertNotCalled(suite.T(), "SendMessage", "decrease.point.failed", mock.Anything, mock.Anything, mock.Anything)
+	suite.outboxRepository.AssertNotCalled(suite.T(), "MarkOutboxDelivered", mock.Anything, uint(1), mock.Anything)
+	suite.outboxRepository.AssertCalled(suite.T(), "MarkOutboxDelivered", mock.Anything, uint(2), mock.Anything)
+}
+
+func (suite *OutboxRelayTestSuite) TestOutboxRelay_GetPendingError() {
+	suite.outboxRepository.On("GetPendingOutboxes", mock.Anything, 100).Return(nil, errors.New("select error"))
+
+	err := suite.newOutboxRelay().RelayPending(context.Background())
+	suite.NotNil(err)
+}
+
+func (suite *OutboxRelayTestSuite) TestOutboxRelay_MarkDeliveredError() {
+	outboxes := []model.Outbox{
+		{Model: gorm.Model{ID: 1}, AggregateId: "1", Topic: "decrease.point.success", Payload: []byte(`{"order_id":1}`)},
+	}
+	suite.outboxRepository.On("GetPendingOutboxes", mock.Anything, 100).Return(outboxes, nil)
+	suite.outboxRepository.On("MarkOutboxDelivered", mock.Anything, uint(1), mock.Anything).Return(errors.New("update error"))
+	suite.producer.On("SendMessage", "decrease.point.success", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	err := suite.newOutboxRelay().RelayPending(context.Background())
+	suite.NotNil(err)
+}
+
+func (suite *OutboxRelayTestSuite) TestOutboxRelay_FlushError() {
+	outboxes := []model.Outbox{
+		{Model: gorm.Model{ID: 1}, AggregateId: "1", Topic: "decrease.point.success", Payload: []byte(`{"order_id":1}`)},
+	}
+	suite.producer = new(mockKafka.Producer)
+	suite.producer.On("SendMessage", "decrease.point.success", "1", `{"order_id":1}`, mock.Anything).Return(nil)
+	suite.producer.On("Flush").Return(errors.New("broker not available"))
+	suite.outboxRepository.On("GetPendingOutboxes", mock.Anything, 100).Return(outboxes, nil)
+	suite.outboxRepository.On("MarkOutboxDelivered", mock.Anything, uint(1), mock.Anything).Return(nil)
+
+	err := suite.newOutboxRelay().RelayPending(context.Background())
+	suite.ErrorContains(err, "flush outbox messages error")
+}
+
+func (suite *OutboxRelayTestSuite) TestOutboxRelay_HappyCase_FlushBeforeSameOrder() {
+	outboxes := []model.Outbox{
+		{Model: gorm.Model{ID: 1}, AggregateId: "1", Topic: "decrease.point.success", Payload: []byte(`{"order_id":1}`)},
+		{Model: gorm.Model{ID: 2}, AggregateId: "2", Topic: "decrease.point.success", Payload: []byte(`{"order_id":2}`)},
+		{Model: gorm.Model{ID: 3}, AggregateId: "1", Topic: "increase.point.success", Payload: []byte(`{"order_id":1}`)},
+	}
+	suite.outboxRepository.On("GetPendingOutboxes", mock.Anything, 100).Return(outboxes, nil)
+	suite.outboxRepository.On("MarkOutboxDelivered", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	suite.producer.On("SendMessage", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	err := suite.newOutboxRelay().RelayPending(context.Background())
+	suite.Nil(err)
+
+	var methods []string
+	for _, call := range suite.producer.Calls {
+		methods = append(methods, call.Method)
+	}
+	suite.Equal([]string{"SendMessage", "SendMessage", "Flush", "SendMessage", "Flush"}, methods)
+	suite.outboxRepository.AssertNumberOfCalls(suite.T(), "MarkOutboxDelivered", 3)
+}
+
+func (suite *OutboxRelayTestSuite) TestOutboxRelay_FlushErrorKeepsFlushedRows() {
+	outboxes := []model.Outbox{
+		{Model: gorm.Model{ID: 1}, AggregateId: "1", Topic: "decrease.point.success", Payload: []byte(`{"order_id":1}`)},
+		{Model: gorm.Model{ID: 2}, AggregateId: "2", Topic: "decrease.point.success", Payload: []byte(`{"order_id":2}`)},
+		{Model: gorm.Model{ID: 3}, AggregateId: "2", Topic: "increase.point.success", Payload: []byte(`{"order_id":2}`)},
+		{Model: gorm.Model{ID: 4}, AggregateId: "1", Topic: "increase.point.success", Payload: []byte(`{"order_id":1}`)},
+	}
+	suite.producer = new(mockKafka.Producer)
+	suite.producer.On("SendMessage", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	suite.producer.On("Flush").Return(nil).Once()
+	suite.producer.On("Flush").Return(errors.New("broker not available"))
+	suite.outboxRepository.On("GetPendingOutboxes", mock.Anything, 100).Return(outboxes, nil)
+	suite.outboxRepository.On("MarkOutboxDelivered", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	err := suite.newOutboxRelay().RelayPending(context.Background())
+	suite.ErrorContains(err, "flush outbox messages error")
+	suite.outboxRepository.AssertCalled(suite.T(), "MarkOutboxDelivered", mock.Anything, uint(1), mock.Anything)
+	suite.outboxRepository.AssertCalled(suite.T(), "MarkOutboxDelivered", mock.Anything, uint(2), mock.Anything)
+	suite.outboxRepository.AssertNotCalled(suite.T(), "MarkOutboxDelivered", mock.Anything, uint(3), mock.Anything)
+	suite.outboxRepository.AssertNotCalled(suite.T(), "MarkOutboxDelivered", mock.Anything, uint(4), mock.Anything)
+}
+
+func (suite *OutboxRelayTestSuite) TestOutboxRelay_FlushErrorEndsBatch() {
+	outboxes := []model.Outbox{
+		{Model: gorm.Model{ID: 1}, AggregateId: "1", Topic: "decrease.point.success", Payload: []byte(`{"order_id":1}`)},
+		{Model: gorm.Model{ID: 2}, AggregateId: "1", Topic: "increase.point.success", Payload: []byte(`{"order_id":1}`)},
+		{Model: gorm.Model{ID: 3}, AggregateId: "2", Topic: "decrease.point.success", Payload: []byte(`{"order_id":2}`)},
+	}
+	suite.producer = new(mockKafka.Producer)
+	suite.producer.On("SendMessage", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	suite.producer.On("Flush").Return(errors.New("broker not available"))
+	suite.outboxRepository.On("GetPendingOutboxes", mock.Anything, 100).Return(outboxes, nil)
+
+	err := suite.newOutboxRelay().RelayPending(context.Background())
+	suite.ErrorContains(err, "flush outbox messages error")
+	suite.producer.AssertNumberOfCalls(suite.T(), "SendMessage", 1)
+	suite.outboxRepository.AssertNotCalled(suite.T(), "MarkOutboxDelivered", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *OutboxRelayTestSuite) TestOutboxRelay_HappyCase_CleanupDelivered() {
+	suite.outboxRepository.On("DeleteDeliveredOutboxes", mock.Anything, mock.Anything).Return(int64(3), nil)
+
+	err := suite.newOutboxRelay().CleanupDelivered(context.Background())
+	suite.Nil(err)
+}
+
+func (suite *OutboxRelayTestSuite) TestOutboxRelay_CleanupDeliveredError() {
+	suite.outboxRepository.On("DeleteDeliveredOutboxes", mock.Anything, mock.Anything).Return(int64(0), errors.New("delete error"))
+
+	err := suite.newOutboxRelay().CleanupDelivered(context.Background())
+	suite.NotNil(err)
+}
+
+func TestOutboxRelayTestSuite(t *testing.T) {
+	suite.Run(t, new(OutboxRelayTestSuite))
+}
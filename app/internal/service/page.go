@@ -0,0 +1,34 @@
+package service
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+var ErrInvalidPage = errors.New("invalid page")
+
+// normalizePage applies the defaults to a page request, page 0 is the first page and page size 0
+// is DefaultPageSize, and returns the offset of the page
+func normalizePage(page int, pageSize int) (int, int, int, error) {
+	if page == 0 {
+		page = 1
+	}
+	if pageSize == 0 {
+		pageSize = DefaultPageSize
+	}
+
+	if page < 1 {
+		return 0, 0, 0, fmt.Errorf("%w: page must be greater than 0", ErrInvalidPage)
+	}
+	if pageSize < 1 || pageSize > MaxPageSize {
+		return 0, 0, 0, fmt.Errorf("%w: page size must be between 1 and %d", ErrInvalidPage, MaxPageSize)
+	}
+
+	return page, pageSize, (page - 1) * pageSize, nil
+}
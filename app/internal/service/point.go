@@ -2,89 +2,485 @@ package service
 
 import (
 	"context"
-	"encoding/json"
-	"point-service/app/internal/constant"
+	"database/sql/driver"
+	"fmt"
+	"log"
+	"net"
 	"point-service/app/internal/model"
 	"point-service/app/internal/repository"
 	"point-service/app/pkg/kafka"
+	"sort"
+	"strconv"
+	"time"
 
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/pkg/errors"
+	"gorm.io/gorm"
 )
 
+var (
+	ErrUnexpectedPriceCategory = errors.New("unexpected price category")
+	ErrProductNotFound         = errors.New("product not found")
+)
+
+// ErrOrderCancelled is a success.order of an order cancelled or refunded before it was processed,
+// it takes no points and emits nothing
+var ErrOrderCancelled = errors.New("order was cancelled before it was processed")
+
+// TierPolicy is how the point level of an order is chosen
+type TierPolicy string
+
+const (
+	// OrderTotalPolicy takes 1 point of the level of the order total
+	OrderTotalPolicy TierPolicy = "order_total"
+	// PerLinePolicy takes quantity points of the level of the unit price of every line
+	PerLinePolicy TierPolicy = "per_line"
+)
+
+func (policy TierPolicy) Valid() bool {
+	return policy == OrderTotalPolicy || policy == PerLinePolicy
+}
+
+// ParseTierPolicy returns the policy named name
+func ParseTierPolicy(name string) (TierPolicy, error) {
+	policy := TierPolicy(name)
+	if !policy.Valid() {
+		return "", errors.Errorf("must be %s or %s", OrderTotalPolicy, PerLinePolicy)
+	}
+
+	return policy, nil
+}
+
 type PointService interface {
 	DecreasePoint(ctx context.Context, successOrder model.SuccessOrder) error
+	RestorePoint(ctx context.Context, orderId uint) error
 }
 
 type pointService struct {
+	transaction               repository.Transaction
 	pointRepository           repository.PointRepository
 	productRepository         repository.ProductRepository
-	producer                  kafka.Producer
+	tierRuleRepository        repository.TierRuleRepository
+	processedOrderRepository  repository.ProcessedOrderRepository
+	outboxRepository          repository.OutboxRepository
+	userPointRepository       repository.UserPointRepository
+	tierPolicy                TierPolicy
 	decreasePointSuccessTopic string
+	decreasePointFailedTopic  string
+	increasePointSuccessTopic string
+	encoder                   kafka.Encoder
+	cloudEvents               kafka.CloudEvents
+	metrics                   *PointMetrics
+	validTierRules            *validTierRules
 }
 
-func NewPointService(pointRepository repository.PointRepository, productRepository repository.ProductRepository, producer kafka.Producer, decreasePointSuccessTopic string) PointService {
+func NewPointService(
+	transaction repository.Transaction,
+	pointRepository repository.PointRepository,
+	productRepository repository.ProductRepository,
+	tierRuleRepository repository.TierRuleRepository,
+	processedOrderRepository repository.ProcessedOrderRepository,
+	outboxRepository repository.OutboxRepository,
+	userPointRepository repository.UserPointRepository,
+	tierPolicy TierPolicy,
+	decreasePointSuccessTopic string,
+	decreasePointFailedTopic string,
+	increasePointSuccessTopic string,
+	encoder kafka.Encoder,
+	cloudEvents kafka.CloudEvents,
+	metrics *PointMetrics,
+) PointService {
 	return &pointService{
+		transaction:               transaction,
 		pointRepository:           pointRepository,
 		productRepository:         productRepository,
-		producer:                  producer,
+		tierRuleRepository:        tierRuleRepository,
+		processedOrderRepository:  processedOrderRepository,
+		outboxRepository:          outboxRepository,
+		userPointRepository:       userPointRepository,
+		tierPolicy:                tierPolicy,
 		decreasePointSuccessTopic: decreasePointSuccessTopic,
+		decreasePointFailedTopic:  decreasePointFailedTopic,
+		increasePointSuccessTopic: increasePointSuccessTopic,
+		encoder:                   encoder,
+		cloudEvents:               cloudEvents,
+		metrics:                   metrics,
+		validTierRules:            &validTierRules{},
 	}
 }
 
 func (service *pointService) DecreasePoint(ctx context.Context, successOrder model.SuccessOrder) error {
-	decreasePointSuccess := model.DecreasePointSuccess{
-		OrderId: successOrder.OrderId,
+	items, err := successOrder.LineItems()
+	if err != nil {
+		return errors.Wrap(err, "invalid success order")
+	}
+
+	duplicate := false
+	var pointUsages []model.PointUsage
+	decrease := func(ctx context.Context) error {
+		// an order that was already processed only re-emits its original result
+		processedOrder, err := service.processedOrderRepository.GetProcessedOrderByOrderId(ctx, successOrder.OrderId)
+		if err == nil && processedOrder.Cancelled {
+			return ErrOrderCancelled
+		}
+		if err == nil {
+			duplicate = true
+			decreasePointSuccess := model.NewDecreasePointSuccess(successOrder.OrderId, processedOrder.UserId, processedOrder.PointUsages())
+			return service.createOutbox(ctx, service.decreasePointSuccessTopic, successOrder.OrderId, decreasePointSuccess)
+		}
+
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.Wrap(err, "get processed order by order id error")
+		}
+
+		pointUsages, err = service.decreasePointByItems(ctx, items)
+		if err != nil {
+			return err
+		}
+
+		// the user earns what the pools gave
+		if successOrder.UserId != 0 {
+			for _, pointUsage := range pointUsages {
+				err = service.userPointRepository.Credit(ctx, successOrder.UserId, pointUsage.Level, successOrder.OrderId, pointUsage.Amount)
+				if err != nil {
+					return errors.Wrapf(err, "credit user %s point error", pointUsage.Level)
+				}
+			}
+		}
+
+		// record the order in the same transaction as the decrement
+		processedOrder = model.ProcessedOrder{OrderId: successOrder.OrderId, UserId: successOrder.UserId}
+		for _, pointUsage := range pointUsages {
+			processedOrder.Points = append(processedOrder.Points, model.ProcessedOrderPoint{
+				Level:  pointUsage.Level,
+				Amount: pointUsage.Amount,
+			})
+		}
+
+		err = service.processedOrderRepository.CreateProcessedOrder(ctx, processedOrder)
+		if err != nil {
+			return errors.Wrap(err, "create processed order error")
+		}
+
+		// decrease point result for increase user point, relayed to kafka once committed
+		decreasePointSuccess := model.NewDecreasePointSuccess(successOrder.OrderId, successOrder.UserId, pointUsages)
+		return service.createOutbox(ctx, service.decreasePointSuccessTopic, successOrder.OrderId, decreasePointSuccess)
+	}
+
+	err = service.transaction.WithinTransaction(ctx, decrease)
+	// a concurrent delivery of the order committed first, the rolled back decrease runs again as a duplicate
+	if errors.Is(err, repository.ErrProcessedOrderExists) {
+		err = service.transaction.WithinTransaction(ctx, decrease)
+	}
+
+	if errors.Is(err, ErrOrderCancelled) {
+		log.Printf("order %d was cancelled before it was processed, its success order is skipped", successOrder.OrderId)
+		service.metrics.order(OrderCancelled, PointDecrease, nil)
+		return err
+	}
+
+	if isBusinessError(err) {
+		err = service.sendDecreasePointFailed(ctx, successOrder, err)
+		if err != nil {
+			return err
+		}
+
+		service.metrics.order(OrderFailed, PointDecrease, nil)
+		return nil
 	}
 
-	// find price of product
-	product, err := service.productRepository.GetProductById(ctx, successOrder.ProductId)
 	if err != nil {
-		return errors.Wrap(err, "get product by id error")
+		return err
 	}
 
-	// decrease point by success order transaction
-	switch {
-	case product.Price >= 1001:
-		decreasePointSuccess.PointLevel = constant.GOLD
+	if duplicate {
+		service.metrics.order(OrderDuplicate, PointDecrease, nil)
+	} else {
+		service.metrics.order(OrderDecreased, PointDecrease, pointUsages)
+	}
+
+	return nil
+}
+
+// RestorePoint gives the points a processed order took back to their pools and takes them back
+// from the user it credited. An order that was not processed yet is recorded as cancelled, its
+// success.order may still be in a retry topic and is skipped when it comes. An order that was
+// already restored only re-emits its result.
+func (service *pointService) RestorePoint(ctx context.Context, orderId uint) error {
+	var restoredUsages []model.PointUsage
+	restore := func(ctx context.Context) error {
+		processedOrder, err := service.processedOrderRepository.GetProcessedOrderByOrderId(ctx, orderId)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			restoredAt := time.Now()
+			err = service.processedOrderRepository.CreateProcessedOrder(ctx, model.ProcessedOrder{OrderId: orderId, RestoredAt: &restoredAt, Cancelled: true})
+			if err != nil {
+				return errors.Wrap(err, "create cancelled order error")
+			}
+
+			return nil
+		}
+
+		if err != nil {
+			return errors.Wrap(err, "get processed order by order id error")
+		}
+
+		// a cancelled order took nothing
+		if processedOrder.Cancelled {
+			return nil
+		}
+
+		pointUsages := processedOrder.PointUsages()
+		increasePointSuccess := model.NewIncreasePointSuccess(orderId, processedOrder.UserId, pointUsages)
+
+		err = service.processedOrderRepository.MarkProcessedOrderRestored(ctx, processedOrder.ID, time.Now())
+		if errors.Is(err, repository.ErrProcessedOrderRestored) {
+			return service.createOutbox(ctx, service.increasePointSuccessTopic, orderId, increasePointSuccess)
+		}
 
-		err := service.pointRepository.DecreaseGoldPoint(ctx)
 		if err != nil {
-			return errors.Wrap(err, "decrease gold point error")
+			return errors.Wrap(err, "mark processed order restored error")
+		}
+
+		// same level order as the decrease, see decreasePointByItems
+		sortedUsages := make([]model.PointUsage, len(pointUsages))
+		copy(sortedUsages, pointUsages)
+		sort.Slice(sortedUsages, func(i, j int) bool { return sortedUsages[i].Level < sortedUsages[j].Level })
+
+		for _, pointUsage := range sortedUsages {
+			err = service.pointRepository.Increase(ctx, pointUsage.Level, pointUsage.Amount)
+			if err != nil {
+				return errors.Wrapf(err, "increase %s point error", pointUsage.Level)
+			}
+
+			if processedOrder.UserId != 0 {
+				err = service.debitUser(ctx, processedOrder.UserId, orderId, pointUsage)
+				if err != nil {
+					return err
+				}
+			}
 		}
 
-	case product.Price >= 101 && product.Price <= 1000:
-		decreasePointSuccess.PointLevel = constant.SILVER
+		restoredUsages = sortedUsages
+		return service.createOutbox(ctx, service.increasePointSuccessTopic, orderId, increasePointSuccess)
+	}
+
+	err := service.transaction.WithinTransaction(ctx, restore)
+	// the success.order of the order committed first, the rolled back restore runs again on it
+	if errors.Is(err, repository.ErrProcessedOrderExists) {
+		err = service.transaction.WithinTransaction(ctx, restore)
+	}
+	if err != nil {
+		return err
+	}
+
+	// nothing was given back to an order not processed yet or already restored
+	if restoredUsages != nil {
+		service.metrics.order(OrderRestored, PointIncrease, restoredUsages)
+	}
+
+	return nil
+}
+
+// debitUser takes the points of a restored order back from the user, points the user already spent
+// are clawed back as far as the balance goes and the rest is recorded as a shortfall in the ledger,
+// the pools get their points back either way
+func (service *pointService) debitUser(ctx context.Context, userId uint, orderId uint, pointUsage model.PointUsage) error {
+	err := service.userPointRepository.Debit(ctx, userId, pointUsage.Level, orderId, pointUsage.Amount)
+	if !errors.Is(err, repository.ErrNotEnoughBalance) {
+		return errors.Wrapf(err, "debit user %s point error", pointUsage.Level)
+	}
+
+	shortfall, err := service.userPointRepository.Clawback(ctx, userId, pointUsage.Level, orderId, pointUsage.Amount)
+	if err != nil {
+		return errors.Wrapf(err, "claw back user %s point error", pointUsage.Level)
+	}
+
+	log.Printf("user %d already spent %d of the %d %s points of restored order %d, recorded as shortfall",
+		userId, shortfall, pointUsage.Amount, pointUsage.Level, orderId)
+	return nil
+}
+
+// decreasePointByItems decreases every level the items take points from, the caller
+// transaction makes the decrements all or nothing
+func (service *pointService) decreasePointByItems(ctx context.Context, items []model.OrderItem) ([]model.PointUsage, error) {
+	// find point level of the prices from the active tier rules
+	tierRules, err := service.tierRuleRepository.GetActiveTierRules(ctx, time.Now())
+	if err != nil {
+		return nil, errors.Wrap(err, "get active tier rules error")
+	}
+
+	err = service.validTierRules.validate(tierRules)
+	if err != nil {
+		log.Printf("fix the tier rules, orders are retried until then: %s", err.Error())
+		return nil, err
+	}
+
+	amounts := map[string]uint{}
+	total := 0.0
 
-		err := service.pointRepository.DecreaseSilverPoint(ctx)
+	for _, item := range items {
+		price, err := service.itemPrice(ctx, item)
 		if err != nil {
-			return errors.Wrap(err, "decrease silver point error")
+			return nil, err
 		}
 
-	case product.Price <= 100 && product.Price > 0:
-		decreasePointSuccess.PointLevel = constant.BRONZE
+		if service.tierPolicy == PerLinePolicy {
+			// a free line earns no points
+			if price == 0 {
+				continue
+			}
+
+			tierRule, ok := selectTierRule(tierRules, price)
+			if !ok {
+				return nil, ErrUnexpectedPriceCategory
+			}
+
+			amounts[tierRule.Level] += item.Quantity
+			continue
+		}
+
+		total += price * float64(item.Quantity)
+	}
+
+	// an order that costs nothing earns no points
+	if service.tierPolicy != PerLinePolicy && total != 0 {
+		tierRule, ok := selectTierRule(tierRules, total)
+		if !ok {
+			return nil, ErrUnexpectedPriceCategory
+		}
+
+		amounts[tierRule.Level] = 1
+	}
+
+	// decrease the levels in the same order for every order, so concurrent orders
+	// locking several point rows cannot deadlock each other
+	levels := make([]string, 0, len(amounts))
+	for level := range amounts {
+		levels = append(levels, level)
+	}
+	sort.Strings(levels)
+
+	pointUsages := []model.PointUsage{}
+	for _, level := range levels {
+		err = service.pointRepository.Decrease(ctx, level, amounts[level])
+		// the level of an active rule cannot be deleted, a missing one is a configuration error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			err = fmt.Errorf("%w: level %s does not exist", ErrInvalidTierRules, level)
+			log.Printf("fix the tier rules, orders are retried until then: %s", err.Error())
+			return nil, err
+		}
 
-		err := service.pointRepository.DecreaseBronzePoint(ctx)
 		if err != nil {
-			return errors.Wrap(err, "decrease bronze point error")
+			return nil, errors.Wrapf(err, "decrease %s point error", level)
 		}
 
-	default:
-		return errors.New("unexpected price category")
+		pointUsages = append(pointUsages, model.PointUsage{Level: level, Amount: amounts[level]})
+	}
+
+	return pointUsages, nil
+}
+
+// itemPrice is the unit price of the item, the catalog price unless the order overrides it
+func (service *pointService) itemPrice(ctx context.Context, item model.OrderItem) (float64, error) {
+	// find price of product, a deleted product is not found and earns no points
+	product, err := service.productRepository.GetProductById(ctx, item.ProductId)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, fmt.Errorf("%w: product %d", ErrProductNotFound, item.ProductId)
+	}
+
+	if err != nil {
+		return 0, errors.Wrap(err, "get product by id error")
+	}
+
+	if item.UnitPrice != nil {
+		return *item.UnitPrice, nil
+	}
+
+	return product.Price, nil
+}
+
+// sendDecreasePointFailed notifies the order service that the order did not earn points
+func (service *pointService) sendDecreasePointFailed(ctx context.Context, successOrder model.SuccessOrder, reason error) error {
+	decreasePointFailed := model.DecreasePointFailed{
+		OrderId: successOrder.OrderId,
+		Reason:  reason.Error(),
+	}
+
+	return service.createOutbox(ctx, service.decreasePointFailedTopic, successOrder.OrderId, decreasePointFailed)
+}
+
+// createOutbox writes the event to the outbox, or sends it with the kafka transaction of the consumed
+// message when the consumer is transactional, a rolled back decrease also aborts that transaction
+func (service *pointService) createOutbox(ctx context.Context, topic string, orderId uint, event interface{}) error {
+	payload, err := service.encoder(topic, event)
+	if err != nil {
+		return errors.Wrap(err, "encode event error")
+	}
+
+	aggregateId := strconv.FormatUint(uint64(orderId), 10)
+	headers := kafka.PropagationHeaders(ctx)
+	payload, err = service.cloudEvents.Wrap(topic, aggregateId, payload, headers)
+	if err != nil {
+		return errors.Wrap(err, "wrap cloud event error")
 	}
 
-	// produce decrease point result for increase user point
-	decreasePointSuccessJson, _ := json.Marshal(decreasePointSuccess)
+	outbox := model.Outbox{
+		AggregateId: aggregateId,
+		Topic:       topic,
+		Payload:     payload,
+		Headers:     headers,
+	}
 
-	decreasePointSuccessHeader := map[string]string{}
+	if producer, ok := kafka.TransactionFromContext(ctx); ok {
+		err := producer.SendMessage(outbox.Topic, outbox.AggregateId, string(outbox.Payload), outbox.Headers)
+		if err != nil {
+			return errors.Wrap(err, "send event in transaction error")
+		}
 
-	err = service.producer.SendMessage(
-		service.decreasePointSuccessTopic,
-		string(decreasePointSuccessJson),
-		decreasePointSuccessHeader,
-	)
+		return nil
+	}
+
+	err = service.outboxRepository.CreateOutbox(ctx, outbox)
 	if err != nil {
-		return errors.Wrap(err, "produce message decrease point result error")
+		return errors.Wrap(err, "create outbox error")
 	}
 
 	return nil
 }
+
+// isBusinessError reports whether err is final for the order, retrying it would give the same result
+func isBusinessError(err error) bool {
+	return errors.Is(err, repository.ErrNotEnoughPoints) ||
+		errors.Is(err, ErrUnexpectedPriceCategory) ||
+		errors.Is(err, ErrProductNotFound)
+}
+
+// postgres error codes worth retrying: serialization failure, deadlock, lock timeout and statement timeout
+var transientPgErrorCodes = map[string]bool{
+	"40001": true,
+	"40P01": true,
+	"55P03": true,
+	"57014": true,
+}
+
+// IsTransientError reports whether a DecreasePoint error may succeed when the order is processed again
+func IsTransientError(err error) bool {
+	var netErr net.Error
+	var pgErr *pgconn.PgError
+
+	switch {
+	case errors.Is(err, repository.ErrMaxAttemptsReached),
+		errors.Is(err, ErrInvalidTierRules),
+		errors.Is(err, context.DeadlineExceeded),
+		errors.Is(err, driver.ErrBadConn),
+		kafka.IsRetryable(err),
+		pgconn.Timeout(err):
+		return true
+	case errors.As(err, &pgErr):
+		return transientPgErrorCodes[pgErr.Code]
+	case errors.As(err, &netErr):
+		return netErr.Timeout()
+	default:
+		return false
+	}
+}
@@ -0,0 +1,174 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"point-service/app/internal/model"
+	"point-service/app/internal/repository"
+	"regexp"
+	"time"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidPointPool = errors.New("invalid point pool")
+	ErrPointPoolExists  = errors.New("point pool already exists")
+	ErrPointPoolInUse   = errors.New("point pool is used by active tier rules")
+)
+
+// a level is part of the admin api path and of the tier rules, keep it a plain slug
+var pointLevelPattern = regexp.MustCompile(`^[a-z0-9_-]{1,32}$`)
+
+// PointPoolService manages the point pool of every level, one model.Point per level
+type PointPoolService interface {
+	ListPointPools(ctx context.Context) ([]model.Point, error)
+	GetPointPool(ctx context.Context, level string) (model.Point, error)
+	CreatePointPool(ctx context.Context, level string, remaining uint) (model.Point, error)
+	TopUpPointPool(ctx context.Context, level string, amount uint) (model.Point, error)
+	SetPointPoolRemaining(ctx context.Context, level string, remaining uint) (model.Point, error)
+	DeletePointPool(ctx context.Context, level string) error
+}
+
+type pointPoolService struct {
+	transaction        repository.Transaction
+	pointRepository    repository.PointRepository
+	tierRuleRepository repository.TierRuleRepository
+}
+
+func NewPointPoolService(
+	transaction repository.Transaction,
+	pointRepository repository.PointRepository,
+	tierRuleRepository repository.TierRuleRepository,
+) PointPoolService {
+	return &pointPoolService{
+		transaction:        transaction,
+		pointRepository:    pointRepository,
+		tierRuleRepository: tierRuleRepository,
+	}
+}
+
+func (service *pointPoolService) ListPointPools(ctx context.Context) ([]model.Point, error) {
+	points, err := service.pointRepository.List(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "list points error")
+	}
+
+	return points, nil
+}
+
+func (service *pointPoolService) GetPointPool(ctx context.Context, level string) (model.Point, error) {
+	point, err := service.pointRepository.Get(ctx, level)
+	if err != nil {
+		return point, errors.Wrapf(err, "get %s point error", level)
+	}
+
+	return point, nil
+}
+
+func (service *pointPoolService) CreatePointPool(ctx context.Context, level string, remaining uint) (model.Point, error) {
+	var point model.Point
+
+	if !pointLevelPattern.MatchString(level) {
+		return point, fmt.Errorf("%w: level must be 1 to 32 lowercase letters, digits, - or _", ErrInvalidPointPool)
+	}
+
+	err := service.transaction.WithinTransaction(ctx, func(ctx context.Context) error {
+		_, err := service.pointRepository.Get(ctx, level)
+		if err == nil {
+			return ErrPointPoolExists
+		}
+
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.Wrapf(err, "get %s point error", level)
+		}
+
+		// a concurrent create of the level is caught by the unique index
+		point, err = service.pointRepository.Create(ctx, model.Point{
+			Level:     level,
+			Remaining: remaining,
+		})
+		if errors.Is(err, repository.ErrPointExists) {
+			return ErrPointPoolExists
+		}
+
+		if err != nil {
+			return errors.Wrapf(err, "create %s point error", level)
+		}
+
+		return nil
+	})
+
+	return point, err
+}
+
+func (service *pointPoolService) TopUpPointPool(ctx context.Context, level string, amount uint) (model.Point, error) {
+	if amount == 0 {
+		return model.Point{}, fmt.Errorf("%w: amount must be greater than 0", ErrInvalidPointPool)
+	}
+
+	return service.updatePointPool(ctx, level, func(ctx context.Context) error {
+		err := service.pointRepository.Increase(ctx, level, amount)
+		if err != nil {
+			return errors.Wrapf(err, "increase %s point error", level)
+		}
+
+		return nil
+	})
+}
+
+func (service *pointPoolService) SetPointPoolRemaining(ctx context.Context, level string, remaining uint) (model.Point, error) {
+	return service.updatePointPool(ctx, level, func(ctx context.Context) error {
+		err := service.pointRepository.SetRemaining(ctx, level, remaining)
+		if err != nil {
+			return errors.Wrapf(err, "set %s point remaining error", level)
+		}
+
+		return nil
+	})
+}
+
+// DeletePointPool soft deletes a level, a level an active tier rule maps prices to is kept,
+// otherwise the orders of those prices would fail until the rules change
+func (service *pointPoolService) DeletePointPool(ctx context.Context, level string) error {
+	tierRules, err := service.tierRuleRepository.GetActiveTierRules(ctx, time.Now())
+	if err != nil {
+		return errors.Wrap(err, "get active tier rules error")
+	}
+
+	for _, tierRule := range tierRules {
+		if tierRule.Level == level {
+			return fmt.Errorf("%w: tier rule %d maps prices to %s", ErrPointPoolInUse, tierRule.ID, level)
+		}
+	}
+
+	err = service.pointRepository.Delete(ctx, level)
+	if err != nil {
+		return errors.Wrapf(err, "delete %s point error", level)
+	}
+
+	return nil
+}
+
+// updatePointPool runs update and reads the point back in one transaction,
+// the caller gets the remaining its own change produced
+func (service *pointPoolService) updatePointPool(ctx context.Context, level string, update func(ctx context.Context) error) (model.Point, error) {
+	var point model.Point
+
+	err := service.transaction.WithinTransaction(ctx, func(ctx context.Context) error {
+		err := update(ctx)
+		if err != nil {
+			return err
+		}
+
+		point, err = service.pointRepository.Get(ctx, level)
+		if err != nil {
+			return errors.Wrapf(err, "get %s point error", level)
+		}
+
+		return nil
+	})
+
+	return point, err
+}
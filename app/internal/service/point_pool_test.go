@@ -0,0 +1,168 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"point-service/app/internal/model"
+	"point-service/app/internal/repository"
+	mockRepository "point-service/app/internal/repository/mocks"
+	"point-service/app/internal/service"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+)
+
+type PointPoolServiceTestSuite struct {
+	suite.Suite
+	pointPoolService service.PointPoolService
+
+	pointRepository    *mockRepository.PointRepository
+	tierRuleRepository *mockRepository.TierRuleRepository
+}
+
+func (suite *PointPoolServiceTestSuite) SetupTest() {
+	transaction := new(mockRepository.Transaction)
+	transaction.On("WithinTransaction", mock.Anything, mock.Anything).Return(func(ctx context.Context, fn func(context.Context) error) error {
+		return fn(ctx)
+	})
+
+	pointRepository := new(mockRepository.PointRepository)
+	pointRepository.On("List", mock.Anything).Return([]model.Point{{Level: "bronze", Remaining: 10}, {Level: "gold", Remaining: 1}}, nil)
+
+	pointRepository.On("Get", mock.Anything, "gold").Return(model.Point{Level: "gold", Remaining: 15}, nil)
+	pointRepository.On("Get", mock.Anything, "broken").Return(model.Point{}, errors.New("get point error"))
+	pointRepository.On("Get", mock.Anything, mock.Anything).Return(model.Point{}, gorm.ErrRecordNotFound)
+
+	pointRepository.On("Create", mock.Anything, model.Point{Level: "platinum", Remaining: 100}).Return(model.Point{Level: "platinum", Remaining: 100}, nil)
+	pointRepository.On("Create", mock.Anything, model.Point{Level: "copper", Remaining: 100}).Return(model.Point{}, repository.ErrPointExists)
+	pointRepository.On("Create", mock.Anything, mock.Anything).Return(model.Point{}, errors.New("create point error"))
+
+	pointRepository.On("Increase", mock.Anything, "gold", uint(5)).Return(nil)
+	pointRepository.On("Increase", mock.Anything, mock.Anything, mock.Anything).Return(gorm.ErrRecordNotFound)
+
+	pointRepository.On("SetRemaining", mock.Anything, "gold", uint(15)).Return(nil)
+	pointRepository.On("SetRemaining", mock.Anything, mock.Anything, mock.Anything).Return(gorm.ErrRecordNotFound)
+
+	pointRepository.On("Delete", mock.Anything, "gold").Return(nil)
+	pointRepository.On("Delete", mock.Anything, mock.Anything).Return(gorm.ErrRecordNotFound)
+
+	tierRuleRepository := new(mockRepository.TierRuleRepository)
+	tierRuleRepository.On("GetActiveTierRules", mock.Anything, mock.Anything).Return([]model.TierRule{
+		{Model: gorm.Model{ID: 1}, Level: "bronze", MaxPrice: price(100)},
+		{Model: gorm.Model{ID: 2}, Level: "silver", MinPrice: 100},
+	}, nil)
+
+	suite.pointRepository = pointRepository
+	suite.tierRuleRepository = tierRuleRepository
+	suite.pointPoolService = service.NewPointPoolService(transaction, pointRepository, tierRuleRepository)
+}
+
+func (suite *PointPoolServiceTestSuite) TestPointPoolService_HappyCase_ListPointPools() {
+	points, err := suite.pointPoolService.ListPointPools(context.Background())
+	suite.Nil(err)
+	suite.Len(points, 2)
+}
+
+func (suite *PointPoolServiceTestSuite) TestPointPoolService_GetPointPoolNotFound() {
+	_, err := suite.pointPoolService.GetPointPool(context.Background(), "platinum")
+	suite.ErrorIs(err, gorm.ErrRecordNotFound)
+}
+
+func (suite *PointPoolServiceTestSuite) TestPointPoolService_HappyCase_CreatePointPool() {
+	point, err := suite.pointPoolService.CreatePointPool(context.Background(), "platinum", 100)
+	suite.Nil(err)
+	suite.Equal("platinum", point.Level)
+	suite.Equal(uint(100), point.Remaining)
+}
+
+func (suite *PointPoolServiceTestSuite) TestPointPoolService_CreatePointPoolInvalidLevel() {
+	for _, level := range []string{"", "Gold", "gold/1", "a very long level name that is over the limit"} {
+		_, err := suite.pointPoolService.CreatePointPool(context.Background(), level, 100)
+		suite.ErrorIs(err, service.ErrInvalidPointPool, level)
+	}
+
+	suite.pointRepository.AssertNotCalled(suite.T(), "Create", mock.Anything, mock.Anything)
+}
+
+func (suite *PointPoolServiceTestSuite) TestPointPoolService_CreatePointPoolExists() {
+	_, err := suite.pointPoolService.CreatePointPool(context.Background(), "gold", 100)
+	suite.ErrorIs(err, service.ErrPointPoolExists)
+	suite.pointRepository.AssertNotCalled(suite.T(), "Create", mock.Anything, mock.Anything)
+}
+
+func (suite *PointPoolServiceTestSuite) TestPointPoolService_CreatePointPoolConcurrentExists() {
+	// another request created the level between the read and the insert
+	_, err := suite.pointPoolService.CreatePointPool(context.Background(), "copper", 100)
+	suite.ErrorIs(err, service.ErrPointPoolExists)
+}
+
+func (suite *PointPoolServiceTestSuite) TestPointPoolService_CreatePointPoolGetError() {
+	_, err := suite.pointPoolService.CreatePointPool(context.Background(), "broken", 100)
+	suite.ErrorContains(err, "get point error")
+}
+
+func (suite *PointPoolServiceTestSuite) TestPointPoolService_CreatePointPoolError() {
+	_, err := suite.pointPoolService.CreatePointPool(context.Background(), "silver", 100)
+	suite.ErrorContains(err, "create point error")
+}
+
+func (suite *PointPoolServiceTestSuite) TestPointPoolService_HappyCase_TopUpPointPool() {
+	point, err := suite.pointPoolService.TopUpPointPool(context.Background(), "gold", 5)
+	suite.Nil(err)
+	suite.Equal(uint(15), point.Remaining)
+}
+
+func (suite *PointPoolServiceTestSuite) TestPointPoolService_TopUpPointPoolZeroAmount() {
+	_, err := suite.pointPoolService.TopUpPointPool(context.Background(), "gold", 0)
+	suite.ErrorIs(err, service.ErrInvalidPointPool)
+	suite.pointRepository.AssertNotCalled(suite.T(), "Increase", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *PointPoolServiceTestSuite) TestPointPoolService_TopUpPointPoolNotFound() {
+	_, err := suite.pointPoolService.TopUpPointPool(context.Background(), "platinum", 5)
+	suite.ErrorIs(err, gorm.ErrRecordNotFound)
+}
+
+func (suite *PointPoolServiceTestSuite) TestPointPoolService_HappyCase_SetPointPoolRemaining() {
+	point, err := suite.pointPoolService.SetPointPoolRemaining(context.Background(), "gold", 15)
+	suite.Nil(err)
+	suite.Equal(uint(15), point.Remaining)
+}
+
+func (suite *PointPoolServiceTestSuite) TestPointPoolService_SetPointPoolRemainingNotFound() {
+	_, err := suite.pointPoolService.SetPointPoolRemaining(context.Background(), "platinum", 15)
+	suite.ErrorIs(err, gorm.ErrRecordNotFound)
+}
+
+func (suite *PointPoolServiceTestSuite) TestPointPoolService_HappyCase_DeletePointPool() {
+	err := suite.pointPoolService.DeletePointPool(context.Background(), "gold")
+	suite.Nil(err)
+}
+
+func (suite *PointPoolServiceTestSuite) TestPointPoolService_DeletePointPoolNotFound() {
+	err := suite.pointPoolService.DeletePointPool(context.Background(), "platinum")
+	suite.ErrorIs(err, gorm.ErrRecordNotFound)
+}
+
+func (suite *PointPoolServiceTestSuite) TestPointPoolService_DeletePointPoolInUse() {
+	err := suite.pointPoolService.DeletePointPool(context.Background(), "silver")
+	suite.ErrorIs(err, service.ErrPointPoolInUse)
+	suite.ErrorContains(err, "tier rule 2 maps prices to silver")
+	suite.pointRepository.AssertNotCalled(suite.T(), "Delete", mock.Anything, mock.Anything)
+}
+
+func (suite *PointPoolServiceTestSuite) TestPointPoolService_DeletePointPoolGetTierRulesError() {
+	tierRuleRepository := new(mockRepository.TierRuleRepository)
+	tierRuleRepository.On("GetActiveTierRules", mock.Anything, mock.Anything).Return(nil, errors.New("select error"))
+	pointPoolService := service.NewPointPoolService(new(mockRepository.Transaction), suite.pointRepository, tierRuleRepository)
+
+	err := pointPoolService.DeletePointPool(context.Background(), "gold")
+	suite.ErrorContains(err, "get active tier rules error")
+	suite.pointRepository.AssertNotCalled(suite.T(), "Delete", mock.Anything, mock.Anything)
+}
+
+func TestPointPoolServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(PointPoolServiceTestSuite))
+}
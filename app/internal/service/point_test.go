@@ -4,13 +4,21 @@ import (
 	"context"
 	"errors"
 	"point-service/app/internal/model"
+	"point-service/app/internal/repository"
 	mockRepository "point-service/app/internal/repository/mocks"
 	"point-service/app/internal/service"
+	"point-service/app/pkg/kafka"
 	mockKafka "point-service/app/pkg/kafka/mocks"
 	"testing"
 
+	"github.com/IBM/sarama"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
 )
 
 type Key string
@@ -18,22 +26,60 @@ type Key string
 type PointServiceTestSuite struct {
 	suite.Suite
 	pointService service.PointService
+	metrics      *service.PointMetrics
 
-	pointRepository   *mockRepository.PointRepository
-	productRepository *mockRepository.ProductRepository
-	producer          *mockKafka.Producer
+	transaction              *mockRepository.Transaction
+	pointRepository          *mockRepository.PointRepository
+	productRepository        *mockRepository.ProductRepository
+	tierRuleRepository       *mockRepository.TierRuleRepository
+	processedOrderRepository *mockRepository.ProcessedOrderRepository
+	outboxRepository         *mockRepository.OutboxRepository
+	userPointRepository      *mockRepository.UserPointRepository
 
 	ctxDecreaseBronzeError context.Context
 	ctxDecreaseSilverError context.Context
 	ctxDecreaseGoldError   context.Context
+	ctxLevelNotFound       context.Context
+	ctxNotEnoughPoints     context.Context
+	ctxTierRuleError       context.Context
+	ctxInvalidTierRule     context.Context
 }
 
 func (suite *PointServiceTestSuite) SetupTest() {
+	suite.setupMockTransaction()
 	suite.setupMockPointRepository()
 	suite.setupMockProductRepository()
-	suite.setupMockProducer()
+	suite.setupMockTierRuleRepository()
+	suite.setupMockProcessedOrderRepository()
+	suite.setupMockOutboxRepository()
+	suite.setupMockUserPointRepository()
+	suite.metrics = service.NewPointMetrics(prometheus.NewRegistry(), suite.pointRepository)
+
+	suite.pointService = service.NewPointService(
+		suite.transaction,
+		suite.pointRepository,
+		suite.productRepository,
+		suite.tierRuleRepository,
+		suite.processedOrderRepository,
+		suite.outboxRepository,
+		suite.userPointRepository,
+		service.OrderTotalPolicy,
+		"decrease.point.success",
+		"decrease.point.failed",
+		"increase.point.success",
+		kafka.JSONEncoder,
+		kafka.CloudEvents{},
+		suite.metrics,
+	)
+}
+
+func (suite *PointServiceTestSuite) setupMockTransaction() {
+	transaction := new(mockRepository.Transaction)
+	transaction.On("WithinTransaction", mock.Anything, mock.Anything).Return(func(ctx context.Context, fn func(context.Context) error) error {
+		return fn(ctx)
+	})
 
-	suite.pointService = service.NewPointService(suite.pointRepository, suite.productRepository, suite.producer, "decrease.point.success")
+	suite.transaction = transaction
 }
 
 func (suite *PointServiceTestSuite) setupMockPointRepository() {
@@ -42,14 +88,21 @@ func (suite *PointServiceTestSuite) setupMockPointRepository() {
 	suite.ctxDecreaseBronzeError = context.WithValue(context.Background(), Key("error"), "bronze")
 	suite.ctxDecreaseSilverError = context.WithValue(context.Background(), Key("error"), "silver")
 	suite.ctxDecreaseGoldError = context.WithValue(context.Background(), Key("error"), "gold")
+	suite.ctxLevelNotFound = context.WithValue(context.Background(), Key("error"), "level not found")
+	suite.ctxNotEnoughPoints = context.WithValue(context.Background(), Key("error"), "not enough points")
 
-	pointRepository.On("DecreaseBronzePoint", suite.ctxDecreaseBronzeError).Return(errors.New("decrease bronze error"))
-	pointRepository.On("DecreaseSilverPoint", suite.ctxDecreaseSilverError).Return(errors.New("decrease silver error"))
-	pointRepository.On("DecreaseGoldPoint", suite.ctxDecreaseGoldError).Return(errors.New("decrease gold error"))
+	pointRepository.On("Decrease", suite.ctxDecreaseBronzeError, "bronze", uint(1)).Return(errors.New("decrease bronze error"))
+	pointRepository.On("Decrease", suite.ctxDecreaseSilverError, "silver", uint(1)).Return(errors.New("decrease silver error"))
+	pointRepository.On("Decrease", suite.ctxDecreaseGoldError, "gold", uint(1)).Return(errors.New("decrease gold error"))
+	pointRepository.On("Decrease", suite.ctxNotEnoughPoints, "gold", uint(1)).Return(repository.ErrNotEnoughPoints)
+	pointRepository.On("Decrease", suite.ctxLevelNotFound, "gold", uint(1)).Return(gorm.ErrRecordNotFound)
 
-	pointRepository.On("DecreaseBronzePoint", context.Background()).Return(nil)
-	pointRepository.On("DecreaseSilverPoint", context.Background()).Return(nil)
-	pointRepository.On("DecreaseGoldPoint", context.Background()).Return(nil)
+	pointRepository.On("Decrease", context.Background(), "bronze", uint(1)).Return(nil)
+	pointRepository.On("Decrease", context.Background(), "silver", uint(1)).Return(nil)
+	pointRepository.On("Decrease", context.Background(), "gold", uint(1)).Return(nil)
+	pointRepository.On("Decrease", context.Background(), mock.Anything, mock.Anything).Return(nil)
+
+	pointRepository.On("Increase", mock.Anything, mock.Anything, mock.Anything).Return(nil)
 
 	suite.pointRepository = pointRepository
 }
@@ -61,18 +114,115 @@ func (suite *PointServiceTestSuite) setupMockProductRepository() {
 	productRepository.On("GetProductById", mock.Anything, uint(3)).Return(model.Product{Name: "car", Price: 77}, nil)
 	productRepository.On("GetProductById", mock.Anything, uint(4)).Return(model.Product{}, errors.New("get product error"))
 	productRepository.On("GetProductById", mock.Anything, uint(5)).Return(model.Product{Name: "negative", Price: -289.2}, nil)
+	productRepository.On("GetProductById", mock.Anything, uint(6)).Return(model.Product{Name: "book", Price: 100.5}, nil)
+	productRepository.On("GetProductById", mock.Anything, uint(7)).Return(model.Product{Name: "bike", Price: 1000.5}, nil)
+	productRepository.On("GetProductById", mock.Anything, uint(8)).Return(model.Product{Name: "sticker", Price: 0}, nil)
+	productRepository.On("GetProductById", mock.Anything, uint(9)).Return(model.Product{}, gorm.ErrRecordNotFound)
 
 	suite.productRepository = productRepository
 }
 
-func (suite *PointServiceTestSuite) setupMockProducer() {
-	producer := new(mockKafka.Producer)
-	producer.On("SendMessage", "decrease.point.success", `{"order_id":1,"point_level":"gold"}`, mock.Anything).Return(nil)
-	producer.On("SendMessage", "decrease.point.success", `{"order_id":2,"point_level":"silver"}`, mock.Anything).Return(nil)
-	producer.On("SendMessage", "decrease.point.success", `{"order_id":3,"point_level":"bronze"}`, mock.Anything).Return(nil)
-	producer.On("SendMessage", "decrease.point.success", `{"order_id":5,"point_level":"gold"}`, mock.Anything).Return(errors.New("produce message error"))
+func (suite *PointServiceTestSuite) setupMockTierRuleRepository() {
+	tierRuleRepository := new(mockRepository.TierRuleRepository)
+
+	suite.ctxTierRuleError = context.WithValue(context.Background(), Key("error"), "tier rule")
+	suite.ctxInvalidTierRule = context.WithValue(context.Background(), Key("error"), "invalid tier rule")
+
+	tierRuleRepository.On("GetActiveTierRules", suite.ctxTierRuleError, mock.Anything).Return(nil, errors.New("get tier rules error"))
+	tierRuleRepository.On("GetActiveTierRules", suite.ctxInvalidTierRule, mock.Anything).Return(service.DefaultTierRules()[:2], nil)
+	tierRuleRepository.On("GetActiveTierRules", mock.Anything, mock.Anything).Return(service.DefaultTierRules(), nil)
+
+	suite.tierRuleRepository = tierRuleRepository
+}
+
+func (suite *PointServiceTestSuite) setupMockProcessedOrderRepository() {
+	processedOrderRepository := new(mockRepository.ProcessedOrderRepository)
+	processedOrderRepository.On("GetProcessedOrderByOrderId", mock.Anything, uint(7)).Return(model.ProcessedOrder{OrderId: 7, ProductId: 3, PointLevel: "bronze"}, nil)
+	processedOrderRepository.On("GetProcessedOrderByOrderId", mock.Anything, uint(21)).Return(model.ProcessedOrder{OrderId: 21, UserId: 42, Points: []model.ProcessedOrderPoint{{Level: "silver", Amount: 1}}}, nil)
+	processedOrderRepository.On("GetProcessedOrderByOrderId", mock.Anything, uint(30)).Return(processedOrder(30, 42, "", model.ProcessedOrderPoint{Level: "gold", Amount: 2}, model.ProcessedOrderPoint{Level: "bronze", Amount: 1}), nil)
+	processedOrderRepository.On("GetProcessedOrderByOrderId", mock.Anything, uint(31)).Return(processedOrder(31, 0, "silver"), nil)
+	processedOrderRepository.On("GetProcessedOrderByOrderId", mock.Anything, uint(32)).Return(processedOrder(32, 43, "", model.ProcessedOrderPoint{Level: "gold", Amount: 1}), nil)
+	processedOrderRepository.On("GetProcessedOrderByOrderId", mock.Anything, uint(22)).Return(model.ProcessedOrder{}, gorm.ErrRecordNotFound).Once()
+	processedOrderRepository.On("GetProcessedOrderByOrderId", mock.Anything, uint(22)).Return(processedOrder(22, 0, "", model.ProcessedOrderPoint{Level: "silver", Amount: 1}), nil)
+	processedOrderRepository.On("GetProcessedOrderByOrderId", mock.Anything, uint(8)).Return(model.ProcessedOrder{}, errors.New("get processed order error"))
+	// order 33 is cancelled before its success order comes
+	processedOrderRepository.On("GetProcessedOrderByOrderId", mock.Anything, uint(33)).Return(model.ProcessedOrder{}, gorm.ErrRecordNotFound).Once()
+	processedOrderRepository.On("GetProcessedOrderByOrderId", mock.Anything, uint(33)).Return(model.ProcessedOrder{OrderId: 33, Cancelled: true}, nil)
+	// the success order of order 34 commits while its cancellation records it
+	processedOrderRepository.On("GetProcessedOrderByOrderId", mock.Anything, uint(34)).Return(model.ProcessedOrder{}, gorm.ErrRecordNotFound).Once()
+	processedOrderRepository.On("GetProcessedOrderByOrderId", mock.Anything, uint(34)).Return(processedOrder(34, 0, "", model.ProcessedOrderPoint{Level: "gold", Amount: 1}), nil)
+	processedOrderRepository.On("GetProcessedOrderByOrderId", mock.Anything, mock.Anything).Return(model.ProcessedOrder{}, gorm.ErrRecordNotFound)
+
+	processedOrderRepository.On("CreateProcessedOrder", mock.Anything, model.ProcessedOrder{OrderId: 9, Points: []model.ProcessedOrderPoint{{Level: "silver", Amount: 1}}}).Return(errors.New("create processed order error"))
+	processedOrderRepository.On("CreateProcessedOrder", mock.Anything, model.ProcessedOrder{OrderId: 22, Points: []model.ProcessedOrderPoint{{Level: "silver", Amount: 1}}}).Return(repository.ErrProcessedOrderExists)
+	processedOrderRepository.On("CreateProcessedOrder", mock.Anything, mock.MatchedBy(func(processedOrder model.ProcessedOrder) bool {
+		return processedOrder.OrderId == 34
+	})).Return(repository.ErrProcessedOrderExists)
+	processedOrderRepository.On("CreateProcessedOrder", mock.Anything, mock.Anything).Return(nil)
+
+	processedOrderRepository.On("MarkProcessedOrderRestored", mock.Anything, uint(31), mock.Anything).Return(repository.ErrProcessedOrderRestored)
+	processedOrderRepository.On("MarkProcessedOrderRestored", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	suite.processedOrderRepository = processedOrderRepository
+}
+
+func processedOrder(id uint, userId uint, pointLevel string, points ...model.ProcessedOrderPoint) model.ProcessedOrder {
+	processedOrder := model.ProcessedOrder{OrderId: id, UserId: userId, PointLevel: pointLevel, Points: points}
+	processedOrder.ID = id
 
-	suite.producer = producer
+	return processedOrder
+}
+
+func outbox(topic string, orderId string, payload string) model.Outbox {
+	return model.Outbox{
+		AggregateId: orderId,
+		Topic:       topic,
+		Payload:     []byte(payload),
+		Headers:     map[string]string{},
+	}
+}
+
+func (suite *PointServiceTestSuite) setupMockOutboxRepository() {
+	outboxRepository := new(mockRepository.OutboxRepository)
+	outboxRepository.On("CreateOutbox", mock.Anything, outbox("decrease.point.success", "1", `{"version":2,"order_id":1,"point_level":"gold","points":[{"level":"gold","amount":1}]}`)).Return(nil)
+	outboxRepository.On("CreateOutbox", mock.Anything, outbox("decrease.point.success", "2", `{"version":2,"order_id":2,"point_level":"silver","points":[{"level":"silver","amount":1}]}`)).Return(nil)
+	outboxRepository.On("CreateOutbox", mock.Anything, outbox("decrease.point.success", "3", `{"version":2,"order_id":3,"point_level":"bronze","points":[{"level":"bronze","amount":1}]}`)).Return(nil)
+	outboxRepository.On("CreateOutbox", mock.Anything, outbox("decrease.point.success", "5", `{"version":2,"order_id":5,"point_level":"gold","points":[{"level":"gold","amount":1}]}`)).Return(errors.New("create outbox error"))
+	outboxRepository.On("CreateOutbox", mock.Anything, outbox("decrease.point.success", "7", `{"version":2,"order_id":7,"point_level":"bronze","points":[{"level":"bronze","amount":1}]}`)).Return(nil)
+	outboxRepository.On("CreateOutbox", mock.Anything, outbox("decrease.point.success", "12", `{"version":2,"order_id":12,"point_level":"silver","points":[{"level":"silver","amount":1}]}`)).Return(nil)
+	outboxRepository.On("CreateOutbox", mock.Anything, outbox("decrease.point.success", "13", `{"version":2,"order_id":13,"point_level":"gold","points":[{"level":"gold","amount":1}]}`)).Return(nil)
+	outboxRepository.On("CreateOutbox", mock.Anything, outbox("decrease.point.success", "14", `{"version":2,"order_id":14,"point_level":"silver","points":[{"level":"silver","amount":1}]}`)).Return(nil)
+	outboxRepository.On("CreateOutbox", mock.Anything, outbox("decrease.point.success", "15", `{"version":2,"order_id":15,"point_level":"bronze","points":[{"level":"bronze","amount":1}]}`)).Return(nil)
+	outboxRepository.On("CreateOutbox", mock.Anything, outbox("decrease.point.success", "16", `{"version":2,"order_id":16,"points":[{"level":"bronze","amount":1},{"level":"gold","amount":2},{"level":"silver","amount":3}]}`)).Return(nil)
+	outboxRepository.On("CreateOutbox", mock.Anything, outbox("decrease.point.failed", "17", `{"order_id":17,"reason":"unexpected price category"}`)).Return(nil)
+	outboxRepository.On("CreateOutbox", mock.Anything, outbox("decrease.point.success", "35", `{"version":2,"order_id":35,"point_level":"gold","points":[{"level":"gold","amount":1}]}`)).Return(nil)
+	outboxRepository.On("CreateOutbox", mock.Anything, outbox("decrease.point.success", "36", `{"version":2,"order_id":36,"points":[]}`)).Return(nil)
+	outboxRepository.On("CreateOutbox", mock.Anything, outbox("decrease.point.success", "19", `{"version":2,"order_id":19,"user_id":42,"points":[{"level":"bronze","amount":1},{"level":"gold","amount":2}]}`)).Return(nil)
+	outboxRepository.On("CreateOutbox", mock.Anything, outbox("decrease.point.success", "21", `{"version":2,"order_id":21,"user_id":42,"point_level":"silver","points":[{"level":"silver","amount":1}]}`)).Return(nil)
+	outboxRepository.On("CreateOutbox", mock.Anything, outbox("decrease.point.success", "22", `{"version":2,"order_id":22,"point_level":"silver","points":[{"level":"silver","amount":1}]}`)).Return(nil)
+	outboxRepository.On("CreateOutbox", mock.Anything, outbox("increase.point.success", "30", `{"version":1,"order_id":30,"user_id":42,"points":[{"level":"gold","amount":2},{"level":"bronze","amount":1}]}`)).Return(nil)
+	outboxRepository.On("CreateOutbox", mock.Anything, outbox("increase.point.success", "32", `{"version":1,"order_id":32,"user_id":43,"points":[{"level":"gold","amount":1}]}`)).Return(nil)
+	outboxRepository.On("CreateOutbox", mock.Anything, outbox("increase.point.success", "31", `{"version":1,"order_id":31,"points":[{"level":"silver","amount":1}]}`)).Return(nil)
+	outboxRepository.On("CreateOutbox", mock.Anything, outbox("increase.point.success", "34", `{"version":1,"order_id":34,"points":[{"level":"gold","amount":1}]}`)).Return(nil)
+	outboxRepository.On("CreateOutbox", mock.Anything, outbox("decrease.point.failed", "37", `{"order_id":37,"reason":"product not found: product 9"}`)).Return(nil)
+	outboxRepository.On("CreateOutbox", mock.Anything, outbox("decrease.point.failed", "6", `{"order_id":6,"reason":"unexpected price category"}`)).Return(nil)
+	outboxRepository.On("CreateOutbox", mock.Anything, outbox("decrease.point.failed", "10", `{"order_id":10,"reason":"decrease gold point error: not enough points"}`)).Return(nil)
+	outboxRepository.On("CreateOutbox", mock.Anything, outbox("decrease.point.failed", "11", `{"order_id":11,"reason":"decrease gold point error: not enough points"}`)).Return(errors.New("create outbox error"))
+
+	suite.outboxRepository = outboxRepository
+}
+
+func (suite *PointServiceTestSuite) setupMockUserPointRepository() {
+	userPointRepository := new(mockRepository.UserPointRepository)
+	userPointRepository.On("Credit", mock.Anything, uint(43), "gold", uint(20), uint(1)).Return(errors.New("credit error"))
+	userPointRepository.On("Credit", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	userPointRepository.On("Debit", mock.Anything, uint(43), "gold", uint(32), uint(1)).Return(repository.ErrNotEnoughBalance)
+	userPointRepository.On("Debit", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	userPointRepository.On("Clawback", mock.Anything, uint(43), "gold", uint(32), uint(1)).Return(uint(1), nil)
+
+	suite.userPointRepository = userPointRepository
 }
 
 func (suite *PointServiceTestSuite) TestPointService_HappyCase_DecreaseGold() {
@@ -116,7 +266,32 @@ func (suite *PointServiceTestSuite) TestPointService_NegativePrice() {
 	}
 
 	err := suite.pointService.DecreasePoint(ctx, successOrder)
+	suite.Empty(err)
+	suite.outboxRepository.AssertCalled(suite.T(), "CreateOutbox", mock.Anything, outbox("decrease.point.failed", "6", `{"order_id":6,"reason":"unexpected price category"}`))
+}
+
+func (suite *PointServiceTestSuite) TestPointService_NotEnoughPoints() {
+	successOrder := model.SuccessOrder{
+		OrderId:   10,
+		ProductId: 1,
+	}
+
+	err := suite.pointService.DecreasePoint(suite.ctxNotEnoughPoints, successOrder)
+	suite.Empty(err)
+	suite.outboxRepository.AssertCalled(suite.T(), "CreateOutbox", mock.Anything, outbox("decrease.point.failed", "10", `{"order_id":10,"reason":"decrease gold point error: not enough points"}`))
+	suite.Equal(1.0, testutil.ToFloat64(suite.metrics.OrdersCounter(service.OrderFailed)))
+	suite.Equal(0.0, testutil.ToFloat64(suite.metrics.PointsCounter("gold", service.PointDecrease)))
+}
+
+func (suite *PointServiceTestSuite) TestPointService_CreateFailedOutboxError() {
+	successOrder := model.SuccessOrder{
+		OrderId:   11,
+		ProductId: 1,
+	}
+
+	err := suite.pointService.DecreasePoint(suite.ctxNotEnoughPoints, successOrder)
 	suite.NotNil(err)
+	suite.Equal(0.0, testutil.ToFloat64(suite.metrics.OrdersCounter(service.OrderFailed)))
 }
 
 func (suite *PointServiceTestSuite) TestPointService_GetProductError() {
@@ -130,7 +305,34 @@ func (suite *PointServiceTestSuite) TestPointService_GetProductError() {
 	suite.NotNil(err)
 }
 
-func (suite *PointServiceTestSuite) TestPointService_ProduceError() {
+func (suite *PointServiceTestSuite) TestPointService_ProductNotFound() {
+	successOrder := model.SuccessOrder{
+		OrderId:   37,
+		ProductId: 9,
+	}
+
+	// a deleted product earns no points, the order fails for good
+	err := suite.pointService.DecreasePoint(context.Background(), successOrder)
+	suite.Empty(err)
+	suite.outboxRepository.AssertCalled(suite.T(), "CreateOutbox", mock.Anything, outbox("decrease.point.failed", "37", `{"order_id":37,"reason":"product not found: product 9"}`))
+}
+
+func (suite *PointServiceTestSuite) TestPointService_LevelNotFound() {
+	successOrder := model.SuccessOrder{
+		OrderId:   38,
+		ProductId: 1,
+	}
+
+	// a missing level is retried like invalid tier rules instead of failing the order
+	err := suite.pointService.DecreasePoint(suite.ctxLevelNotFound, successOrder)
+	suite.ErrorIs(err, service.ErrInvalidTierRules)
+	suite.ErrorContains(err, "level gold does not exist")
+	suite.True(service.IsTransientError(err))
+	suite.outboxRepository.AssertNotCalled(suite.T(), "CreateOutbox", mock.Anything, mock.Anything)
+	suite.Equal(0.0, testutil.ToFloat64(suite.metrics.OrdersCounter(service.OrderFailed)))
+}
+
+func (suite *PointServiceTestSuite) TestPointService_CreateOutboxError() {
 	ctx := context.Background()
 	successOrder := model.SuccessOrder{
 		OrderId:   5,
@@ -169,8 +371,532 @@ func (suite *PointServiceTestSuite) TestPointService_DecreaseGoldError() {
 
 	err := suite.pointService.DecreasePoint(suite.ctxDecreaseGoldError, successOrder)
 	suite.NotNil(err)
+	// a rolled back order is not counted
+	suite.Equal(0.0, testutil.ToFloat64(suite.metrics.OrdersCounter(service.OrderDecreased)))
+}
+
+func (suite *PointServiceTestSuite) TestPointService_ProcessedOrder_ReEmitResult() {
+	ctx := context.Background()
+	successOrder := model.SuccessOrder{
+		OrderId:   7,
+		ProductId: 3,
+	}
+
+	err := suite.pointService.DecreasePoint(ctx, successOrder)
+	suite.Empty(err)
+	suite.pointRepository.AssertNotCalled(suite.T(), "Decrease", mock.Anything, "bronze", mock.Anything)
+	suite.processedOrderRepository.AssertNotCalled(suite.T(), "CreateProcessedOrder", mock.Anything, mock.Anything)
+	suite.outboxRepository.AssertCalled(suite.T(), "CreateOutbox", mock.Anything, outbox("decrease.point.success", "7", `{"version":2,"order_id":7,"point_level":"bronze","points":[{"level":"bronze","amount":1}]}`))
+	suite.Equal(1.0, testutil.ToFloat64(suite.metrics.OrdersCounter(service.OrderDuplicate)))
+	suite.Equal(0.0, testutil.ToFloat64(suite.metrics.OrdersCounter(service.OrderDecreased)))
+	suite.Equal(0.0, testutil.ToFloat64(suite.metrics.PointsCounter("bronze", service.PointDecrease)))
+}
+
+func (suite *PointServiceTestSuite) TestPointService_PropagateHeaders() {
+	ctx := kafka.WithMessage(context.Background(), &sarama.ConsumerMessage{
+		Topic: "success.order",
+		Headers: []*sarama.RecordHeader{
+			{Key: []byte(kafka.HeaderCorrelationId), Value: []byte("checkout-1")},
+			{Key: []byte(kafka.HeaderTraceparent), Value: []byte("00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01")},
+			{Key: []byte(kafka.HeaderTenant), Value: []byte("th")},
+			{Key: []byte("content-type"), Value: []byte("application/json")},
+		},
+	})
+	expected := outbox("decrease.point.success", "7", `{"version":2,"order_id":7,"point_level":"bronze","points":[{"level":"bronze","amount":1}]}`)
+	expected.Headers = map[string]string{
+		kafka.HeaderCorrelationId: "checkout-1",
+		kafka.HeaderTraceparent:   "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01",
+		kafka.HeaderTenant:        "th",
+	}
+	suite.outboxRepository.On("CreateOutbox", mock.Anything, expected).Return(nil)
+
+	err := suite.pointService.DecreasePoint(ctx, model.SuccessOrder{OrderId: 7, ProductId: 3})
+	suite.Nil(err)
+	suite.outboxRepository.AssertCalled(suite.T(), "CreateOutbox", mock.Anything, expected)
+}
+
+func (suite *PointServiceTestSuite) TestPointService_SendEventInTransaction() {
+	producer := new(mockKafka.Producer)
+	producer.On("SendMessage", "decrease.point.success", "7", `{"version":2,"order_id":7,"point_level":"bronze","points":[{"level":"bronze","amount":1}]}`, map[string]string{}).Return(nil)
+
+	err := suite.pointService.DecreasePoint(kafka.WithTransaction(context.Background(), producer), model.SuccessOrder{OrderId: 7, ProductId: 3})
+	suite.Nil(err)
+	producer.AssertExpectations(suite.T())
+	suite.outboxRepository.AssertNotCalled(suite.T(), "CreateOutbox", mock.Anything, mock.Anything)
+}
+
+func (suite *PointServiceTestSuite) TestPointService_CloudEventsEnvelope() {
+	pointService := service.NewPointService(
+		suite.transaction,
+		suite.pointRepository,
+		suite.productRepository,
+		suite.tierRuleRepository,
+		suite.processedOrderRepository,
+		suite.outboxRepository,
+		suite.userPointRepository,
+		service.OrderTotalPolicy,
+		"decrease.point.success",
+		"decrease.point.failed",
+		"increase.point.success",
+		kafka.JSONEncoder,
+		kafka.CloudEvents{
+			Mode:   kafka.BinaryCloudEvents,
+			Source: "/point-service",
+			Types:  map[string]string{"decrease.point.success": model.DecreasePointSuccessType},
+		},
+		suite.metrics,
+	)
+	var created model.Outbox
+	suite.outboxRepository.On("CreateOutbox", mock.Anything, mock.MatchedBy(func(outbox model.Outbox) bool {
+		created = outbox
+		return outbox.Topic == "decrease.point.success"
+	})).Return(nil)
+
+	err := pointService.DecreasePoint(kafka.WithCorrelationId(context.Background(), "checkout-1"), model.SuccessOrder{OrderId: 7, ProductId: 3})
+	suite.Nil(err)
+	// the event stays the value, the envelope is in the headers
+	suite.Equal(`{"version":2,"order_id":7,"point_level":"bronze","points":[{"level":"bronze","amount":1}]}`, string(created.Payload))
+	suite.Equal("1.0", created.Headers["ce_specversion"])
+	suite.Equal("decrease.point.success-7", created.Headers["ce_id"])
+	suite.Equal("/point-service", created.Headers["ce_source"])
+	suite.Equal(model.DecreasePointSuccessType, created.Headers["ce_type"])
+	suite.Equal("7", created.Headers["ce_subject"])
+	suite.Equal("application/json", created.Headers["content-type"])
+	suite.Equal("checkout-1", created.Headers[kafka.HeaderCorrelationId])
+}
+
+func (suite *PointServiceTestSuite) TestPointService_GetProcessedOrderError() {
+	ctx := context.Background()
+	successOrder := model.SuccessOrder{
+		OrderId:   8,
+		ProductId: 1,
+	}
+
+	err := suite.pointService.DecreasePoint(ctx, successOrder)
+	suite.NotNil(err)
+	suite.pointRepository.AssertNotCalled(suite.T(), "Decrease", mock.Anything, "gold", mock.Anything)
+}
+
+func (suite *PointServiceTestSuite) TestPointService_CreateProcessedOrderError() {
+	ctx := context.Background()
+	successOrder := model.SuccessOrder{
+		OrderId:   9,
+		ProductId: 2,
+	}
+
+	err := suite.pointService.DecreasePoint(ctx, successOrder)
+	suite.NotNil(err)
+	suite.outboxRepository.AssertNotCalled(suite.T(), "CreateOutbox", mock.Anything, mock.Anything)
+}
+
+func (suite *PointServiceTestSuite) TestPointService_ProcessedOrder_ConcurrentDelivery() {
+	// the other delivery of the order commits its processed order between the read and the insert
+	err := suite.pointService.DecreasePoint(context.Background(), model.SuccessOrder{OrderId: 22, ProductId: 2})
+	suite.Nil(err)
+	suite.transaction.AssertNumberOfCalls(suite.T(), "WithinTransaction", 2)
+	suite.processedOrderRepository.AssertNumberOfCalls(suite.T(), "CreateProcessedOrder", 1)
+	suite.outboxRepository.AssertCalled(suite.T(), "CreateOutbox", mock.Anything, outbox("decrease.point.success", "22", `{"version":2,"order_id":22,"point_level":"silver","points":[{"level":"silver","amount":1}]}`))
+	suite.outboxRepository.AssertNotCalled(suite.T(), "CreateOutbox", mock.Anything, mock.MatchedBy(func(outbox model.Outbox) bool {
+		return outbox.Topic == "decrease.point.failed"
+	}))
+	suite.Equal(1.0, testutil.ToFloat64(suite.metrics.OrdersCounter(service.OrderDuplicate)))
+	suite.Equal(0.0, testutil.ToFloat64(suite.metrics.OrdersCounter(service.OrderDecreased)))
+}
+
+func (suite *PointServiceTestSuite) TestPointService_HappyCase_FractionalPriceSilver() {
+	ctx := context.Background()
+	successOrder := model.SuccessOrder{
+		OrderId:   12,
+		ProductId: 6,
+	}
+
+	err := suite.pointService.DecreasePoint(ctx, successOrder)
+	suite.Empty(err)
+	suite.pointRepository.AssertCalled(suite.T(), "Decrease", ctx, "silver", uint(1))
+}
+
+func (suite *PointServiceTestSuite) TestPointService_HappyCase_FractionalPriceGold() {
+	ctx := context.Background()
+	successOrder := model.SuccessOrder{
+		OrderId:   13,
+		ProductId: 7,
+	}
+
+	err := suite.pointService.DecreasePoint(ctx, successOrder)
+	suite.Empty(err)
+	suite.pointRepository.AssertCalled(suite.T(), "Decrease", ctx, "gold", uint(1))
+}
+
+func (suite *PointServiceTestSuite) TestPointService_GetTierRulesError() {
+	successOrder := model.SuccessOrder{
+		OrderId:   1,
+		ProductId: 1,
+	}
+
+	err := suite.pointService.DecreasePoint(suite.ctxTierRuleError, successOrder)
+	suite.NotNil(err)
+}
+
+func (suite *PointServiceTestSuite) TestPointService_InvalidTierRules() {
+	successOrder := model.SuccessOrder{
+		OrderId:   1,
+		ProductId: 1,
+	}
+
+	err := suite.pointService.DecreasePoint(suite.ctxInvalidTierRule, successOrder)
+	suite.ErrorContains(err, "no rule covers prices above 1000")
+	// a bad configuration is retried instead of failing the order
+	suite.ErrorIs(err, service.ErrInvalidTierRules)
+	suite.True(service.IsTransientError(err))
+	suite.outboxRepository.AssertNotCalled(suite.T(), "CreateOutbox", mock.Anything, mock.Anything)
+}
+
+func (suite *PointServiceTestSuite) TestPointService_TierRulesValidatedOnChange() {
+	tierRules := service.DefaultTierRules()
+	tierRules[2].MinPrice = 2000
+	tierRuleRepository := new(mockRepository.TierRuleRepository)
+	tierRuleRepository.On("GetActiveTierRules", mock.Anything, mock.Anything).Return(service.DefaultTierRules(), nil).Twice()
+	tierRuleRepository.On("GetActiveTierRules", mock.Anything, mock.Anything).Return(tierRules, nil)
+	pointService := service.NewPointService(
+		suite.transaction,
+		suite.pointRepository,
+		suite.productRepository,
+		tierRuleRepository,
+		suite.processedOrderRepository,
+		suite.outboxRepository,
+		suite.userPointRepository,
+		service.OrderTotalPolicy,
+		"decrease.point.success",
+		"decrease.point.failed",
+		"increase.point.success",
+		kafka.JSONEncoder,
+		kafka.CloudEvents{},
+		// a service without metrics counts nothing
+		nil,
+	)
+
+	suite.Nil(pointService.DecreasePoint(context.Background(), model.SuccessOrder{OrderId: 1, ProductId: 1}))
+	suite.Nil(pointService.DecreasePoint(context.Background(), model.SuccessOrder{OrderId: 2, ProductId: 2}))
+
+	// changed rules are validated again
+	err := pointService.DecreasePoint(context.Background(), model.SuccessOrder{OrderId: 3, ProductId: 3})
+	suite.ErrorContains(err, "no rule covers prices between 1000 and 2000")
+}
+
+func (suite *PointServiceTestSuite) perLinePointService() service.PointService {
+	return service.NewPointService(
+		suite.transaction,
+		suite.pointRepository,
+		suite.productRepository,
+		suite.tierRuleRepository,
+		suite.processedOrderRepository,
+		suite.outboxRepository,
+		suite.userPointRepository,
+		service.PerLinePolicy,
+		"decrease.point.success",
+		"decrease.point.failed",
+		"increase.point.success",
+		kafka.JSONEncoder,
+		kafka.CloudEvents{},
+		suite.metrics,
+	)
+}
+
+func (suite *PointServiceTestSuite) TestPointService_HappyCase_OrderTotal() {
+	ctx := context.Background()
+	successOrder := model.SuccessOrder{
+		Version: model.SuccessOrderVersion,
+		OrderId: 14,
+		Items: []model.OrderItem{
+			{ProductId: 3, Quantity: 2},
+			{ProductId: 2, Quantity: 1},
+		},
+	}
+
+	// 77 * 2 + 800 is a silver order
+	err := suite.pointService.DecreasePoint(ctx, successOrder)
+	suite.Empty(err)
+	suite.pointRepository.AssertCalled(suite.T(), "Decrease", ctx, "silver", uint(1))
+	suite.pointRepository.AssertNumberOfCalls(suite.T(), "Decrease", 1)
+	suite.processedOrderRepository.AssertCalled(suite.T(), "CreateProcessedOrder", mock.Anything, model.ProcessedOrder{
+		OrderId: 14,
+		Points:  []model.ProcessedOrderPoint{{Level: "silver", Amount: 1}},
+	})
+	suite.Equal(1.0, testutil.ToFloat64(suite.metrics.OrdersCounter(service.OrderDecreased)))
+	suite.Equal(1.0, testutil.ToFloat64(suite.metrics.PointsCounter("silver", service.PointDecrease)))
+}
+
+func (suite *PointServiceTestSuite) TestPointService_HappyCase_UnitPriceOverride() {
+	ctx := context.Background()
+	unitPrice := 50.0
+	successOrder := model.SuccessOrder{
+		Version: model.SuccessOrderVersion,
+		OrderId: 15,
+		Items:   []model.OrderItem{{ProductId: 1, Quantity: 1, UnitPrice: &unitPrice}},
+	}
+
+	err := suite.pointService.DecreasePoint(ctx, successOrder)
+	suite.Empty(err)
+	suite.pointRepository.AssertCalled(suite.T(), "Decrease", ctx, "bronze", uint(1))
+}
+
+func (suite *PointServiceTestSuite) TestPointService_HappyCase_PerLine() {
+	ctx := context.Background()
+	successOrder := model.SuccessOrder{
+		Version: model.SuccessOrderVersion,
+		OrderId: 16,
+		Items: []model.OrderItem{
+			{ProductId: 1, Quantity: 2},
+			{ProductId: 2, Quantity: 1},
+			{ProductId: 3, Quantity: 1},
+			{ProductId: 6, Quantity: 2},
+		},
+	}
+
+	err := suite.perLinePointService().DecreasePoint(ctx, successOrder)
+	suite.Empty(err)
+
+	// every level once, in level order
+	suite.Equal([]interface{}{"bronze", "gold", "silver"}, []interface{}{
+		suite.pointRepository.Calls[0].Arguments[1],
+		suite.pointRepository.Calls[1].Arguments[1],
+		suite.pointRepository.Calls[2].Arguments[1],
+	})
+	suite.pointRepository.AssertCalled(suite.T(), "Decrease", ctx, "bronze", uint(1))
+	suite.pointRepository.AssertCalled(suite.T(), "Decrease", ctx, "gold", uint(2))
+	suite.pointRepository.AssertCalled(suite.T(), "Decrease", ctx, "silver", uint(3))
+	suite.outboxRepository.AssertCalled(suite.T(), "CreateOutbox", mock.Anything, outbox("decrease.point.success", "16", `{"version":2,"order_id":16,"points":[{"level":"bronze","amount":1},{"level":"gold","amount":2},{"level":"silver","amount":3}]}`))
+}
+
+func (suite *PointServiceTestSuite) TestPointService_PerLineUnexpectedPrice() {
+	ctx := context.Background()
+	successOrder := model.SuccessOrder{
+		Version: model.SuccessOrderVersion,
+		OrderId: 17,
+		Items: []model.OrderItem{
+			{ProductId: 1, Quantity: 1},
+			{ProductId: 5, Quantity: 1},
+		},
+	}
+
+	err := suite.perLinePointService().DecreasePoint(ctx, successOrder)
+	suite.Empty(err)
+	suite.pointRepository.AssertNotCalled(suite.T(), "Decrease", mock.Anything, mock.Anything, mock.Anything)
+	suite.outboxRepository.AssertCalled(suite.T(), "CreateOutbox", mock.Anything, outbox("decrease.point.failed", "17", `{"order_id":17,"reason":"unexpected price category"}`))
+}
+
+func (suite *PointServiceTestSuite) TestPointService_HappyCase_PerLineFreeItem() {
+	ctx := context.Background()
+	successOrder := model.SuccessOrder{
+		Version: model.SuccessOrderVersion,
+		OrderId: 35,
+		Items: []model.OrderItem{
+			{ProductId: 1, Quantity: 1},
+			{ProductId: 8, Quantity: 2},
+		},
+	}
+
+	err := suite.perLinePointService().DecreasePoint(ctx, successOrder)
+	suite.Empty(err)
+	suite.pointRepository.AssertNumberOfCalls(suite.T(), "Decrease", 1)
+	suite.pointRepository.AssertCalled(suite.T(), "Decrease", ctx, "gold", uint(1))
+	suite.outboxRepository.AssertCalled(suite.T(), "CreateOutbox", mock.Anything, outbox("decrease.point.success", "35", `{"version":2,"order_id":35,"point_level":"gold","points":[{"level":"gold","amount":1}]}`))
+}
+
+func (suite *PointServiceTestSuite) TestPointService_HappyCase_FreeOrder() {
+	ctx := context.Background()
+	successOrder := model.SuccessOrder{
+		OrderId:   36,
+		ProductId: 8,
+	}
+
+	err := suite.pointService.DecreasePoint(ctx, successOrder)
+	suite.Empty(err)
+	suite.pointRepository.AssertNotCalled(suite.T(), "Decrease", mock.Anything, mock.Anything, mock.Anything)
+	suite.outboxRepository.AssertCalled(suite.T(), "CreateOutbox", mock.Anything, outbox("decrease.point.success", "36", `{"version":2,"order_id":36,"points":[]}`))
+}
+
+func (suite *PointServiceTestSuite) TestPointService_InvalidSuccessOrder() {
+	for _, successOrder := range []model.SuccessOrder{
+		{OrderId: 18},
+		{ProductId: 1},
+		{Version: model.SuccessOrderVersion, Items: []model.OrderItem{{ProductId: 1, Quantity: 1}}},
+		{Version: 3, OrderId: 18, ProductId: 1},
+		{Version: model.SuccessOrderVersion, OrderId: 18},
+		{Version: model.SuccessOrderVersion, OrderId: 18, Items: []model.OrderItem{{ProductId: 1}}},
+		{Version: model.SuccessOrderVersion, OrderId: 18, Items: []model.OrderItem{{Quantity: 1}}},
+	} {
+		err := suite.pointService.DecreasePoint(context.Background(), successOrder)
+		suite.NotNil(err)
+		suite.False(service.IsTransientError(err))
+	}
+
+	suite.processedOrderRepository.AssertNotCalled(suite.T(), "GetProcessedOrderByOrderId", mock.Anything, mock.Anything)
+	suite.outboxRepository.AssertNotCalled(suite.T(), "CreateOutbox", mock.Anything, mock.Anything)
+}
+
+func (suite *PointServiceTestSuite) TestPointService_HappyCase_CreditUser() {
+	ctx := context.Background()
+	successOrder := model.SuccessOrder{
+		Version: model.SuccessOrderVersion,
+		OrderId: 19,
+		UserId:  42,
+		Items: []model.OrderItem{
+			{ProductId: 1, Quantity: 2},
+			{ProductId: 3, Quantity: 1},
+		},
+	}
+
+	err := suite.perLinePointService().DecreasePoint(ctx, successOrder)
+	suite.Empty(err)
+	suite.userPointRepository.AssertCalled(suite.T(), "Credit", ctx, uint(42), "bronze", uint(19), uint(1))
+	suite.userPointRepository.AssertCalled(suite.T(), "Credit", ctx, uint(42), "gold", uint(19), uint(2))
+	suite.processedOrderRepository.AssertCalled(suite.T(), "CreateProcessedOrder", mock.Anything, model.ProcessedOrder{
+		OrderId: 19,
+		UserId:  42,
+		Points:  []model.ProcessedOrderPoint{{Level: "bronze", Amount: 1}, {Level: "gold", Amount: 2}},
+	})
+	suite.outboxRepository.AssertCalled(suite.T(), "CreateOutbox", mock.Anything, outbox("decrease.point.success", "19", `{"version":2,"order_id":19,"user_id":42,"points":[{"level":"bronze","amount":1},{"level":"gold","amount":2}]}`))
+}
+
+func (suite *PointServiceTestSuite) TestPointService_WithoutUserSkipsCredit() {
+	err := suite.pointService.DecreasePoint(context.Background(), model.SuccessOrder{OrderId: 1, ProductId: 1})
+	suite.Empty(err)
+	suite.userPointRepository.AssertNotCalled(suite.T(), "Credit", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *PointServiceTestSuite) TestPointService_CreditUserError() {
+	successOrder := model.SuccessOrder{
+		OrderId:   20,
+		UserId:    43,
+		ProductId: 1,
+	}
+
+	err := suite.pointService.DecreasePoint(context.Background(), successOrder)
+	suite.ErrorContains(err, "credit user gold point error")
+	suite.processedOrderRepository.AssertNotCalled(suite.T(), "CreateProcessedOrder", mock.Anything, mock.Anything)
+}
+
+func (suite *PointServiceTestSuite) TestPointService_ProcessedOrder_ReEmitUser() {
+	err := suite.pointService.DecreasePoint(context.Background(), model.SuccessOrder{OrderId: 21, UserId: 42, ProductId: 2})
+	suite.Empty(err)
+	suite.userPointRepository.AssertNotCalled(suite.T(), "Credit", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	suite.outboxRepository.AssertCalled(suite.T(), "CreateOutbox", mock.Anything, outbox("decrease.point.success", "21", `{"version":2,"order_id":21,"user_id":42,"point_level":"silver","points":[{"level":"silver","amount":1}]}`))
+}
+
+func (suite *PointServiceTestSuite) TestPointService_HappyCase_RestorePoint() {
+	err := suite.pointService.RestorePoint(context.Background(), 30)
+	suite.Nil(err)
+
+	suite.pointRepository.AssertCalled(suite.T(), "Increase", mock.Anything, "gold", uint(2))
+	suite.pointRepository.AssertCalled(suite.T(), "Increase", mock.Anything, "bronze", uint(1))
+	suite.userPointRepository.AssertCalled(suite.T(), "Debit", mock.Anything, uint(42), "gold", uint(30), uint(2))
+	suite.userPointRepository.AssertCalled(suite.T(), "Debit", mock.Anything, uint(42), "bronze", uint(30), uint(1))
+	suite.outboxRepository.AssertCalled(suite.T(), "CreateOutbox", mock.Anything, outbox("increase.point.success", "30", `{"version":1,"order_id":30,"user_id":42,"points":[{"level":"gold","amount":2},{"level":"bronze","amount":1}]}`))
+
+	// levels are increased in the same order they are decreased
+	var levels []string
+	for _, call := range suite.pointRepository.Calls {
+		if call.Method == "Increase" {
+			levels = append(levels, call.Arguments.String(1))
+		}
+	}
+	suite.Equal([]string{"bronze", "gold"}, levels)
+
+	suite.Equal(1.0, testutil.ToFloat64(suite.metrics.OrdersCounter(service.OrderRestored)))
+	suite.Equal(2.0, testutil.ToFloat64(suite.metrics.PointsCounter("gold", service.PointIncrease)))
+	suite.Equal(1.0, testutil.ToFloat64(suite.metrics.PointsCounter("bronze", service.PointIncrease)))
+}
+
+func (suite *PointServiceTestSuite) TestPointService_HappyCase_RestorePointAlreadyRestored() {
+	err := suite.pointService.RestorePoint(context.Background(), 31)
+	suite.Nil(err)
+	suite.pointRepository.AssertNotCalled(suite.T(), "Increase", mock.Anything, mock.Anything, mock.Anything)
+	suite.outboxRepository.AssertCalled(suite.T(), "CreateOutbox", mock.Anything, outbox("increase.point.success", "31", `{"version":1,"order_id":31,"points":[{"level":"silver","amount":1}]}`))
+	suite.Equal(0.0, testutil.ToFloat64(suite.metrics.OrdersCounter(service.OrderRestored)))
+}
+
+func (suite *PointServiceTestSuite) TestPointService_HappyCase_RestorePointNotProcessed() {
+	err := suite.pointService.RestorePoint(context.Background(), 99)
+	suite.Nil(err)
+	suite.processedOrderRepository.AssertCalled(suite.T(), "CreateProcessedOrder", mock.Anything, mock.MatchedBy(func(processedOrder model.ProcessedOrder) bool {
+		return processedOrder.OrderId == 99 && processedOrder.Cancelled && processedOrder.RestoredAt != nil && len(processedOrder.Points) == 0
+	}))
+	suite.processedOrderRepository.AssertNotCalled(suite.T(), "MarkProcessedOrderRestored", mock.Anything, mock.Anything, mock.Anything)
+	suite.outboxRepository.AssertNotCalled(suite.T(), "CreateOutbox", mock.Anything, mock.Anything)
+}
+
+func (suite *PointServiceTestSuite) TestPointService_HappyCase_CancelBeforeSuccess() {
+	ctx := context.Background()
+	err := suite.pointService.RestorePoint(ctx, 33)
+	suite.Nil(err)
+
+	// the late success order takes nothing and emits nothing
+	err = suite.pointService.DecreasePoint(ctx, model.SuccessOrder{OrderId: 33, UserId: 42, ProductId: 2})
+	suite.ErrorIs(err, service.ErrOrderCancelled)
+	suite.pointRepository.AssertNotCalled(suite.T(), "Decrease", mock.Anything, mock.Anything, mock.Anything)
+	suite.userPointRepository.AssertNotCalled(suite.T(), "Credit", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	suite.outboxRepository.AssertNotCalled(suite.T(), "CreateOutbox", mock.Anything, mock.Anything)
+	suite.Equal(1.0, testutil.ToFloat64(suite.metrics.OrdersCounter(service.OrderCancelled)))
+	suite.Equal(0.0, testutil.ToFloat64(suite.metrics.OrdersCounter(service.OrderFailed)))
+
+	// a redelivered cancellation finds nothing to give back
+	err = suite.pointService.RestorePoint(ctx, 33)
+	suite.Nil(err)
+	suite.pointRepository.AssertNotCalled(suite.T(), "Increase", mock.Anything, mock.Anything, mock.Anything)
+	suite.processedOrderRepository.AssertNumberOfCalls(suite.T(), "CreateProcessedOrder", 1)
+}
+
+func (suite *PointServiceTestSuite) TestPointService_HappyCase_RestorePointSuccessCommittedFirst() {
+	err := suite.pointService.RestorePoint(context.Background(), 34)
+	suite.Nil(err)
+	suite.pointRepository.AssertCalled(suite.T(), "Increase", mock.Anything, "gold", uint(1))
+	suite.processedOrderRepository.AssertCalled(suite.T(), "MarkProcessedOrderRestored", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *PointServiceTestSuite) TestPointService_HappyCase_RestorePointShortfall() {
+	err := suite.pointService.RestorePoint(context.Background(), 32)
+	suite.Nil(err)
+	suite.pointRepository.AssertCalled(suite.T(), "Increase", mock.Anything, "gold", uint(1))
+	suite.userPointRepository.AssertCalled(suite.T(), "Clawback", mock.Anything, uint(43), "gold", uint(32), uint(1))
+	suite.outboxRepository.AssertCalled(suite.T(), "CreateOutbox", mock.Anything, outbox("increase.point.success", "32", `{"version":1,"order_id":32,"user_id":43,"points":[{"level":"gold","amount":1}]}`))
+}
+
+func (suite *PointServiceTestSuite) TestPointService_RestorePointGetProcessedOrderError() {
+	err := suite.pointService.RestorePoint(context.Background(), 8)
+	suite.ErrorContains(err, "get processed order error")
 }
 
 func TestPointServiceTestSuite(t *testing.T) {
 	suite.Run(t, new(PointServiceTestSuite))
 }
+
+func TestParseTierPolicy(t *testing.T) {
+	policy, err := service.ParseTierPolicy("per_line")
+	assert.Nil(t, err)
+	assert.Equal(t, service.PerLinePolicy, policy)
+
+	_, err = service.ParseTierPolicy("per_order")
+	assert.EqualError(t, err, "must be order_total or per_line")
+}
+
+func TestIsTransientError(t *testing.T) {
+	cases := []struct {
+		name      string
+		err       error
+		transient bool
+	}{
+		{name: "max attempts reached", err: errors.Join(errors.New("decrease gold point error"), repository.ErrMaxAttemptsReached), transient: true},
+		{name: "deadline exceeded", err: context.DeadlineExceeded, transient: true},
+		{name: "retryable produce error", err: kafka.Retryable(errors.New("send message error")), transient: true},
+		{name: "serialization failure", err: &pgconn.PgError{Code: "40001"}, transient: true},
+		{name: "unique violation", err: &pgconn.PgError{Code: "23505"}, transient: false},
+		{name: "unknown error", err: errors.New("unknown error"), transient: false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.Equal(t, c.transient, service.IsTransientError(c.err))
+		})
+	}
+}
@@ -0,0 +1,181 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"point-service/app/internal/model"
+	"point-service/app/internal/repository"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var ErrInvalidProduct = errors.New("invalid product")
+
+type ProductPage struct {
+	Products []model.Product
+	Page     int
+	PageSize int
+	Total    int64
+}
+
+type ProductService interface {
+	ListProducts(ctx context.Context, page int, pageSize int) (ProductPage, error)
+	GetProduct(ctx context.Context, productId uint) (model.Product, error)
+	CreateProduct(ctx context.Context, name string, price float64) (model.Product, error)
+	UpdateProduct(ctx context.Context, productId uint, name string, price float64) (model.Product, error)
+	DeleteProduct(ctx context.Context, productId uint) error
+}
+
+type productService struct {
+	transaction        repository.Transaction
+	productRepository  repository.ProductRepository
+	tierRuleRepository repository.TierRuleRepository
+}
+
+func NewProductService(
+	transaction repository.Transaction,
+	productRepository repository.ProductRepository,
+	tierRuleRepository repository.TierRuleRepository,
+) ProductService {
+	return &productService{
+		transaction:        transaction,
+		productRepository:  productRepository,
+		tierRuleRepository: tierRuleRepository,
+	}
+}
+
+// ListProducts returns the page of products, page 0 is the first page and page size 0 is DefaultPageSize
+func (service *productService) ListProducts(ctx context.Context, page int, pageSize int) (ProductPage, error) {
+	page, pageSize, offset, err := normalizePage(page, pageSize)
+	if err != nil {
+		return ProductPage{}, err
+	}
+
+	products, total, err := service.productRepository.ListProducts(ctx, offset, pageSize)
+	if err != nil {
+		return ProductPage{}, errors.Wrap(err, "list products error")
+	}
+
+	return ProductPage{
+		Products: products,
+		Page:     page,
+		PageSize: pageSize,
+		Total:    total,
+	}, nil
+}
+
+func (service *productService) GetProduct(ctx context.Context, productId uint) (model.Product, error) {
+	product, err := service.productRepository.GetProductById(ctx, productId)
+	if err != nil {
+		return product, errors.Wrap(err, "get product by id error")
+	}
+
+	return product, nil
+}
+
+func (service *productService) CreateProduct(ctx context.Context, name string, price float64) (model.Product, error) {
+	product := model.Product{
+		Name:  strings.TrimSpace(name),
+		Price: price,
+	}
+
+	err := validateProduct(product)
+	if err != nil {
+		return product, err
+	}
+
+	err = service.validatePriceTier(ctx, product.Price)
+	if err != nil {
+		return product, err
+	}
+
+	product, err = service.productRepository.CreateProduct(ctx, product)
+	if err != nil {
+		return product, errors.Wrap(err, "create product error")
+	}
+
+	return product, nil
+}
+
+// UpdateProduct replaces the name and price of the product, a new price has to be covered by
+// an active tier rule so orders of the product keep earning points
+func (service *productService) UpdateProduct(ctx context.Context, productId uint, name string, price float64) (model.Product, error) {
+	var product model.Product
+
+	err := service.transaction.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+
+		product, err = service.productRepository.GetProductById(ctx, productId)
+		if err != nil {
+			return errors.Wrap(err, "get product by id error")
+		}
+
+		priceChanged := product.Price != price
+		product.Name = strings.TrimSpace(name)
+		product.Price = price
+
+		err = validateProduct(product)
+		if err != nil {
+			return err
+		}
+
+		if priceChanged {
+			err = service.validatePriceTier(ctx, product.Price)
+			if err != nil {
+				return err
+			}
+		}
+
+		err = service.productRepository.UpdateProduct(ctx, product)
+		if err != nil {
+			return errors.Wrap(err, "update product error")
+		}
+
+		return nil
+	})
+
+	return product, err
+}
+
+// DeleteProduct soft deletes the product, it stays in the database for orders already processed
+func (service *productService) DeleteProduct(ctx context.Context, productId uint) error {
+	err := service.productRepository.DeleteProduct(ctx, productId)
+	if err != nil {
+		return errors.Wrap(err, "delete product error")
+	}
+
+	return nil
+}
+
+func (service *productService) validatePriceTier(ctx context.Context, price float64) error {
+	tierRules, err := service.tierRuleRepository.GetActiveTierRules(ctx, time.Now())
+	if err != nil {
+		return errors.Wrap(err, "get active tier rules error")
+	}
+
+	_, ok := selectTierRule(tierRules, price)
+	if !ok {
+		return fmt.Errorf("%w: no active tier rule covers price %v", ErrInvalidProduct, price)
+	}
+
+	return nil
+}
+
+func validateProduct(product model.Product) error {
+	if product.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidProduct)
+	}
+
+	if len(product.Name) > 255 {
+		return fmt.Errorf("%w: name must be at most 255 characters", ErrInvalidProduct)
+	}
+
+	if product.Price < 0 || math.IsNaN(product.Price) || math.IsInf(product.Price, 0) {
+		return fmt.Errorf("%w: price must be a number not less than 0", ErrInvalidProduct)
+	}
+
+	return nil
+}
@@ -0,0 +1,170 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"math"
+	"point-service/app/internal/model"
+	mockRepository "point-service/app/internal/repository/mocks"
+	"point-service/app/internal/service"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+)
+
+type ProductServiceTestSuite struct {
+	suite.Suite
+	productService service.ProductService
+
+	productRepository  *mockRepository.ProductRepository
+	tierRuleRepository *mockRepository.TierRuleRepository
+
+	ctxTierRuleError context.Context
+}
+
+func product(id uint, name string, price float64) model.Product {
+	product := model.Product{Name: name, Price: price}
+	product.ID = id
+
+	return product
+}
+
+func (suite *ProductServiceTestSuite) SetupTest() {
+	transaction := new(mockRepository.Transaction)
+	transaction.On("WithinTransaction", mock.Anything, mock.Anything).Return(func(ctx context.Context, fn func(context.Context) error) error {
+		return fn(ctx)
+	})
+
+	productRepository := new(mockRepository.ProductRepository)
+	productRepository.On("ListProducts", mock.Anything, 0, 20).Return([]model.Product{product(1, "car", 77)}, int64(1), nil)
+	productRepository.On("ListProducts", mock.Anything, 100, 50).Return([]model.Product{}, int64(1), nil)
+	productRepository.On("ListProducts", mock.Anything, mock.Anything, mock.Anything).Return(nil, int64(0), errors.New("list error"))
+
+	productRepository.On("GetProductById", mock.Anything, uint(1)).Return(product(1, "car", 77), nil)
+	productRepository.On("GetProductById", mock.Anything, mock.Anything).Return(model.Product{}, gorm.ErrRecordNotFound)
+
+	productRepository.On("CreateProduct", mock.Anything, model.Product{Name: "bike", Price: 1000.5}).Return(product(2, "bike", 1000.5), nil)
+	productRepository.On("CreateProduct", mock.Anything, mock.Anything).Return(model.Product{}, errors.New("create error"))
+
+	productRepository.On("UpdateProduct", mock.Anything, mock.Anything).Return(nil)
+
+	productRepository.On("DeleteProduct", mock.Anything, uint(1)).Return(nil)
+	productRepository.On("DeleteProduct", mock.Anything, mock.Anything).Return(gorm.ErrRecordNotFound)
+
+	suite.ctxTierRuleError = context.WithValue(context.Background(), Key("error"), "tier rule")
+
+	tierRuleRepository := new(mockRepository.TierRuleRepository)
+	tierRuleRepository.On("GetActiveTierRules", suite.ctxTierRuleError, mock.Anything).Return(nil, errors.New("get tier rules error"))
+	tierRuleRepository.On("GetActiveTierRules", mock.Anything, mock.Anything).Return(service.DefaultTierRules(), nil)
+
+	suite.productRepository = productRepository
+	suite.tierRuleRepository = tierRuleRepository
+	suite.productService = service.NewProductService(transaction, productRepository, tierRuleRepository)
+}
+
+func (suite *ProductServiceTestSuite) TestProductService_HappyCase_ListProductsDefaultPage() {
+	page, err := suite.productService.ListProducts(context.Background(), 0, 0)
+	suite.Nil(err)
+	suite.Equal(1, page.Page)
+	suite.Equal(service.DefaultPageSize, page.PageSize)
+	suite.Equal(int64(1), page.Total)
+	suite.Len(page.Products, 1)
+}
+
+func (suite *ProductServiceTestSuite) TestProductService_HappyCase_ListProductsOffset() {
+	page, err := suite.productService.ListProducts(context.Background(), 3, 50)
+	suite.Nil(err)
+	suite.Equal(3, page.Page)
+	suite.Empty(page.Products)
+}
+
+func (suite *ProductServiceTestSuite) TestProductService_ListProductsInvalidPage() {
+	for _, pageSize := range []int{-1, service.MaxPageSize + 1} {
+		_, err := suite.productService.ListProducts(context.Background(), 1, pageSize)
+		suite.ErrorIs(err, service.ErrInvalidPage)
+	}
+
+	_, err := suite.productService.ListProducts(context.Background(), -1, 10)
+	suite.ErrorIs(err, service.ErrInvalidPage)
+	suite.productRepository.AssertNotCalled(suite.T(), "ListProducts", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *ProductServiceTestSuite) TestProductService_ListProductsError() {
+	_, err := suite.productService.ListProducts(context.Background(), 2, 10)
+	suite.ErrorContains(err, "list error")
+}
+
+func (suite *ProductServiceTestSuite) TestProductService_GetProductNotFound() {
+	_, err := suite.productService.GetProduct(context.Background(), 9)
+	suite.ErrorIs(err, gorm.ErrRecordNotFound)
+}
+
+func (suite *ProductServiceTestSuite) TestProductService_HappyCase_CreateProduct() {
+	product, err := suite.productService.CreateProduct(context.Background(), "  bike ", 1000.5)
+	suite.Nil(err)
+	suite.Equal(uint(2), product.ID)
+}
+
+func (suite *ProductServiceTestSuite) TestProductService_CreateProductInvalid() {
+	for _, invalid := range []model.Product{
+		{Name: " ", Price: 10},
+		{Name: "car", Price: -1},
+		{Name: "car", Price: math.Inf(1)},
+		{Name: "free", Price: 0},
+	} {
+		_, err := suite.productService.CreateProduct(context.Background(), invalid.Name, invalid.Price)
+		suite.ErrorIs(err, service.ErrInvalidProduct, invalid.Name)
+	}
+
+	suite.productRepository.AssertNotCalled(suite.T(), "CreateProduct", mock.Anything, mock.Anything)
+}
+
+func (suite *ProductServiceTestSuite) TestProductService_CreateProductTierRuleError() {
+	_, err := suite.productService.CreateProduct(suite.ctxTierRuleError, "bike", 1000.5)
+	suite.ErrorContains(err, "get tier rules error")
+}
+
+func (suite *ProductServiceTestSuite) TestProductService_CreateProductError() {
+	_, err := suite.productService.CreateProduct(context.Background(), "car", 77)
+	suite.ErrorContains(err, "create error")
+}
+
+func (suite *ProductServiceTestSuite) TestProductService_HappyCase_UpdateProduct() {
+	updated, err := suite.productService.UpdateProduct(context.Background(), 1, "sports car", 1500)
+	suite.Nil(err)
+	suite.Equal(product(1, "sports car", 1500), updated)
+	suite.productRepository.AssertCalled(suite.T(), "UpdateProduct", mock.Anything, product(1, "sports car", 1500))
+}
+
+func (suite *ProductServiceTestSuite) TestProductService_UpdateProductSamePriceSkipsTierCheck() {
+	_, err := suite.productService.UpdateProduct(suite.ctxTierRuleError, 1, "old car", 77)
+	suite.Nil(err)
+	suite.tierRuleRepository.AssertNotCalled(suite.T(), "GetActiveTierRules", mock.Anything, mock.Anything)
+}
+
+func (suite *ProductServiceTestSuite) TestProductService_UpdateProductUncoveredPrice() {
+	_, err := suite.productService.UpdateProduct(context.Background(), 1, "car", 0)
+	suite.ErrorIs(err, service.ErrInvalidProduct)
+	suite.productRepository.AssertNotCalled(suite.T(), "UpdateProduct", mock.Anything, mock.Anything)
+}
+
+func (suite *ProductServiceTestSuite) TestProductService_UpdateProductNotFound() {
+	_, err := suite.productService.UpdateProduct(context.Background(), 9, "car", 77)
+	suite.ErrorIs(err, gorm.ErrRecordNotFound)
+}
+
+func (suite *ProductServiceTestSuite) TestProductService_HappyCase_DeleteProduct() {
+	err := suite.productService.DeleteProduct(context.Background(), 1)
+	suite.Nil(err)
+}
+
+func (suite *ProductServiceTestSuite) TestProductService_DeleteProductNotFound() {
+	err := suite.productService.DeleteProduct(context.Background(), 9)
+	suite.ErrorIs(err, gorm.ErrRecordNotFound)
+}
+
+func TestProductServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(ProductServiceTestSuite))
+}
@@ -0,0 +1,301 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"point-service/app/internal/model"
+	"point-service/app/internal/repository"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidReservation = errors.New("invalid reservation")
+	ErrReservationExists  = errors.New("reservation of the order already exists")
+	// ErrReservationNotPending is a reservation the user can no longer release
+	ErrReservationNotPending = errors.New("reservation is not pending")
+)
+
+type RedemptionService interface {
+	Run(ctx context.Context)
+	Reserve(ctx context.Context, userId uint, orderId uint, level string, amount uint) (model.PointReservation, error)
+	GetReservation(ctx context.Context, userId uint, orderId uint) (model.PointReservation, error)
+	Release(ctx context.Context, userId uint, orderId uint) (model.PointReservation, error)
+	Confirm(ctx context.Context, userId uint, orderId uint) (model.PointReservation, error)
+	Cancel(ctx context.Context, orderId uint) error
+	ReleaseExpired(ctx context.Context) error
+}
+
+type redemptionService struct {
+	transaction                repository.Transaction
+	userPointRepository        repository.UserPointRepository
+	pointReservationRepository repository.PointReservationRepository
+	reservationTtl             time.Duration
+	sweepInterval              time.Duration
+	sweepBatchSize             int
+}
+
+func NewRedemptionService(
+	transaction repository.Transaction,
+	userPointRepository repository.UserPointRepository,
+	pointReservationRepository repository.PointReservationRepository,
+	reservationTtl time.Duration,
+	sweepInterval time.Duration,
+	sweepBatchSize int,
+) RedemptionService {
+	return &redemptionService{
+		transaction:                transaction,
+		userPointRepository:        userPointRepository,
+		pointReservationRepository: pointReservationRepository,
+		reservationTtl:             reservationTtl,
+		sweepInterval:              sweepInterval,
+		sweepBatchSize:             sweepBatchSize,
+	}
+}
+
+// Run releases expired reservations until ctx is done
+func (service *redemptionService) Run(ctx context.Context) {
+	sweepTicker := time.NewTicker(service.sweepInterval)
+	defer sweepTicker.Stop()
+
+	for {
+		select {
+		case <-sweepTicker.C:
+			err := service.ReleaseExpired(ctx)
+			if err != nil {
+				log.Printf("release expired reservations error: %s", err.Error())
+			}
+
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Reserve takes amount points of the level from the user balance for the checkout of the order.
+// Reserving the same points for the same order again returns the pending reservation, reserving
+// other points for it is ErrReservationExists.
+func (service *redemptionService) Reserve(ctx context.Context, userId uint, orderId uint, level string, amount uint) (model.PointReservation, error) {
+	reservation := model.PointReservation{
+		UserId:  userId,
+		OrderId: orderId,
+		Level:   strings.TrimSpace(level),
+		Amount:  amount,
+		Status:  model.ReservationPending,
+	}
+
+	err := validateReservation(reservation)
+	if err != nil {
+		return reservation, err
+	}
+
+	err = service.transaction.WithinTransaction(ctx, func(ctx context.Context) error {
+		existing, err := service.pointReservationRepository.GetReservation(ctx, userId, orderId)
+		if err == nil {
+			if existing.Status != model.ReservationPending || existing.Level != reservation.Level || existing.Amount != reservation.Amount {
+				return ErrReservationExists
+			}
+
+			reservation = existing
+			return nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.Wrap(err, "get reservation error")
+		}
+
+		err = service.userPointRepository.Reserve(ctx, userId, reservation.Level, orderId, amount)
+		if err != nil {
+			return errors.Wrapf(err, "reserve user %s point error", reservation.Level)
+		}
+
+		reservation.ExpiresAt = time.Now().Add(service.reservationTtl)
+		reservation, err = service.pointReservationRepository.CreateReservation(ctx, reservation)
+		if err != nil {
+			return errors.Wrap(err, "create reservation error")
+		}
+
+		return nil
+	})
+
+	return reservation, err
+}
+
+func (service *redemptionService) GetReservation(ctx context.Context, userId uint, orderId uint) (model.PointReservation, error) {
+	reservation, err := service.pointReservationRepository.GetReservation(ctx, userId, orderId)
+	if err != nil {
+		return reservation, errors.Wrap(err, "get reservation error")
+	}
+
+	return reservation, nil
+}
+
+// Release gives the points of a pending reservation of the user back when the checkout is abandoned.
+// The points of any other reservation only move with the events of its order, releasing it is
+// ErrReservationNotPending.
+func (service *redemptionService) Release(ctx context.Context, userId uint, orderId uint) (model.PointReservation, error) {
+	var reservation model.PointReservation
+
+	err := service.transaction.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+
+		reservation, err = service.pointReservationRepository.GetReservation(ctx, userId, orderId)
+		if err != nil {
+			return errors.Wrap(err, "get reservation error")
+		}
+
+		if reservation.Status != model.ReservationPending {
+			return fmt.Errorf("%w: reservation is %s", ErrReservationNotPending, reservation.Status)
+		}
+
+		return service.release(ctx, &reservation, model.ReservationCancelled)
+	})
+
+	return reservation, err
+}
+
+// Confirm spends the points the user reserved for the order when it succeeded. Points of a
+// reservation that expired before the order succeeded are taken from the balance again, when the
+// user no longer has them, or the reservation was cancelled, it is left in conflict instead of
+// failing the order. Confirming a reservation again does nothing.
+func (service *redemptionService) Confirm(ctx context.Context, userId uint, orderId uint) (model.PointReservation, error) {
+	var reservation model.PointReservation
+
+	err := service.transaction.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+
+		reservation, err = service.pointReservationRepository.GetReservation(ctx, userId, orderId)
+		if err != nil {
+			return errors.Wrap(err, "get reservation error")
+		}
+
+		switch reservation.Status {
+		case model.ReservationPending:
+			return service.updateStatus(ctx, &reservation, model.ReservationConfirmed)
+		case model.ReservationExpired:
+			err = service.userPointRepository.Redeem(ctx, reservation.UserId, reservation.Level, reservation.OrderId, reservation.Amount)
+			if errors.Is(err, repository.ErrNotEnoughBalance) {
+				log.Printf("reservation of order %d expired and user %d no longer has %d %s points, it is left in conflict",
+					orderId, reservation.UserId, reservation.Amount, reservation.Level)
+				return service.updateStatus(ctx, &reservation, model.ReservationConflict)
+			}
+			if err != nil {
+				return errors.Wrapf(err, "redeem user %s point error", reservation.Level)
+			}
+
+			return service.updateStatus(ctx, &reservation, model.ReservationConfirmed)
+		case model.ReservationCancelled:
+			log.Printf("reservation of order %d was cancelled before the order succeeded, it is left in conflict", orderId)
+			return service.updateStatus(ctx, &reservation, model.ReservationConflict)
+		default:
+			return nil
+		}
+	})
+
+	return reservation, err
+}
+
+// Cancel gives back the points reserved for the cancelled order by every user, the points of a
+// confirmed reservation are refunded. A reservation that is already cancelled, expired or refunded
+// is left as it is, and an order without reservation does nothing.
+func (service *redemptionService) Cancel(ctx context.Context, orderId uint) error {
+	return service.transaction.WithinTransaction(ctx, func(ctx context.Context) error {
+		reservations, err := service.pointReservationRepository.GetReservationsByOrderId(ctx, orderId)
+		if err != nil {
+			return errors.Wrap(err, "get reservations by order id error")
+		}
+
+		for i := range reservations {
+			err = service.cancel(ctx, &reservations[i])
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// ReleaseExpired gives back the points of one batch of pending reservations past their expiry
+func (service *redemptionService) ReleaseExpired(ctx context.Context) error {
+	return service.transaction.WithinTransaction(ctx, func(ctx context.Context) error {
+		reservations, err := service.pointReservationRepository.GetExpiredReservations(ctx, time.Now(), service.sweepBatchSize)
+		if err != nil {
+			return errors.Wrap(err, "get expired reservations error")
+		}
+
+		for i := range reservations {
+			err = service.release(ctx, &reservations[i], model.ReservationExpired)
+			if err != nil {
+				return err
+			}
+		}
+
+		if len(reservations) > 0 {
+			log.Printf("released %d expired reservations", len(reservations))
+		}
+
+		return nil
+	})
+}
+
+func (service *redemptionService) cancel(ctx context.Context, reservation *model.PointReservation) error {
+	switch reservation.Status {
+	case model.ReservationPending:
+		return service.release(ctx, reservation, model.ReservationCancelled)
+	case model.ReservationConfirmed:
+		err := service.userPointRepository.Refund(ctx, reservation.UserId, reservation.Level, reservation.OrderId, reservation.Amount)
+		if err != nil {
+			return errors.Wrapf(err, "refund user %s point error", reservation.Level)
+		}
+
+		return service.updateStatus(ctx, reservation, model.ReservationRefunded)
+	case model.ReservationConflict:
+		// the points of a reservation in conflict were never spent
+		return service.updateStatus(ctx, reservation, model.ReservationCancelled)
+	default:
+		return nil
+	}
+}
+
+func (service *redemptionService) release(ctx context.Context, reservation *model.PointReservation, status string) error {
+	err := service.userPointRepository.Release(ctx, reservation.UserId, reservation.Level, reservation.OrderId, reservation.Amount)
+	if err != nil {
+		return errors.Wrapf(err, "release user %s point error", reservation.Level)
+	}
+
+	return service.updateStatus(ctx, reservation, status)
+}
+
+func (service *redemptionService) updateStatus(ctx context.Context, reservation *model.PointReservation, status string) error {
+	err := service.pointReservationRepository.UpdateReservationStatus(ctx, reservation.ID, status)
+	if err != nil {
+		return errors.Wrap(err, "update reservation status error")
+	}
+	reservation.Status = status
+
+	return nil
+}
+
+func validateReservation(reservation model.PointReservation) error {
+	if reservation.UserId == 0 {
+		return fmt.Errorf("%w: user id is required", ErrInvalidReservation)
+	}
+
+	if reservation.OrderId == 0 {
+		return fmt.Errorf("%w: order id is required", ErrInvalidReservation)
+	}
+
+	if !pointLevelPattern.MatchString(reservation.Level) {
+		return fmt.Errorf("%w: level is invalid", ErrInvalidReservation)
+	}
+
+	if reservation.Amount == 0 {
+		return fmt.Errorf("%w: amount must be greater than 0", ErrInvalidReservation)
+	}
+
+	return nil
+}
@@ -0,0 +1,281 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"point-service/app/internal/model"
+	"point-service/app/internal/repository"
+	mockRepository "point-service/app/internal/repository/mocks"
+	"point-service/app/internal/service"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+)
+
+type RedemptionServiceTestSuite struct {
+	suite.Suite
+	redemptionService service.RedemptionService
+
+	userPointRepository        *mockRepository.UserPointRepository
+	pointReservationRepository *mockRepository.PointReservationRepository
+
+	ctxExpiredError context.Context
+}
+
+func reservation(id uint, orderId uint, status string) model.PointReservation {
+	reservation := model.PointReservation{UserId: 42, OrderId: orderId, Level: "gold", Amount: 5, Status: status}
+	reservation.ID = id
+
+	return reservation
+}
+
+func (suite *RedemptionServiceTestSuite) SetupTest() {
+	transaction := new(mockRepository.Transaction)
+	transaction.On("WithinTransaction", mock.Anything, mock.Anything).Return(func(ctx context.Context, fn func(context.Context) error) error {
+		return fn(ctx)
+	})
+
+	userPointRepository := new(mockRepository.UserPointRepository)
+	userPointRepository.On("Reserve", mock.Anything, uint(42), "gold", uint(10), uint(5)).Return(nil)
+	userPointRepository.On("Reserve", mock.Anything, uint(42), "gold", uint(10), uint(500)).Return(repository.ErrNotEnoughBalance)
+	userPointRepository.On("Reserve", mock.Anything, uint(43), "gold", uint(1), uint(5)).Return(nil)
+	userPointRepository.On("Release", mock.Anything, mock.Anything, mock.Anything, uint(4), mock.Anything).Return(errors.New("release error"))
+	userPointRepository.On("Release", mock.Anything, mock.Anything, "gold", mock.Anything, uint(5)).Return(nil)
+	userPointRepository.On("Redeem", mock.Anything, mock.Anything, mock.Anything, uint(12), mock.Anything).Return(repository.ErrNotEnoughBalance)
+	userPointRepository.On("Redeem", mock.Anything, uint(42), "gold", mock.Anything, uint(5)).Return(nil)
+	userPointRepository.On("Refund", mock.Anything, uint(42), "gold", mock.Anything, uint(5)).Return(nil)
+
+	pointReservationRepository := new(mockRepository.PointReservationRepository)
+	for _, fixture := range []model.PointReservation{
+		reservation(1, 1, model.ReservationPending),
+		reservation(2, 2, model.ReservationConfirmed),
+		reservation(3, 3, model.ReservationCancelled),
+		reservation(4, 4, model.ReservationPending),
+		reservation(11, 11, model.ReservationExpired),
+		reservation(12, 12, model.ReservationExpired),
+		reservation(13, 13, model.ReservationConflict),
+	} {
+		pointReservationRepository.On("GetReservation", mock.Anything, uint(42), fixture.OrderId).Return(fixture, nil)
+		pointReservationRepository.On("GetReservationsByOrderId", mock.Anything, fixture.OrderId).Return([]model.PointReservation{fixture}, nil)
+	}
+	pointReservationRepository.On("GetReservation", mock.Anything, uint(42), uint(5)).Return(model.PointReservation{}, errors.New("select error"))
+	pointReservationRepository.On("GetReservation", mock.Anything, mock.Anything, mock.Anything).Return(model.PointReservation{}, gorm.ErrRecordNotFound)
+	// order 14 was reserved by two users, user 42 spent the points
+	otherUser := reservation(15, 14, model.ReservationPending)
+	otherUser.UserId = 43
+	pointReservationRepository.On("GetReservationsByOrderId", mock.Anything, uint(14)).Return([]model.PointReservation{reservation(14, 14, model.ReservationConfirmed), otherUser}, nil)
+	pointReservationRepository.On("GetReservationsByOrderId", mock.Anything, uint(5)).Return(nil, errors.New("select error"))
+	pointReservationRepository.On("GetReservationsByOrderId", mock.Anything, mock.Anything).Return(nil, nil)
+
+	pointReservationRepository.On("CreateReservation", mock.Anything, mock.Anything).Return(func(ctx context.Context, reservation model.PointReservation) (model.PointReservation, error) {
+		reservation.ID = 7
+		return reservation, nil
+	})
+
+	pointReservationRepository.On("UpdateReservationStatus", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	suite.ctxExpiredError = context.WithValue(context.Background(), Key("error"), "expired")
+	pointReservationRepository.On("GetExpiredReservations", suite.ctxExpiredError, mock.Anything, 100).Return(nil, errors.New("select error"))
+	pointReservationRepository.On("GetExpiredReservations", mock.Anything, mock.Anything, 100).Return([]model.PointReservation{reservation(1, 1, model.ReservationPending), reservation(6, 6, model.ReservationPending)}, nil)
+
+	suite.userPointRepository = userPointRepository
+	suite.pointReservationRepository = pointReservationRepository
+	suite.redemptionService = service.NewRedemptionService(transaction, userPointRepository, pointReservationRepository, time.Minute*15, time.Minute, 100)
+}
+
+func (suite *RedemptionServiceTestSuite) TestRedemptionService_HappyCase_Reserve() {
+	reservation, err := suite.redemptionService.Reserve(context.Background(), 42, 10, " gold ", 5)
+	suite.Nil(err)
+	suite.Equal(uint(7), reservation.ID)
+	suite.Equal(model.ReservationPending, reservation.Status)
+	suite.WithinDuration(time.Now().Add(time.Minute*15), reservation.ExpiresAt, time.Second)
+	suite.userPointRepository.AssertCalled(suite.T(), "Reserve", mock.Anything, uint(42), "gold", uint(10), uint(5))
+}
+
+func (suite *RedemptionServiceTestSuite) TestRedemptionService_HappyCase_ReserveAgain() {
+	reservation, err := suite.redemptionService.Reserve(context.Background(), 42, 1, "gold", 5)
+	suite.Nil(err)
+	suite.Equal(uint(1), reservation.ID)
+	suite.userPointRepository.AssertNotCalled(suite.T(), "Reserve", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *RedemptionServiceTestSuite) TestRedemptionService_ReserveExists() {
+	for _, orderId := range []uint{1, 2} {
+		_, err := suite.redemptionService.Reserve(context.Background(), 42, orderId, "gold", 3)
+		suite.ErrorIs(err, service.ErrReservationExists)
+	}
+}
+
+func (suite *RedemptionServiceTestSuite) TestRedemptionService_HappyCase_ReserveOrderOfOtherUser() {
+	// the reservation of user 42 for order 1 does not block another user
+	reservation, err := suite.redemptionService.Reserve(context.Background(), 43, 1, "gold", 5)
+	suite.Nil(err)
+	suite.Equal(uint(7), reservation.ID)
+	suite.Equal(uint(43), reservation.UserId)
+	suite.userPointRepository.AssertCalled(suite.T(), "Reserve", mock.Anything, uint(43), "gold", uint(1), uint(5))
+}
+
+func (suite *RedemptionServiceTestSuite) TestRedemptionService_ReserveNotEnoughBalance() {
+	_, err := suite.redemptionService.Reserve(context.Background(), 42, 10, "gold", 500)
+	suite.ErrorIs(err, repository.ErrNotEnoughBalance)
+	suite.pointReservationRepository.AssertNotCalled(suite.T(), "CreateReservation", mock.Anything, mock.Anything)
+}
+
+func (suite *RedemptionServiceTestSuite) TestRedemptionService_ReserveInvalid() {
+	for _, invalid := range []model.PointReservation{
+		{UserId: 0, OrderId: 10, Level: "gold", Amount: 5},
+		{UserId: 42, OrderId: 0, Level: "gold", Amount: 5},
+		{UserId: 42, OrderId: 10, Level: "Gold", Amount: 5},
+		{UserId: 42, OrderId: 10, Level: "gold", Amount: 0},
+	} {
+		_, err := suite.redemptionService.Reserve(context.Background(), invalid.UserId, invalid.OrderId, invalid.Level, invalid.Amount)
+		suite.ErrorIs(err, service.ErrInvalidReservation)
+	}
+
+	suite.pointReservationRepository.AssertNotCalled(suite.T(), "GetReservation", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *RedemptionServiceTestSuite) TestRedemptionService_GetReservationNotFound() {
+	_, err := suite.redemptionService.GetReservation(context.Background(), 42, 9)
+	suite.ErrorIs(err, gorm.ErrRecordNotFound)
+}
+
+func (suite *RedemptionServiceTestSuite) TestRedemptionService_HappyCase_Confirm() {
+	reservation, err := suite.redemptionService.Confirm(context.Background(), 42, 1)
+	suite.Nil(err)
+	suite.Equal(model.ReservationConfirmed, reservation.Status)
+	suite.pointReservationRepository.AssertCalled(suite.T(), "UpdateReservationStatus", mock.Anything, uint(1), model.ReservationConfirmed)
+}
+
+func (suite *RedemptionServiceTestSuite) TestRedemptionService_HappyCase_ConfirmTwice() {
+	_, err := suite.redemptionService.Confirm(context.Background(), 42, 2)
+	suite.Nil(err)
+	suite.pointReservationRepository.AssertNotCalled(suite.T(), "UpdateReservationStatus", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *RedemptionServiceTestSuite) TestRedemptionService_ConfirmCancelled() {
+	reservation, err := suite.redemptionService.Confirm(context.Background(), 42, 3)
+	suite.Nil(err)
+	suite.Equal(model.ReservationConflict, reservation.Status)
+	suite.pointReservationRepository.AssertCalled(suite.T(), "UpdateReservationStatus", mock.Anything, uint(3), model.ReservationConflict)
+}
+
+func (suite *RedemptionServiceTestSuite) TestRedemptionService_HappyCase_ConfirmExpired() {
+	reservation, err := suite.redemptionService.Confirm(context.Background(), 42, 11)
+	suite.Nil(err)
+	suite.Equal(model.ReservationConfirmed, reservation.Status)
+	suite.userPointRepository.AssertCalled(suite.T(), "Redeem", mock.Anything, uint(42), "gold", uint(11), uint(5))
+	suite.pointReservationRepository.AssertCalled(suite.T(), "UpdateReservationStatus", mock.Anything, uint(11), model.ReservationConfirmed)
+}
+
+func (suite *RedemptionServiceTestSuite) TestRedemptionService_ConfirmExpiredNotEnoughBalance() {
+	reservation, err := suite.redemptionService.Confirm(context.Background(), 42, 12)
+	suite.Nil(err)
+	suite.Equal(model.ReservationConflict, reservation.Status)
+	suite.pointReservationRepository.AssertCalled(suite.T(), "UpdateReservationStatus", mock.Anything, uint(12), model.ReservationConflict)
+}
+
+func (suite *RedemptionServiceTestSuite) TestRedemptionService_ConfirmNotFound() {
+	_, err := suite.redemptionService.Confirm(context.Background(), 42, 9)
+	suite.ErrorIs(err, gorm.ErrRecordNotFound)
+}
+
+func (suite *RedemptionServiceTestSuite) TestRedemptionService_HappyCase_Release() {
+	reservation, err := suite.redemptionService.Release(context.Background(), 42, 1)
+	suite.Nil(err)
+	suite.Equal(model.ReservationCancelled, reservation.Status)
+	suite.userPointRepository.AssertCalled(suite.T(), "Release", mock.Anything, uint(42), "gold", uint(1), uint(5))
+	suite.pointReservationRepository.AssertCalled(suite.T(), "UpdateReservationStatus", mock.Anything, uint(1), model.ReservationCancelled)
+}
+
+func (suite *RedemptionServiceTestSuite) TestRedemptionService_ReleaseNotPending() {
+	// spent points only come back with the cancellation of the order
+	for _, orderId := range []uint{2, 3, 11, 13} {
+		_, err := suite.redemptionService.Release(context.Background(), 42, orderId)
+		suite.ErrorIs(err, service.ErrReservationNotPending)
+	}
+
+	suite.userPointRepository.AssertNotCalled(suite.T(), "Refund", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	suite.userPointRepository.AssertNotCalled(suite.T(), "Release", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	suite.pointReservationRepository.AssertNotCalled(suite.T(), "UpdateReservationStatus", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *RedemptionServiceTestSuite) TestRedemptionService_ReleaseNotFound() {
+	_, err := suite.redemptionService.Release(context.Background(), 43, 1)
+	suite.ErrorIs(err, gorm.ErrRecordNotFound)
+	suite.userPointRepository.AssertNotCalled(suite.T(), "Release", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *RedemptionServiceTestSuite) TestRedemptionService_HappyCase_Cancel() {
+	err := suite.redemptionService.Cancel(context.Background(), 1)
+	suite.Nil(err)
+	suite.userPointRepository.AssertCalled(suite.T(), "Release", mock.Anything, uint(42), "gold", uint(1), uint(5))
+	suite.pointReservationRepository.AssertCalled(suite.T(), "UpdateReservationStatus", mock.Anything, uint(1), model.ReservationCancelled)
+}
+
+func (suite *RedemptionServiceTestSuite) TestRedemptionService_HappyCase_CancelTwice() {
+	err := suite.redemptionService.Cancel(context.Background(), 3)
+	suite.Nil(err)
+	suite.userPointRepository.AssertNotCalled(suite.T(), "Release", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *RedemptionServiceTestSuite) TestRedemptionService_HappyCase_CancelConfirmed() {
+	err := suite.redemptionService.Cancel(context.Background(), 2)
+	suite.Nil(err)
+	suite.userPointRepository.AssertCalled(suite.T(), "Refund", mock.Anything, uint(42), "gold", uint(2), uint(5))
+	suite.pointReservationRepository.AssertCalled(suite.T(), "UpdateReservationStatus", mock.Anything, uint(2), model.ReservationRefunded)
+}
+
+func (suite *RedemptionServiceTestSuite) TestRedemptionService_HappyCase_CancelConflict() {
+	err := suite.redemptionService.Cancel(context.Background(), 13)
+	suite.Nil(err)
+	suite.pointReservationRepository.AssertCalled(suite.T(), "UpdateReservationStatus", mock.Anything, uint(13), model.ReservationCancelled)
+	suite.userPointRepository.AssertNotCalled(suite.T(), "Release", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *RedemptionServiceTestSuite) TestRedemptionService_HappyCase_CancelEveryUser() {
+	err := suite.redemptionService.Cancel(context.Background(), 14)
+	suite.Nil(err)
+	suite.userPointRepository.AssertCalled(suite.T(), "Refund", mock.Anything, uint(42), "gold", uint(14), uint(5))
+	suite.userPointRepository.AssertCalled(suite.T(), "Release", mock.Anything, uint(43), "gold", uint(14), uint(5))
+	suite.pointReservationRepository.AssertCalled(suite.T(), "UpdateReservationStatus", mock.Anything, uint(14), model.ReservationRefunded)
+	suite.pointReservationRepository.AssertCalled(suite.T(), "UpdateReservationStatus", mock.Anything, uint(15), model.ReservationCancelled)
+}
+
+func (suite *RedemptionServiceTestSuite) TestRedemptionService_HappyCase_CancelWithoutReservation() {
+	err := suite.redemptionService.Cancel(context.Background(), 9)
+	suite.Nil(err)
+	suite.pointReservationRepository.AssertNotCalled(suite.T(), "UpdateReservationStatus", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *RedemptionServiceTestSuite) TestRedemptionService_CancelReleaseError() {
+	err := suite.redemptionService.Cancel(context.Background(), 4)
+	suite.ErrorContains(err, "release error")
+	suite.pointReservationRepository.AssertNotCalled(suite.T(), "UpdateReservationStatus", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *RedemptionServiceTestSuite) TestRedemptionService_CancelGetError() {
+	err := suite.redemptionService.Cancel(context.Background(), 5)
+	suite.ErrorContains(err, "select error")
+}
+
+func (suite *RedemptionServiceTestSuite) TestRedemptionService_HappyCase_ReleaseExpired() {
+	err := suite.redemptionService.ReleaseExpired(context.Background())
+	suite.Nil(err)
+	suite.userPointRepository.AssertNumberOfCalls(suite.T(), "Release", 2)
+	suite.pointReservationRepository.AssertCalled(suite.T(), "UpdateReservationStatus", mock.Anything, uint(1), model.ReservationExpired)
+	suite.pointReservationRepository.AssertCalled(suite.T(), "UpdateReservationStatus", mock.Anything, uint(6), model.ReservationExpired)
+}
+
+func (suite *RedemptionServiceTestSuite) TestRedemptionService_ReleaseExpiredError() {
+	err := suite.redemptionService.ReleaseExpired(suite.ctxExpiredError)
+	suite.ErrorContains(err, "select error")
+	suite.userPointRepository.AssertNotCalled(suite.T(), "Release", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestRedemptionServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(RedemptionServiceTestSuite))
+}
@@ -0,0 +1,163 @@
+package service
+
+import (
+	"fmt"
+	"point-service/app/internal/constant"
+	"point-service/app/internal/model"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// ErrInvalidTierRules is a configuration error, an order is retried until the rules are fixed
+var ErrInvalidTierRules = errors.New("invalid tier rules")
+
+// DefaultTierRules covers every positive price: bronze up to 100, silver up to 1000 and gold above
+func DefaultTierRules() []model.TierRule {
+	bronzeMax, silverMax := 100.0, 1000.0
+
+	return []model.TierRule{
+		{Level: constant.BRONZE, MinPrice: 0, MinInclusive: false, MaxPrice: &bronzeMax, MaxInclusive: true},
+		{Level: constant.SILVER, MinPrice: bronzeMax, MinInclusive: false, MaxPrice: &silverMax, MaxInclusive: true},
+		{Level: constant.GOLD, MinPrice: silverMax, MinInclusive: false},
+	}
+}
+
+// ValidateTierRules checks that every positive price is covered by a rule and that
+// overlapping rules are told apart by their priority
+func ValidateTierRules(tierRules []model.TierRule) error {
+	var problems []string
+
+	for _, rule := range tierRules {
+		if rule.Level == "" {
+			problems = append(problems, fmt.Sprintf("rule %s has no level", describeTierRule(rule)))
+		}
+		if rule.MinPrice < 0 {
+			problems = append(problems, fmt.Sprintf("rule %s has a negative min price", describeTierRule(rule)))
+		}
+		if rule.MaxPrice != nil && !lowerBelowUpper(rule, rule) {
+			problems = append(problems, fmt.Sprintf("rule %s has an empty price range", describeTierRule(rule)))
+		}
+	}
+
+	// rules of the same priority must not share a price
+	for i := 0; i < len(tierRules); i++ {
+		for j := i + 1; j < len(tierRules); j++ {
+			a, b := tierRules[i], tierRules[j]
+			if a.Priority == b.Priority && lowerBelowUpper(a, b) && lowerBelowUpper(b, a) {
+				problems = append(problems, fmt.Sprintf("rule %s overlaps rule %s", describeTierRule(a), describeTierRule(b)))
+			}
+		}
+	}
+
+	problems = append(problems, findTierRuleGaps(tierRules)...)
+
+	if len(problems) > 0 {
+		return fmt.Errorf("%w: %s", ErrInvalidTierRules, strings.Join(problems, "; "))
+	}
+
+	return nil
+}
+
+// validTierRules remembers the last rules that passed ValidateTierRules, the same rules loaded for the
+// next order are only compared instead of validated again
+type validTierRules struct {
+	mutex     sync.Mutex
+	tierRules []model.TierRule
+}
+
+func (valid *validTierRules) validate(tierRules []model.TierRule) error {
+	valid.mutex.Lock()
+	defer valid.mutex.Unlock()
+
+	if valid.tierRules != nil && reflect.DeepEqual(valid.tierRules, tierRules) {
+		return nil
+	}
+
+	err := ValidateTierRules(tierRules)
+	if err != nil {
+		return err
+	}
+
+	valid.tierRules = tierRules
+	return nil
+}
+
+// selectTierRule returns the highest priority rule matching price
+func selectTierRule(tierRules []model.TierRule, price float64) (model.TierRule, bool) {
+	var selected model.TierRule
+	found := false
+
+	for _, rule := range tierRules {
+		if rule.Matches(price) && (!found || rule.Priority > selected.Priority) {
+			selected = rule
+			found = true
+		}
+	}
+
+	return selected, found
+}
+
+// findTierRuleGaps sweeps the rules by min price and reports the prices above 0 that no rule covers
+func findTierRuleGaps(tierRules []model.TierRule) []string {
+	sorted := append([]model.TierRule{}, tierRules...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].MinPrice != sorted[j].MinPrice {
+			return sorted[i].MinPrice < sorted[j].MinPrice
+		}
+
+		return sorted[i].MinInclusive && !sorted[j].MinInclusive
+	})
+
+	var gaps []string
+
+	// price 0 is not a valid price, so coverage starts right after it
+	reach, reachInclusive := 0.0, true
+	for _, rule := range sorted {
+		if rule.MinPrice > reach {
+			gaps = append(gaps, fmt.Sprintf("no rule covers prices between %v and %v", reach, rule.MinPrice))
+		}
+		if rule.MinPrice == reach && !reachInclusive && !rule.MinInclusive {
+			gaps = append(gaps, fmt.Sprintf("no rule covers price %v", reach))
+		}
+
+		if rule.MaxPrice == nil {
+			return gaps
+		}
+
+		if *rule.MaxPrice > reach || (*rule.MaxPrice == reach && rule.MaxInclusive) {
+			reach, reachInclusive = *rule.MaxPrice, rule.MaxInclusive
+		}
+	}
+
+	return append(gaps, fmt.Sprintf("no rule covers prices above %v", reach))
+}
+
+// lowerBelowUpper reports whether the lower bound of a is below the upper bound of b
+func lowerBelowUpper(a model.TierRule, b model.TierRule) bool {
+	if b.MaxPrice == nil {
+		return true
+	}
+
+	return a.MinPrice < *b.MaxPrice || (a.MinPrice == *b.MaxPrice && a.MinInclusive && b.MaxInclusive)
+}
+
+func describeTierRule(rule model.TierRule) string {
+	lower, upper := "(", ")"
+	if rule.MinInclusive {
+		lower = "["
+	}
+
+	max := "inf"
+	if rule.MaxPrice != nil {
+		max = fmt.Sprint(*rule.MaxPrice)
+		if rule.MaxInclusive {
+			upper = "]"
+		}
+	}
+
+	return fmt.Sprintf("%s %s%v, %s%s", rule.Level, lower, rule.MinPrice, max, upper)
+}
@@ -0,0 +1,87 @@
+package service_test
+
+import (
+	"point-service/app/internal/model"
+	"point-service/app/internal/service"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type TierRuleTestSuite struct {
+	suite.Suite
+}
+
+func (suite *TierRuleTestSuite) SetupTest() {}
+
+func price(value float64) *float64 {
+	return &value
+}
+
+func (suite *TierRuleTestSuite) TestValidateTierRules_HappyCase_Default() {
+	err := service.ValidateTierRules(service.DefaultTierRules())
+	suite.Nil(err)
+}
+
+func (suite *TierRuleTestSuite) TestValidateTierRules_HappyCase_PriorityOverride() {
+	tierRules := append(service.DefaultTierRules(), model.TierRule{
+		Level:        "platinum",
+		MinPrice:     5000,
+		MinInclusive: true,
+		Priority:     1,
+	})
+
+	err := service.ValidateTierRules(tierRules)
+	suite.Nil(err)
+}
+
+func (suite *TierRuleTestSuite) TestValidateTierRules_Empty() {
+	err := service.ValidateTierRules(nil)
+	suite.ErrorContains(err, "no rule covers prices above 0")
+}
+
+func (suite *TierRuleTestSuite) TestValidateTierRules_Gap() {
+	tierRules := []model.TierRule{
+		{Level: "bronze", MinPrice: 0, MaxPrice: price(100), MaxInclusive: true},
+		{Level: "silver", MinPrice: 101, MinInclusive: true},
+	}
+
+	err := service.ValidateTierRules(tierRules)
+	suite.ErrorContains(err, "no rule covers prices between 100 and 101")
+}
+
+func (suite *TierRuleTestSuite) TestValidateTierRules_BoundaryGap() {
+	tierRules := []model.TierRule{
+		{Level: "bronze", MinPrice: 0, MaxPrice: price(100)},
+		{Level: "silver", MinPrice: 100},
+	}
+
+	err := service.ValidateTierRules(tierRules)
+	suite.ErrorContains(err, "no rule covers price 100")
+}
+
+func (suite *TierRuleTestSuite) TestValidateTierRules_Overlap() {
+	tierRules := []model.TierRule{
+		{Level: "bronze", MinPrice: 0, MaxPrice: price(100), MaxInclusive: true},
+		{Level: "silver", MinPrice: 100, MinInclusive: true},
+	}
+
+	err := service.ValidateTierRules(tierRules)
+	suite.ErrorContains(err, "rule bronze (0, 100] overlaps rule silver [100, inf)")
+}
+
+func (suite *TierRuleTestSuite) TestValidateTierRules_InvalidRule() {
+	tierRules := []model.TierRule{
+		{Level: "", MinPrice: -1, MaxPrice: price(-2)},
+		{Level: "gold", MinPrice: 0},
+	}
+
+	err := service.ValidateTierRules(tierRules)
+	suite.ErrorContains(err, "has no level")
+	suite.ErrorContains(err, "has a negative min price")
+	suite.ErrorContains(err, "has an empty price range")
+}
+
+func TestTierRuleTestSuite(t *testing.T) {
+	suite.Run(t, new(TierRuleTestSuite))
+}
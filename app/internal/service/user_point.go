@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"point-service/app/internal/model"
+	"point-service/app/internal/repository"
+
+	"github.com/pkg/errors"
+)
+
+type UserPointBalance struct {
+	UserId uint
+	Total  uint
+	Levels []model.UserPoint
+}
+
+type UserPointLedgerPage struct {
+	Entries  []model.UserPointLedger
+	Page     int
+	PageSize int
+	Total    int64
+}
+
+type UserPointService interface {
+	GetUserPointBalance(ctx context.Context, userId uint) (UserPointBalance, error)
+	ListUserPointLedger(ctx context.Context, userId uint, page int, pageSize int) (UserPointLedgerPage, error)
+}
+
+type userPointService struct {
+	userPointRepository repository.UserPointRepository
+}
+
+func NewUserPointService(userPointRepository repository.UserPointRepository) UserPointService {
+	return &userPointService{
+		userPointRepository: userPointRepository,
+	}
+}
+
+// GetUserPointBalance returns the balance of every level of the user and their total,
+// a user without credit has a zero balance
+func (service *userPointService) GetUserPointBalance(ctx context.Context, userId uint) (UserPointBalance, error) {
+	userPoints, err := service.userPointRepository.GetUserPoints(ctx, userId)
+	if err != nil {
+		return UserPointBalance{}, errors.Wrap(err, "get user points error")
+	}
+
+	balance := UserPointBalance{
+		UserId: userId,
+		Levels: userPoints,
+	}
+	for _, userPoint := range userPoints {
+		balance.Total += userPoint.Balance
+	}
+
+	return balance, nil
+}
+
+func (service *userPointService) ListUserPointLedger(ctx context.Context, userId uint, page int, pageSize int) (UserPointLedgerPage, error) {
+	page, pageSize, offset, err := normalizePage(page, pageSize)
+	if err != nil {
+		return UserPointLedgerPage{}, err
+	}
+
+	entries, total, err := service.userPointRepository.ListLedger(ctx, userId, offset, pageSize)
+	if err != nil {
+		return UserPointLedgerPage{}, errors.Wrap(err, "list user point ledger error")
+	}
+
+	return UserPointLedgerPage{
+		Entries:  entries,
+		Page:     page,
+		PageSize: pageSize,
+		Total:    total,
+	}, nil
+}
@@ -0,0 +1,75 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"point-service/app/internal/model"
+	mockRepository "point-service/app/internal/repository/mocks"
+	"point-service/app/internal/service"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type UserPointServiceTestSuite struct {
+	suite.Suite
+	userPointService service.UserPointService
+
+	userPointRepository *mockRepository.UserPointRepository
+}
+
+func (suite *UserPointServiceTestSuite) SetupTest() {
+	userPointRepository := new(mockRepository.UserPointRepository)
+	userPointRepository.On("GetUserPoints", mock.Anything, uint(42)).Return([]model.UserPoint{{UserId: 42, Level: "bronze", Balance: 3}, {UserId: 42, Level: "gold", Balance: 7}}, nil)
+	userPointRepository.On("GetUserPoints", mock.Anything, uint(43)).Return([]model.UserPoint{}, nil)
+	userPointRepository.On("GetUserPoints", mock.Anything, mock.Anything).Return(nil, errors.New("select error"))
+
+	userPointRepository.On("ListLedger", mock.Anything, uint(42), 20, 20).Return([]model.UserPointLedger{{UserId: 42, OrderId: 10, Level: "gold", Type: model.LedgerCredit, Amount: 2, BalanceAfter: 7}}, int64(21), nil)
+	userPointRepository.On("ListLedger", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil, int64(0), errors.New("select error"))
+
+	suite.userPointRepository = userPointRepository
+	suite.userPointService = service.NewUserPointService(userPointRepository)
+}
+
+func (suite *UserPointServiceTestSuite) TestUserPointService_HappyCase_GetUserPointBalance() {
+	balance, err := suite.userPointService.GetUserPointBalance(context.Background(), 42)
+	suite.Nil(err)
+	suite.Equal(uint(42), balance.UserId)
+	suite.Equal(uint(10), balance.Total)
+	suite.Len(balance.Levels, 2)
+}
+
+func (suite *UserPointServiceTestSuite) TestUserPointService_HappyCase_GetUserPointBalanceEmpty() {
+	balance, err := suite.userPointService.GetUserPointBalance(context.Background(), 43)
+	suite.Nil(err)
+	suite.Equal(uint(0), balance.Total)
+}
+
+func (suite *UserPointServiceTestSuite) TestUserPointService_GetUserPointBalanceError() {
+	_, err := suite.userPointService.GetUserPointBalance(context.Background(), 44)
+	suite.ErrorContains(err, "select error")
+}
+
+func (suite *UserPointServiceTestSuite) TestUserPointService_HappyCase_ListUserPointLedger() {
+	ledgerPage, err := suite.userPointService.ListUserPointLedger(context.Background(), 42, 2, 0)
+	suite.Nil(err)
+	suite.Equal(2, ledgerPage.Page)
+	suite.Equal(int64(21), ledgerPage.Total)
+	suite.Len(ledgerPage.Entries, 1)
+}
+
+func (suite *UserPointServiceTestSuite) TestUserPointService_ListUserPointLedgerInvalidPage() {
+	_, err := suite.userPointService.ListUserPointLedger(context.Background(), 42, 1, 1000)
+	suite.ErrorIs(err, service.ErrInvalidPage)
+	suite.userPointRepository.AssertNotCalled(suite.T(), "ListLedger", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *UserPointServiceTestSuite) TestUserPointService_ListUserPointLedgerError() {
+	_, err := suite.userPointService.ListUserPointLedger(context.Background(), 43, 1, 20)
+	suite.ErrorContains(err, "select error")
+}
+
+func TestUserPointServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(UserPointServiceTestSuite))
+}
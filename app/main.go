@@ -3,9 +3,14 @@ package main
 import (
 	"context"
 	"errors"
+	"flag"
+	"fmt"
 	"log"
+	"log/slog"
+	"net/http"
 	"os"
 	"os/signal"
+	"point-service/app/internal/config"
 	"point-service/app/internal/handler"
 	"point-service/app/internal/model"
 	"point-service/app/internal/repository"
@@ -15,59 +20,277 @@ import (
 	"time"
 
 	"github.com/IBM/sarama"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
 
-var (
-	// postgresql config
-	dsn        = "host=localhost user=postgresusr password=1234 dbname=songvutdb port=5432 sslmode=disable TimeZone=Asia/Bangkok"
-	waitTime   = time.Millisecond * 100
-	maxAttempt = 1000
-
-	// kafka config
-	brokerAddress             = []string{"localhost:9092"}
-	consumerGroupId           = "point-service"
-	topicSuccessOrder         = "success.order"
-	topicDecreasePointSuccess = "decrease.point.success"
-)
-
 func main() {
+	// CONFIG
+	configPath := flag.String("config", os.Getenv("CONFIG_PATH"), "path to a yaml or json config file")
+	flag.Parse()
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		log.Panicf("load config error: %s", err.Error())
+	}
+	decreaseStrategy, err := repository.ParseDecreaseStrategy(cfg.Point.Strategy)
+	if err != nil {
+		log.Panicf("invalid config: point.strategy %s", err.Error())
+	}
+	tierPolicy, err := service.ParseTierPolicy(cfg.Point.TierPolicy)
+	if err != nil {
+		log.Panicf("invalid config: point.tier_policy %s", err.Error())
+	}
+	log.Println("load config success")
+
 	// DATABASE
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	db, err := gorm.Open(postgres.Open(cfg.Postgres.Dsn), &gorm.Config{})
 	if err != nil {
 		log.Panicf("connect to database error: %s", err.Error())
 	}
 	log.Println("connect database success")
 
-	err = db.AutoMigrate(&model.Point{}, &model.Product{})
+	err = db.AutoMigrate(&model.Point{}, &model.Product{}, &model.ProcessedOrder{}, &model.ProcessedOrderPoint{}, &model.Outbox{}, &model.TierRule{}, &model.UserPoint{}, &model.UserPointLedger{}, &model.PointReservation{})
 	if err != nil {
 		log.Panicf("auto migration error: %s", err.Error())
 	}
 	log.Println("database auto migration success")
 
+	logLevel := slog.LevelInfo
+	if cfg.Kafka.LogMessages {
+		logLevel = slog.LevelDebug
+	}
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: logLevel}))
+
+	// METRICS
+	metricsRegistry := prometheus.NewRegistry()
+	metricsRegistry.MustRegister(collectors.NewGoCollector(), collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+	kafkaMetrics := kafka.NewMetrics(metricsRegistry)
+
 	// KAFKA PRODUCER
-	producer, err := kafka.NewProducer(brokerAddress)
+	producer, err := kafka.NewProducer(cfg.Kafka.Brokers, cfg.Kafka.Partitioner)
 	if err != nil {
 		log.Panicf("new producer error: %s", err.Error())
 	}
+	producer = kafkaMetrics.Producer(producer)
+
+	// retry and dead letter messages must be written before their offset is marked, only the relay sends async
+	relayProducer := producer
+	if cfg.Kafka.Producer.Async {
+		relayProducer, err = kafka.NewAsyncProducer(cfg.Kafka.Brokers, cfg.Kafka.Partitioner, cfg.Kafka.Producer.Batching(), func(result kafka.DeliveryResult) {
+			if result.Err != nil {
+				kafkaMetrics.ProducerError(result.Topic)
+				logger.Error("deliver message error", slog.String("topic", result.Topic), slog.String("key", result.Key), slog.String("error", result.Err.Error()))
+			}
+		})
+		if err != nil {
+			log.Panicf("new async producer error: %s", err.Error())
+		}
+		relayProducer = kafkaMetrics.Producer(relayProducer)
+	}
 	log.Println("kafka producer is ready...")
 
+	// MESSAGE SCHEMAS
+	schemaRegistry := kafka.NewRegistry()
+	if cfg.Kafka.SchemaDir != "" {
+		schemaRegistry, err = kafka.NewFileRegistry(cfg.Kafka.SchemaDir)
+		if err != nil {
+			log.Panicf("load message schemas error: %s", err.Error())
+		}
+	}
+	log.Println("load message schemas success")
+
 	// REPOSITORY, SERVICE, HANDLER
+	transaction := repository.NewTransaction(db)
 	productRepository := repository.NewProductRepository(db)
-	pointRepository := repository.NewPointRepository(db, waitTime, uint(maxAttempt))
-	pointService := service.NewPointService(pointRepository, productRepository, producer, topicDecreasePointSuccess)
-	pointHandler := handler.NewPointHandler(pointService)
+	retries := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "point_optimistic_retries_total",
+		Help: "Optimistic lock retries of a point decrease by level.",
+	}, []string{"level"})
+	metricsRegistry.MustRegister(retries)
+	pointRepository := repository.NewPointRepository(db, decreaseStrategy, cfg.Point.WaitTime, cfg.Point.MaxAttempt, func(level string) {
+		retries.WithLabelValues(level).Inc()
+	})
+	tierRuleRepository := repository.NewTierRuleRepository(db)
+	processedOrderRepository := repository.NewProcessedOrderRepository(db)
+	outboxRepository := repository.NewOutboxRepository(db)
+	userPointRepository := repository.NewUserPointRepository(db)
+	pointReservationRepository := repository.NewPointReservationRepository(db)
+	pointService := service.NewPointService(
+		transaction,
+		pointRepository,
+		productRepository,
+		tierRuleRepository,
+		processedOrderRepository,
+		outboxRepository,
+		userPointRepository,
+		tierPolicy,
+		cfg.Kafka.Topics.DecreasePointSuccess,
+		cfg.Kafka.Topics.DecreasePointFailed,
+		cfg.Kafka.Topics.IncreasePointSuccess,
+		schemaRegistry.Encode,
+		kafka.CloudEvents{
+			Mode:   cfg.Kafka.CloudEvents.Mode,
+			Source: cfg.Kafka.CloudEvents.Source,
+			Types:  map[string]string{cfg.Kafka.Topics.DecreasePointSuccess: model.DecreasePointSuccessType},
+		},
+		service.NewPointMetrics(metricsRegistry, pointRepository),
+	)
+
+	// seed the default tier rules on an empty table, later changes are made in the database
+	tierRuleCount, err := tierRuleRepository.CountTierRules(context.Background())
+	if err != nil {
+		log.Panicf("count tier rules error: %s", err.Error())
+	}
+	if tierRuleCount == 0 {
+		for _, tierRule := range service.DefaultTierRules() {
+			err = tierRuleRepository.CreateTierRule(context.Background(), tierRule)
+			if err != nil {
+				log.Panicf("seed tier rule error: %s", err.Error())
+			}
+		}
+		log.Println("seed default tier rules success")
+	}
+	// a rule changed later fails the orders it affects as transient errors, until it is fixed
+	tierRules, err := tierRuleRepository.GetActiveTierRules(context.Background(), time.Now())
+	if err != nil {
+		log.Panicf("get active tier rules error: %s", err.Error())
+	}
+	err = service.ValidateTierRules(tierRules)
+	if err != nil {
+		log.Panicf("validate tier rules error: %s", err.Error())
+	}
+	redemptionService := service.NewRedemptionService(
+		transaction,
+		userPointRepository,
+		pointReservationRepository,
+		cfg.Redemption.ReservationTtl,
+		cfg.Redemption.SweepInterval,
+		cfg.Redemption.SweepBatchSize,
+	)
+	redemptionHandler := handler.NewRedemptionHandler(redemptionService)
+	pointHandler := handler.NewPointHandler(pointService, redemptionService)
+	pointPoolService := service.NewPointPoolService(transaction, pointRepository, tierRuleRepository)
+	pointPoolHandler := handler.NewPointPoolHandler(pointPoolService)
+	productService := service.NewProductService(transaction, productRepository, tierRuleRepository)
+	productHandler := handler.NewProductHandler(productService)
+	userPointService := service.NewUserPointService(userPointRepository)
+	userPointHandler := handler.NewUserPointHandler(userPointService)
+
+	// OUTBOX RELAY
+	outboxRelay := service.NewOutboxRelay(
+		transaction,
+		outboxRepository,
+		relayProducer,
+		cfg.Outbox.BatchSize,
+		cfg.Outbox.PollInterval,
+		cfg.Outbox.CleanupInterval,
+		cfg.Outbox.Retention,
+	)
+	relayCtx, stopRelay := context.WithCancel(context.Background())
+	relayDone := make(chan struct{})
+	go func() {
+		defer close(relayDone)
+		outboxRelay.Run(relayCtx)
+	}()
+	log.Println("outbox relay is running...")
+
+	// RESERVATION SWEEPER
+	sweeperCtx, stopSweeper := context.WithCancel(context.Background())
+	sweeperDone := make(chan struct{})
+	go func() {
+		defer close(sweeperDone)
+		redemptionService.Run(sweeperCtx)
+	}()
+	log.Println("reservation sweeper is running...")
+
+	// HTTP SERVER
+	adminMux := http.NewServeMux()
+	pointPoolHandler.RegisterRoutes(adminMux)
+	productHandler.RegisterRoutes(adminMux)
+	userPointHandler.RegisterRoutes(adminMux)
+
+	userMux := http.NewServeMux()
+	redemptionHandler.RegisterRoutes(userMux)
+
+	mux := http.NewServeMux()
+	mux.Handle("/admin/", handler.RequireAdminToken(cfg.Http.AdminToken, adminMux))
+	mux.Handle("/redemptions", handler.RequireUserToken(cfg.Http.UserTokenSecret, userMux))
+	mux.Handle("/redemptions/", handler.RequireUserToken(cfg.Http.UserTokenSecret, userMux))
+	mux.Handle("/metrics", promhttp.HandlerFor(metricsRegistry, promhttp.HandlerOpts{}))
+	httpServer := &http.Server{
+		Addr:         cfg.Http.Addr,
+		Handler:      mux,
+		ReadTimeout:  cfg.Http.ReadTimeout,
+		WriteTimeout: cfg.Http.WriteTimeout,
+	}
+	go func() {
+		err := httpServer.ListenAndServe()
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Panicf("http server error: %s", err.Error())
+		}
+	}()
+	log.Printf("http server is listening on %s", cfg.Http.Addr)
 
 	// KAFKA CONSUMER
 	log.Println("Starting a new Sarama consumer")
 	kafkaCtx := context.Background()
-	consumerGroup, err := kafka.NewConsumerGroup(kafkaCtx, consumerGroupId, brokerAddress)
+	consumerGroup, err := kafka.NewConsumerGroup(kafkaCtx, cfg.Kafka.ConsumerGroupId, cfg.Kafka.Brokers, cfg.Kafka.Transactional)
+	if err != nil {
+		log.Panicf("new consumer group error: %s", err.Error())
+	}
 
-	consumer := kafka.NewConsumer(pointHandler.SuccessOrderProcess)
+	// a panic is recovered inside logging and metrics, so it is logged and counted as a failure
+	router := kafka.NewRouter()
+	router.Use(
+		kafka.Logging(logger),
+		kafkaMetrics.Middleware(),
+		kafka.Recovery(logger),
+	)
+	// cancellations and refunds only retry in process, a reservation left behind is released by the sweeper
+	routes := []kafka.Route{
+		{
+			Topic:       cfg.Kafka.Topics.SuccessOrder,
+			Handler:     kafka.Decode(schemaRegistry.Decoder(cfg.Kafka.Topics.SuccessOrder), pointHandler.SuccessOrderProcess),
+			RetryPolicy: cfg.Kafka.Retry.Policy(),
+			DeadLetter:  kafka.NewDeadLetter(producer, cfg.Kafka.Topics.SuccessOrderDlq),
+			Middlewares: []kafka.Middleware{kafka.UnwrapCloudEvents()},
+		},
+		{
+			Topic:       cfg.Kafka.Topics.OrderCancelled,
+			Handler:     kafka.Decode(schemaRegistry.Decoder(cfg.Kafka.Topics.OrderCancelled), pointHandler.OrderCancelledProcess),
+			RetryPolicy: cfg.Kafka.Retry.InProcessPolicy(),
+			DeadLetter:  kafka.NewDeadLetter(producer, cfg.Kafka.Topics.OrderCancelledDlq),
+		},
+		{
+			Topic:       cfg.Kafka.Topics.OrderRefunded,
+			Handler:     kafka.Decode(schemaRegistry.Decoder(cfg.Kafka.Topics.OrderRefunded), pointHandler.OrderRefundedProcess),
+			RetryPolicy: cfg.Kafka.Retry.InProcessPolicy(),
+			DeadLetter:  kafka.NewDeadLetter(producer, cfg.Kafka.Topics.OrderRefundedDlq),
+		},
+	}
+	for _, route := range routes {
+		err = router.Handle(route)
+		if err != nil {
+			log.Panicf("add kafka route error: %s", err.Error())
+		}
+	}
+
+	consumer := kafka.NewConsumer(router, producer, cfg.Kafka.Timeouts())
+	if cfg.Kafka.Transactional {
+		// each claimed partition has its own transactional id, a new owner of the partition fences the old one
+		consumer = kafka.NewTransactionalConsumer(router, cfg.Kafka.ConsumerGroupId, func(topic string, partition int32) (kafka.TransactionalProducer, error) {
+			transactionalId := fmt.Sprintf("%s-%s-%d", cfg.Kafka.TransactionalIdPrefix, topic, partition)
+			return kafka.NewTransactionalProducer(cfg.Kafka.Brokers, cfg.Kafka.Partitioner, transactionalId)
+		}, cfg.Kafka.Timeouts())
+	}
+	consumer = consumer.WithMetrics(kafkaMetrics)
 	go func() {
 		for {
-			err = consumerGroup.Consume(kafkaCtx, []string{topicSuccessOrder}, &consumer)
+			err := consumerGroup.Consume(kafkaCtx, router.Topics(), &consumer)
 			if err != nil {
 				if errors.Is(err, sarama.ErrClosedConsumerGroup) {
 					return
@@ -92,11 +315,33 @@ func main() {
 		log.Println("terminating: kafka context cancelled")
 	}
 
+	shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), cfg.Http.ShutdownTimeout)
+	err = httpServer.Shutdown(shutdownCtx)
+	cancelShutdown()
+	if err != nil {
+		log.Panicf("shutdown http server error: %s", err.Error())
+	}
+
 	err = consumerGroup.Close()
 	if err != nil {
 		log.Panicf("closing consumer group error: %s", err.Error())
 	}
 
+	stopSweeper()
+	<-sweeperDone
+
+	// let the relay finish its batch before the producer goes away
+	stopRelay()
+	<-relayDone
+
+	// an async producer sends what it still queues before closing
+	if cfg.Kafka.Producer.Async {
+		err = relayProducer.CloseConnection()
+		if err != nil {
+			log.Panicf("closing async producer error: %s", err.Error())
+		}
+	}
+
 	err = producer.CloseConnection()
 	if err != nil {
 		log.Panicf("closing producer error: %s", err.Error())
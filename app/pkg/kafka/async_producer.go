@@ -0,0 +1,225 @@
+package kafka
+
+import (
+	"sync"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/pkg/errors"
+)
+
+// Compression is the codec of the batches sent by the async producer
+type Compression string
+
+const (
+	NoCompression     Compression = "none"
+	GzipCompression   Compression = "gzip"
+	SnappyCompression Compression = "snappy"
+	Lz4Compression    Compression = "lz4"
+	ZstdCompression   Compression = "zstd"
+)
+
+var ErrProducerClosed = errors.New("producer is closed")
+
+func (compression Compression) Valid() bool {
+	switch compression {
+	case NoCompression, GzipCompression, SnappyCompression, Lz4Compression, ZstdCompression:
+		return true
+	default:
+		return false
+	}
+}
+
+func (compression Compression) codec() sarama.CompressionCodec {
+	switch compression {
+	case GzipCompression:
+		return sarama.CompressionGZIP
+	case SnappyCompression:
+		return sarama.CompressionSnappy
+	case Lz4Compression:
+		return sarama.CompressionLZ4
+	case ZstdCompression:
+		return sarama.CompressionZSTD
+	default:
+		return sarama.CompressionNone
+	}
+}
+
+// Batching is how the async producer groups messages, a batch is sent once it has Size messages
+// or its first message waited Linger
+type Batching struct {
+	Size        int
+	Linger      time.Duration
+	Compression Compression
+}
+
+// DeliveryResult is where a message sent by the async producer was written, or why it was not
+type DeliveryResult struct {
+	Topic     string
+	Key       string
+	Partition int32
+	Offset    int64
+	Err       error
+}
+
+// DeliveryCallback is called with the result of every message sent by the async producer
+type DeliveryCallback func(result DeliveryResult)
+
+type asyncProducer struct {
+	AsyncProducer sarama.AsyncProducer
+	onDelivery    DeliveryCallback
+
+	// closeMutex keeps SendMessage from writing to the input of a closed producer
+	closeMutex sync.RWMutex
+	closed     bool
+
+	mutex    sync.Mutex
+	flushed  *sync.Cond
+	inFlight int
+	err      error
+
+	delivered chan struct{}
+}
+
+// NewAsyncProducer returns a producer whose SendMessage only queues the message, Flush waits for the
+// queued messages and returns the first failure since the last Flush. The failures are not tied to the
+// sender, so an async producer should have one sender that flushes, like the outbox relay.
+func NewAsyncProducer(addresses []string, partitioner Partitioner, batching Batching, onDelivery DeliveryCallback) (Producer, error) {
+	kafkaConfig := sarama.NewConfig()
+	kafkaConfig.Version = sarama.DefaultVersion
+	kafkaConfig.Producer.Partitioner = partitioner.constructor()
+	kafkaConfig.Producer.RequiredAcks = sarama.WaitForAll
+	kafkaConfig.Producer.Retry.Max = 3
+	kafkaConfig.Producer.Return.Successes = true
+	kafkaConfig.Producer.Return.Errors = true
+	kafkaConfig.Producer.Flush.Messages = batching.Size
+	kafkaConfig.Producer.Flush.Frequency = batching.Linger
+	kafkaConfig.Producer.Compression = batching.Compression.codec()
+	// a retried batch must not overtake the next one, the messages of a key stay in order
+	kafkaConfig.Net.MaxOpenRequests = 1
+
+	saramaProducer, err := sarama.NewAsyncProducer(addresses, kafkaConfig)
+	if err != nil {
+		return nil, errors.Wrap(err, "new async producer sarama error")
+	}
+
+	return newAsyncProducer(saramaProducer, onDelivery), nil
+}
+
+func newAsyncProducer(saramaProducer sarama.AsyncProducer, onDelivery DeliveryCallback) *asyncProducer {
+	producer := &asyncProducer{
+		AsyncProducer: saramaProducer,
+		onDelivery:    onDelivery,
+		delivered:     make(chan struct{}),
+	}
+	producer.flushed = sync.NewCond(&producer.mutex)
+
+	go producer.deliver()
+
+	return producer
+}
+
+func (producer *asyncProducer) SendMessage(topic string, key string, message string, headers map[string]string) error {
+	return producer.SendMessageToPartition(topic, AnyPartition, key, message, headers)
+}
+
+func (producer *asyncProducer) SendMessageToPartition(topic string, partition int32, key string, message string, headers map[string]string) error {
+	producer.closeMutex.RLock()
+	defer producer.closeMutex.RUnlock()
+
+	if producer.closed {
+		return ErrProducerClosed
+	}
+
+	producer.mutex.Lock()
+	producer.inFlight++
+	producer.mutex.Unlock()
+
+	producer.AsyncProducer.Input() <- newProducerMessage(topic, partition, key, message, headers)
+
+	return nil
+}
+
+// Flush waits until every queued message is written or failed
+func (producer *asyncProducer) Flush() error {
+	producer.mutex.Lock()
+	defer producer.mutex.Unlock()
+
+	for producer.inFlight > 0 {
+		producer.flushed.Wait()
+	}
+
+	err := producer.err
+	producer.err = nil
+	if err != nil {
+		return Retryable(errors.Wrap(err, "send message error"))
+	}
+
+	return nil
+}
+
+// CloseConnection sends the queued messages and waits for their results before closing
+func (producer *asyncProducer) CloseConnection() error {
+	producer.closeMutex.Lock()
+	if producer.closed {
+		producer.closeMutex.Unlock()
+		return nil
+	}
+	producer.closed = true
+	producer.closeMutex.Unlock()
+
+	producer.AsyncProducer.AsyncClose()
+	<-producer.delivered
+
+	return producer.Flush()
+}
+
+// deliver reports the result of every message until the producer is closed
+func (producer *asyncProducer) deliver() {
+	defer close(producer.delivered)
+
+	successes, failures := producer.AsyncProducer.Successes(), producer.AsyncProducer.Errors()
+	for successes != nil || failures != nil {
+		select {
+		case message, ok := <-successes:
+			if !ok {
+				successes = nil
+				continue
+			}
+			producer.done(message, nil)
+
+		case failure, ok := <-failures:
+			if !ok {
+				failures = nil
+				continue
+			}
+			producer.done(failure.Msg, failure.Err)
+		}
+	}
+}
+
+func (producer *asyncProducer) done(message *sarama.ProducerMessage, err error) {
+	if producer.onDelivery != nil {
+		result := DeliveryResult{
+			Topic:     message.Topic,
+			Partition: message.Partition,
+			Offset:    message.Offset,
+			Err:       err,
+		}
+		if message.Key != nil {
+			key, _ := message.Key.Encode()
+			result.Key = string(key)
+		}
+
+		producer.onDelivery(result)
+	}
+
+	producer.mutex.Lock()
+	defer producer.mutex.Unlock()
+
+	producer.inFlight--
+	if err != nil && producer.err == nil {
+		producer.err = err
+	}
+	producer.flushed.Broadcast()
+}
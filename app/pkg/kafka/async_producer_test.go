@@ -0,0 +1,93 @@
+package kafka
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/IBM/sarama"
+	"github.com/IBM/sarama/mocks"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/suite"
+)
+
+type AsyncProducerTestSuite struct {
+	suite.Suite
+	saramaProducer *mocks.AsyncProducer
+	producer       *asyncProducer
+
+	mutex   sync.Mutex
+	results []DeliveryResult
+}
+
+func (suite *AsyncProducerTestSuite) SetupTest() {
+	config := mocks.NewTestConfig()
+	config.Producer.Return.Successes = true
+
+	suite.results = nil
+	suite.saramaProducer = mocks.NewAsyncProducer(suite.T(), config)
+	suite.producer = newAsyncProducer(suite.saramaProducer, func(result DeliveryResult) {
+		suite.mutex.Lock()
+		defer suite.mutex.Unlock()
+		suite.results = append(suite.results, result)
+	})
+}
+
+func (suite *AsyncProducerTestSuite) TestAsyncProducer_HappyCase_Flush() {
+	suite.saramaProducer.ExpectInputWithMessageCheckerFunctionAndSucceed(func(message *sarama.ProducerMessage) error {
+		key, _ := message.Key.Encode()
+		if string(key) != "1" {
+			return errors.Errorf("unexpected key %s", key)
+		}
+		return nil
+	})
+	suite.saramaProducer.ExpectInputAndSucceed()
+
+	suite.Nil(suite.producer.SendMessage("decrease.point.success", "1", `{"order_id":1}`, map[string]string{HeaderCorrelationId: "checkout-1"}))
+	suite.Nil(suite.producer.SendMessage("decrease.point.success", "2", `{"order_id":2}`, nil))
+	suite.Nil(suite.producer.Flush())
+
+	suite.Len(suite.results, 2)
+	suite.Equal("decrease.point.success", suite.results[0].Topic)
+	suite.Equal("1", suite.results[0].Key)
+	suite.Nil(suite.results[0].Err)
+	suite.Nil(suite.producer.CloseConnection())
+}
+
+func (suite *AsyncProducerTestSuite) TestAsyncProducer_FlushError() {
+	suite.saramaProducer.ExpectInputAndFail(sarama.ErrNotLeaderForPartition)
+	suite.saramaProducer.ExpectInputAndSucceed()
+
+	suite.Nil(suite.producer.SendMessage("decrease.point.success", "1", `{"order_id":1}`, nil))
+	suite.Nil(suite.producer.SendMessage("decrease.point.success", "2", `{"order_id":2}`, nil))
+
+	err := suite.producer.Flush()
+	suite.ErrorIs(err, sarama.ErrNotLeaderForPartition)
+	suite.True(IsRetryable(err))
+	suite.Len(suite.results, 2)
+
+	// a failure is only reported once
+	suite.Nil(suite.producer.Flush())
+	suite.Nil(suite.producer.CloseConnection())
+}
+
+func (suite *AsyncProducerTestSuite) TestAsyncProducer_Closed() {
+	suite.saramaProducer.ExpectInputAndSucceed()
+
+	suite.Nil(suite.producer.SendMessage("decrease.point.success", "1", `{"order_id":1}`, nil))
+	suite.Nil(suite.producer.CloseConnection())
+	suite.Len(suite.results, 1)
+
+	err := suite.producer.SendMessage("decrease.point.success", "2", `{"order_id":2}`, nil)
+	suite.ErrorIs(err, ErrProducerClosed)
+	suite.Nil(suite.producer.CloseConnection())
+}
+
+func (suite *AsyncProducerTestSuite) TestAsyncProducer_Compression() {
+	suite.Equal(sarama.CompressionSnappy, SnappyCompression.codec())
+	suite.Equal(sarama.CompressionNone, NoCompression.codec())
+	suite.False(Compression("brotli").Valid())
+}
+
+func TestAsyncProducerTestSuite(t *testing.T) {
+	suite.Run(t, new(AsyncProducerTestSuite))
+}
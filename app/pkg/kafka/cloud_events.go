@@ -0,0 +1,209 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/pkg/errors"
+)
+
+// CloudEventsMode is how produced events are wrapped in a CloudEvents 1.0 envelope
+type CloudEventsMode string
+
+const (
+	// NoCloudEvents sends the bare event
+	NoCloudEvents CloudEventsMode = "none"
+	// BinaryCloudEvents keeps the event as the value and sends the attributes as ce_ headers
+	BinaryCloudEvents CloudEventsMode = "binary"
+	// StructuredCloudEvents sends the attributes and the event in one json envelope
+	StructuredCloudEvents CloudEventsMode = "structured"
+)
+
+// headers of the CloudEvents kafka protocol binding
+const (
+	HeaderContentType       = "content-type"
+	CloudEventsHeaderPrefix = "ce_"
+)
+
+const (
+	cloudEventsSpecVersion = "1.0"
+	cloudEventsContentType = "application/cloudevents+json; charset=UTF-8"
+	jsonContentType        = "application/json"
+	avroContentType        = "application/avro"
+)
+
+var ErrInvalidCloudEvent = errors.New("invalid cloud event")
+
+func (mode CloudEventsMode) Valid() bool {
+	switch mode {
+	case NoCloudEvents, BinaryCloudEvents, StructuredCloudEvents:
+		return true
+	default:
+		return false
+	}
+}
+
+// CloudEvents wraps the events of the topics of Types, the events of other topics are sent as they are
+type CloudEvents struct {
+	Mode   CloudEventsMode
+	Source string
+	// Types is the event type of each wrapped topic
+	Types map[string]string
+}
+
+type cloudEvent struct {
+	SpecVersion     string          `json:"specversion"`
+	Id              string          `json:"id"`
+	Source          string          `json:"source"`
+	Type            string          `json:"type"`
+	Subject         string          `json:"subject,omitempty"`
+	Time            string          `json:"time,omitempty"`
+	DataContentType string          `json:"datacontenttype,omitempty"`
+	Data            json.RawMessage `json:"data,omitempty"`
+	DataBase64      []byte          `json:"data_base64,omitempty"`
+}
+
+// Wrap returns the value of an event of topic keyed by key, the attribute headers of the envelope are
+// added to headers. The id of the event is the topic and the key, so a re-emitted event has the same id.
+func (cloudEvents CloudEvents) Wrap(topic string, key string, value []byte, headers map[string]string) ([]byte, error) {
+	eventType, ok := cloudEvents.Types[topic]
+	if !ok || cloudEvents.Mode == NoCloudEvents || cloudEvents.Mode == "" {
+		return value, nil
+	}
+
+	event := cloudEvent{
+		SpecVersion:     cloudEventsSpecVersion,
+		Id:              topic + "-" + key,
+		Source:          cloudEvents.Source,
+		Type:            eventType,
+		Subject:         key,
+		Time:            time.Now().UTC().Format(time.RFC3339Nano),
+		DataContentType: jsonContentType,
+	}
+	// an avro event is not json, it is base64 data in a structured envelope
+	if !json.Valid(value) {
+		event.DataContentType = avroContentType
+	}
+
+	if cloudEvents.Mode == BinaryCloudEvents {
+		headers[CloudEventsHeaderPrefix+"specversion"] = event.SpecVersion
+		headers[CloudEventsHeaderPrefix+"id"] = event.Id
+		headers[CloudEventsHeaderPrefix+"source"] = event.Source
+		headers[CloudEventsHeaderPrefix+"type"] = event.Type
+		headers[CloudEventsHeaderPrefix+"subject"] = event.Subject
+		headers[CloudEventsHeaderPrefix+"time"] = event.Time
+		headers[HeaderContentType] = event.DataContentType
+		return value, nil
+	}
+
+	if event.DataContentType == jsonContentType {
+		event.Data = value
+	} else {
+		event.DataBase64 = value
+	}
+
+	envelope, err := json.Marshal(event)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshal cloud event error")
+	}
+	headers[HeaderContentType] = cloudEventsContentType
+
+	return envelope, nil
+}
+
+// UnwrapCloudEvents hands a structured CloudEvents message to the handler as a binary one, the value is
+// the data of the event and its attributes are ce_ headers. A binary or bare message is handed as it
+// is, so the decoder of the route reads the event in any of the three forms.
+func UnwrapCloudEvents() Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, message *sarama.ConsumerMessage) error {
+			if !isStructuredCloudEvent(message) {
+				return next(ctx, message)
+			}
+
+			unwrapped, err := unwrapCloudEvent(message)
+			if err != nil {
+				return err
+			}
+
+			return next(WithMessage(ctx, unwrapped), unwrapped)
+		}
+	}
+}
+
+// isStructuredCloudEvent reports whether message is a structured event, by its content type or, for a
+// producer that sets no headers, by the specversion of the value
+func isStructuredCloudEvent(message *sarama.ConsumerMessage) bool {
+	contentType, ok := messageHeaders(message)[HeaderContentType]
+	if ok {
+		return strings.HasPrefix(contentType, "application/cloudevents")
+	}
+
+	var attributes struct {
+		SpecVersion *string `json:"specversion"`
+	}
+	return json.Unmarshal(message.Value, &attributes) == nil && attributes.SpecVersion != nil
+}
+
+func unwrapCloudEvent(message *sarama.ConsumerMessage) (*sarama.ConsumerMessage, error) {
+	if strings.HasPrefix(messageHeaders(message)[HeaderContentType], "application/cloudevents-batch") {
+		return nil, fmt.Errorf("%w: batched events are not supported", ErrInvalidCloudEvent)
+	}
+
+	var attributes map[string]json.RawMessage
+	err := json.Unmarshal(message.Value, &attributes)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCloudEvent, err)
+	}
+
+	var event cloudEvent
+	err = json.Unmarshal(message.Value, &event)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCloudEvent, err)
+	}
+	if event.SpecVersion != cloudEventsSpecVersion {
+		return nil, fmt.Errorf("%w: specversion %q is not supported", ErrInvalidCloudEvent, event.SpecVersion)
+	}
+	if event.Id == "" || event.Source == "" || event.Type == "" {
+		return nil, fmt.Errorf("%w: id, source and type are required", ErrInvalidCloudEvent)
+	}
+
+	unwrapped := *message
+	unwrapped.Value = event.Data
+	if event.DataBase64 != nil {
+		unwrapped.Value = event.DataBase64
+	}
+
+	unwrapped.Headers = nil
+	for _, header := range message.Headers {
+		if string(header.Key) != HeaderContentType {
+			unwrapped.Headers = append(unwrapped.Headers, header)
+		}
+	}
+	names := []string{}
+	for name := range attributes {
+		if name != "data" && name != "data_base64" && name != "datacontenttype" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		// extension attributes may be numbers or booleans, they are kept as their json text
+		var value string
+		if json.Unmarshal(attributes[name], &value) != nil {
+			value = string(attributes[name])
+		}
+		unwrapped.Headers = append(unwrapped.Headers, &sarama.RecordHeader{Key: []byte(CloudEventsHeaderPrefix + name), Value: []byte(value)})
+	}
+	if event.DataContentType != "" {
+		unwrapped.Headers = append(unwrapped.Headers, &sarama.RecordHeader{Key: []byte(HeaderContentType), Value: []byte(event.DataContentType)})
+	}
+
+	return &unwrapped, nil
+}
@@ -0,0 +1,175 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/stretchr/testify/suite"
+)
+
+type CloudEventsTestSuite struct {
+	suite.Suite
+	cloudEvents CloudEvents
+}
+
+func (suite *CloudEventsTestSuite) SetupTest() {
+	suite.cloudEvents = CloudEvents{
+		Mode:   StructuredCloudEvents,
+		Source: "/point-service",
+		Types:  map[string]string{"decrease.point.success": "point.decrease.success"},
+	}
+}
+
+// handle runs message through UnwrapCloudEvents and returns the message and context the handler got
+func (suite *CloudEventsTestSuite) handle(message *sarama.ConsumerMessage) (*sarama.ConsumerMessage, context.Context, error) {
+	var handled *sarama.ConsumerMessage
+	var handledCtx context.Context
+	err := UnwrapCloudEvents()(func(ctx context.Context, message *sarama.ConsumerMessage) error {
+		handled = message
+		handledCtx = ctx
+		return nil
+	})(WithMessage(context.Background(), message), message)
+
+	return handled, handledCtx, err
+}
+
+func (suite *CloudEventsTestSuite) TestCloudEvents_HappyCase_Structured() {
+	headers := map[string]string{HeaderCorrelationId: "checkout-1"}
+	value, err := suite.cloudEvents.Wrap("decrease.point.success", "7", []byte(`{"version":2,"order_id":7}`), headers)
+	suite.Nil(err)
+	suite.Equal(map[string]string{HeaderCorrelationId: "checkout-1", HeaderContentType: "application/cloudevents+json; charset=UTF-8"}, headers)
+
+	var event map[string]interface{}
+	suite.Nil(json.Unmarshal(value, &event))
+	suite.Equal("1.0", event["specversion"])
+	suite.Equal("decrease.point.success-7", event["id"])
+	suite.Equal("/point-service", event["source"])
+	suite.Equal("point.decrease.success", event["type"])
+	suite.Equal("7", event["subject"])
+	suite.Equal("application/json", event["datacontenttype"])
+	suite.Equal(map[string]interface{}{"version": float64(2), "order_id": float64(7)}, event["data"])
+	_, err = time.Parse(time.RFC3339Nano, event["time"].(string))
+	suite.Nil(err)
+}
+
+func (suite *CloudEventsTestSuite) TestCloudEvents_HappyCase_Binary() {
+	suite.cloudEvents.Mode = BinaryCloudEvents
+
+	headers := map[string]string{}
+	value, err := suite.cloudEvents.Wrap("decrease.point.success", "7", []byte(`{"order_id":7}`), headers)
+	suite.Nil(err)
+	suite.Equal(`{"order_id":7}`, string(value))
+	suite.Equal("1.0", headers["ce_specversion"])
+	suite.Equal("decrease.point.success-7", headers["ce_id"])
+	suite.Equal("point.decrease.success", headers["ce_type"])
+	suite.Equal("application/json", headers[HeaderContentType])
+}
+
+func (suite *CloudEventsTestSuite) TestCloudEvents_Unwrapped() {
+	// a topic without type and the none mode send the bare event
+	headers := map[string]string{}
+	value, err := suite.cloudEvents.Wrap("decrease.point.failed", "7", []byte(`{"order_id":7}`), headers)
+	suite.Nil(err)
+	suite.Equal(`{"order_id":7}`, string(value))
+	suite.Empty(headers)
+
+	suite.cloudEvents.Mode = NoCloudEvents
+	value, err = suite.cloudEvents.Wrap("decrease.point.success", "7", []byte(`{"order_id":7}`), headers)
+	suite.Nil(err)
+	suite.Equal(`{"order_id":7}`, string(value))
+	suite.Empty(headers)
+}
+
+func (suite *CloudEventsTestSuite) TestCloudEvents_HappyCase_Avro() {
+	headers := map[string]string{}
+	value, err := suite.cloudEvents.Wrap("decrease.point.success", "7", []byte{0, 0, 0, 0, 2, 14}, headers)
+	suite.Nil(err)
+	suite.Contains(string(value), `"datacontenttype":"application/avro","data_base64":"AAAAAAIO"`)
+
+	handled, _, err := suite.handle(&sarama.ConsumerMessage{
+		Value:   value,
+		Headers: []*sarama.RecordHeader{{Key: []byte(HeaderContentType), Value: []byte(headers[HeaderContentType])}},
+	})
+	suite.Nil(err)
+	suite.Equal([]byte{0, 0, 0, 0, 2, 14}, handled.Value)
+}
+
+func (suite *CloudEventsTestSuite) TestCloudEvents_HappyCase_UnwrapStructured() {
+	message := &sarama.ConsumerMessage{
+		Topic: "success.order",
+		Value: []byte(`{"specversion":"1.0","id":"order-1","source":"/order-service","type":"order.success","datacontenttype":"application/json","partitionkey":1,"data":{"order_id":1,"product_id":2}}`),
+		Headers: []*sarama.RecordHeader{
+			{Key: []byte(HeaderCorrelationId), Value: []byte("checkout-1")},
+			{Key: []byte(HeaderContentType), Value: []byte("application/cloudevents+json; charset=UTF-8")},
+		},
+	}
+
+	handled, ctx, err := suite.handle(message)
+	suite.Nil(err)
+	suite.JSONEq(`{"order_id":1,"product_id":2}`, string(handled.Value))
+	suite.Equal(map[string]string{
+		HeaderCorrelationId: "checkout-1",
+		HeaderContentType:   "application/json",
+		"ce_specversion":    "1.0",
+		"ce_id":             "order-1",
+		"ce_source":         "/order-service",
+		"ce_type":           "order.success",
+		"ce_partitionkey":   "1",
+	}, messageHeaders(handled))
+
+	// the context carries the attributes too, the consumed message is left as it is
+	id, _ := HeaderFromContext(ctx, "ce_id")
+	suite.Equal("order-1", id)
+	suite.Equal("checkout-1", CorrelationIdFromContext(ctx))
+	suite.Contains(string(message.Value), "specversion")
+}
+
+func (suite *CloudEventsTestSuite) TestCloudEvents_HappyCase_UnwrapWithoutContentType() {
+	handled, _, err := suite.handle(&sarama.ConsumerMessage{
+		Value: []byte(`{"specversion":"1.0","id":"order-1","source":"/order-service","type":"order.success","data":{"order_id":1}}`),
+	})
+	suite.Nil(err)
+	suite.JSONEq(`{"order_id":1}`, string(handled.Value))
+}
+
+func (suite *CloudEventsTestSuite) TestCloudEvents_HappyCase_BinaryAndBare() {
+	for _, message := range []*sarama.ConsumerMessage{
+		{
+			Value: []byte(`{"order_id":1}`),
+			Headers: []*sarama.RecordHeader{
+				{Key: []byte("ce_specversion"), Value: []byte("1.0")},
+				{Key: []byte(HeaderContentType), Value: []byte("application/json")},
+			},
+		},
+		{Value: []byte(`{"order_id":1}`)},
+	} {
+		handled, _, err := suite.handle(message)
+		suite.Nil(err)
+		suite.Same(message, handled)
+	}
+}
+
+func (suite *CloudEventsTestSuite) TestCloudEvents_InvalidEvent() {
+	for _, value := range []string{
+		`{"specversion":"0.3","id":"order-1","source":"/order-service","type":"order.success","data":{}}`,
+		`{"specversion":"1.0","source":"/order-service","type":"order.success","data":{}}`,
+		`{"specversion":"1.0","id":"order-1","source":"/order-service","type":"order.success","data_base64":"!"}`,
+	} {
+		_, _, err := suite.handle(&sarama.ConsumerMessage{Value: []byte(value)})
+		suite.ErrorIs(err, ErrInvalidCloudEvent)
+		suite.False(IsRetryable(err))
+	}
+
+	_, _, err := suite.handle(&sarama.ConsumerMessage{
+		Value:   []byte(`[]`),
+		Headers: []*sarama.RecordHeader{{Key: []byte(HeaderContentType), Value: []byte("application/cloudevents-batch+json")}},
+	})
+	suite.ErrorContains(err, "batched events are not supported")
+}
+
+func TestCloudEventsTestSuite(t *testing.T) {
+	suite.Run(t, new(CloudEventsTestSuite))
+}
@@ -1,27 +1,60 @@
 package kafka
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
 	"log"
+	"strconv"
 	"time"
 
 	"github.com/IBM/sarama"
+	"github.com/pkg/errors"
 )
 
+// Timeouts bound the handling of a message. Every handler attempt is cancelled after Handler, and all
+// attempts of the message with the backoff between them share Processing. An attempt never outlives
+// Processing, a message running out of it goes to the next retry topic of its route.
+type Timeouts struct {
+	Handler    time.Duration
+	Processing time.Duration
+}
+
 type Consumer struct {
-	logEnable bool
-	handler   func(ctx context.Context, message *sarama.ConsumerMessage) error
+	router   *Router
+	producer Producer
+	timeouts Timeouts
+
+	groupId                string
+	transactionalProducers TransactionalProducerFactory
+
+	metrics *Metrics
+}
+
+// NewConsumer returns a consumer handling every message within timeouts
+func NewConsumer(router *Router, producer Producer, timeouts Timeouts) Consumer {
+	return Consumer{
+		router:   router,
+		producer: producer,
+		timeouts: timeouts,
+	}
 }
 
-func NewConsumer(handler func(ctx context.Context, message *sarama.ConsumerMessage) error) Consumer {
+// NewTransactionalConsumer returns a consumer handling every message in a kafka transaction of its partition,
+// the messages sent while handling it and its offset are committed together
+func NewTransactionalConsumer(router *Router, groupId string, producers TransactionalProducerFactory, timeouts Timeouts) Consumer {
 	return Consumer{
-		logEnable: false,
-		handler:   handler,
+		router:                 router,
+		timeouts:               timeouts,
+		groupId:                groupId,
+		transactionalProducers: producers,
 	}
 }
 
+// WithMetrics returns the consumer keeping the lag of its partitions in metrics
+func (consumer Consumer) WithMetrics(metrics *Metrics) Consumer {
+	consumer.metrics = metrics
+	return consumer
+}
+
 func (consumer *Consumer) Setup(sarama.ConsumerGroupSession) error {
 	return nil
 }
@@ -31,6 +64,21 @@ func (consumer *Consumer) Cleanup(sarama.ConsumerGroupSession) error {
 }
 
 func (consumer *Consumer) ConsumeClaim(session sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
+	var transaction TransactionalProducer
+	if consumer.transactionalProducers != nil {
+		var err error
+		transaction, err = consumer.transactionalProducers(claim.Topic(), claim.Partition())
+		if err != nil {
+			return errors.Wrap(err, "new transactional producer error")
+		}
+		defer transaction.CloseConnection()
+	}
+
+	// another consumer of the group reports the partition after a rebalance
+	if consumer.metrics != nil {
+		defer consumer.metrics.forgetLag(claim.Topic(), claim.Partition())
+	}
+
 	for {
 		select {
 		case message, ok := <-claim.Messages():
@@ -39,38 +87,171 @@ func (consumer *Consumer) ConsumeClaim(session sarama.ConsumerGroupSession, clai
 				return nil
 			}
 
-			// message logging
-			if consumer.logEnable {
-				dst := &bytes.Buffer{}
-				json.Compact(dst, message.Value)
+			if consumer.metrics != nil {
+				consumer.metrics.observeLag(claim, message)
+			}
 
-				var headers = map[string]string{}
-				for _, h := range message.Headers {
-					headers[string(h.Key)] = string(h.Value)
+			route, handler, ok := consumer.router.match(message.Topic)
+			if !ok {
+				log.Printf("no route for topic %s, skip offset %d", message.Topic, message.Offset)
+				err := consumer.commit(session, transaction, message, func(context.Context) error { return nil })
+				if err != nil {
+					return err
 				}
+				continue
+			}
 
-				log.Printf(
-					"consume message: headers = %+v, value = %s, timestamp = %s, partition = %d, offset = %d\n",
-					headers,
-					dst.String(),
-					message.Timestamp.Format(time.RFC3339),
-					message.Partition,
-					message.Offset,
-				)
+			// messages from a retry topic wait until their delay has passed
+			if !consumer.waitRetryDelay(session.Context(), route, message) {
+				return nil
 			}
 
 			// start message processing
-			err := consumer.handler(context.Background(), message)
-			if err != nil {
-				log.Printf("consumer handler error: %s", err.Error())
-				return err
+			setCorrelationId(message)
+			err := consumer.process(session.Context(), route, handler, message, transaction)
+			if session.Context().Err() != nil {
+				return nil
 			}
 
-			// mark message
-			session.MarkMessage(message, "")
+			switch {
+			case errors.Is(err, ErrTransactionFailed):
+				return err
+
+			case err != nil:
+				log.Printf("consumer handler error: %s", err.Error())
+
+				reason := err
+				err = consumer.commit(session, transaction, message, func(ctx context.Context) error {
+					return consumer.forward(ctx, route, message, reason)
+				})
+				if err != nil {
+					log.Printf("consumer route error: %s", err.Error())
+					return err
+				}
+
+			// a transactional handler already committed the offset with its messages
+			case transaction == nil:
+				session.MarkMessage(message, "")
+			}
 
 		case <-session.Context().Done():
 			return nil
 		}
 	}
 }
+
+// commit runs fn then marks message, in one kafka transaction when the consumer is transactional
+func (consumer *Consumer) commit(session sarama.ConsumerGroupSession, transaction TransactionalProducer, message *sarama.ConsumerMessage, fn func(ctx context.Context) error) error {
+	if transaction != nil {
+		return inTransaction(session.Context(), transaction, consumer.groupId, message, fn)
+	}
+
+	err := fn(session.Context())
+	if err != nil {
+		return err
+	}
+	session.MarkMessage(message, "")
+
+	return nil
+}
+
+// process runs the handler, retrying transient errors with backoff until the route retry policy is exhausted.
+// The handler context ends with the session or as the Timeouts of the consumer say.
+func (consumer *Consumer) process(sessionCtx context.Context, route *Route, handler HandlerFunc, message *sarama.ConsumerMessage, transaction TransactionalProducer) error {
+	ctx, cancel := context.WithTimeout(WithMessage(sessionCtx, message), consumer.timeouts.Processing)
+	defer cancel()
+
+	var attempt uint = 1
+
+	for {
+		err := consumer.attempt(ctx, handler, message, transaction)
+		if err == nil || !IsRetryable(err) || attempt >= route.RetryPolicy.MaxAttempts {
+			return err
+		}
+
+		select {
+		case <-time.After(route.RetryPolicy.Backoff(attempt)):
+		case <-ctx.Done():
+			return Retryable(errors.Wrap(ctx.Err(), "process message error"))
+		}
+
+		attempt++
+	}
+}
+
+// attempt runs the handler once behind the Timeout middleware, which the consumer applies instead of the
+// router so the timeout also covers the kafka transaction of the attempt
+func (consumer *Consumer) attempt(ctx context.Context, handler HandlerFunc, message *sarama.ConsumerMessage, transaction TransactionalProducer) error {
+	if transaction != nil {
+		inner := handler
+		handler = func(ctx context.Context, message *sarama.ConsumerMessage) error {
+			return inTransaction(ctx, transaction, consumer.groupId, message, func(ctx context.Context) error {
+				return inner(ctx, message)
+			})
+		}
+	}
+
+	return Timeout(consumer.timeouts.Handler)(handler)(ctx, message)
+}
+
+// forward sends a failed message to the next retry topic of its route, or to the dead letter
+// topic when the error is permanent or every retry topic was tried, with the kafka transaction of ctx if any
+func (consumer *Consumer) forward(ctx context.Context, route *Route, message *sarama.ConsumerMessage, reason error) error {
+	next := route.RetryPolicy.tier(message.Topic) + 1
+	if !IsRetryable(reason) || next >= len(route.RetryPolicy.RetryTopics) {
+		return route.DeadLetter.SendDeadLetter(ctx, message, reason)
+	}
+
+	producer := consumer.producer
+	if transaction, ok := TransactionFromContext(ctx); ok {
+		producer = transaction
+	}
+
+	headers := messageHeaders(message)
+
+	// keep where the message came from on the first hop
+	if _, ok := headers[HeaderOriginalTopic]; !ok {
+		headers[HeaderOriginalTopic] = message.Topic
+		headers[HeaderOriginalPartition] = strconv.FormatInt(int64(message.Partition), 10)
+		headers[HeaderOriginalOffset] = strconv.FormatInt(message.Offset, 10)
+	}
+
+	headers[HeaderErrorReason] = reason.Error()
+	headers[HeaderRetryAttempt] = strconv.Itoa(next + 1)
+
+	err := producer.SendMessage(route.RetryPolicy.RetryTopics[next].Topic, string(message.Key), string(message.Value), headers)
+	if err != nil {
+		return errors.Wrap(err, "send retry message error")
+	}
+
+	return nil
+}
+
+// waitRetryDelay returns false when the session ends before the message is due
+func (consumer *Consumer) waitRetryDelay(sessionCtx context.Context, route *Route, message *sarama.ConsumerMessage) bool {
+	tier := route.RetryPolicy.tier(message.Topic)
+	if tier < 0 {
+		return true
+	}
+
+	wait := time.Until(message.Timestamp.Add(route.RetryPolicy.RetryTopics[tier].Delay))
+	if wait <= 0 {
+		return true
+	}
+
+	select {
+	case <-time.After(wait):
+		return true
+	case <-sessionCtx.Done():
+		return false
+	}
+}
+
+func messageHeaders(message *sarama.ConsumerMessage) map[string]string {
+	headers := map[string]string{}
+	for _, h := range message.Headers {
+		headers[string(h.Key)] = string(h.Value)
+	}
+
+	return headers
+}
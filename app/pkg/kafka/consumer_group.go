@@ -7,11 +7,17 @@ import (
 	"github.com/pkg/errors"
 )
 
-func NewConsumerGroup(ctx context.Context, consumerGroupId string, addresses []string) (sarama.ConsumerGroup, error) {
+// NewConsumerGroup returns a consumer group, a transactional one only reads committed messages and
+// leaves committing offsets to the kafka transactions of its consumer
+func NewConsumerGroup(ctx context.Context, consumerGroupId string, addresses []string, transactional bool) (sarama.ConsumerGroup, error) {
 	kafkaConfig := sarama.NewConfig()
 	kafkaConfig.Version = sarama.DefaultVersion
 	kafkaConfig.Consumer.Group.Rebalance.GroupStrategies = []sarama.BalanceStrategy{sarama.NewBalanceStrategyRoundRobin()}
 	kafkaConfig.Consumer.Offsets.Initial = sarama.OffsetOldest
+	if transactional {
+		kafkaConfig.Consumer.IsolationLevel = sarama.ReadCommitted
+		kafkaConfig.Consumer.Offsets.AutoCommit.Enable = false
+	}
 
 	consumerGroup, err := sarama.NewConsumerGroup(addresses, consumerGroupId, kafkaConfig)
 	if err != nil {
@@ -0,0 +1,129 @@
+package kafka
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"time"
+
+	"github.com/IBM/sarama"
+)
+
+// headers carried from a consumed message to every message produced while handling it,
+// HeaderCorrelationId ties together the messages and logs of one business flow
+const (
+	HeaderCorrelationId = "x-correlation-id"
+	HeaderTraceparent   = "traceparent"
+	HeaderTracestate    = "tracestate"
+	HeaderTenant        = "x-tenant"
+	HeaderSource        = "x-source"
+)
+
+var PropagatedHeaders = []string{HeaderCorrelationId, HeaderTraceparent, HeaderTracestate, HeaderTenant, HeaderSource}
+
+type metadataKey struct{}
+
+type correlationIdKey struct{}
+
+// Metadata is where the message being handled came from
+type Metadata struct {
+	Topic     string
+	Partition int32
+	Offset    int64
+	Key       string
+	Timestamp time.Time
+	Headers   map[string]string
+}
+
+// WithMessage returns a context carrying the metadata and correlation id of message
+func WithMessage(ctx context.Context, message *sarama.ConsumerMessage) context.Context {
+	metadata := Metadata{
+		Topic:     message.Topic,
+		Partition: message.Partition,
+		Offset:    message.Offset,
+		Key:       string(message.Key),
+		Timestamp: message.Timestamp,
+		Headers:   messageHeaders(message),
+	}
+
+	correlationId := metadata.Headers[HeaderCorrelationId]
+	if correlationId == "" {
+		correlationId = NewCorrelationId()
+	}
+
+	ctx = context.WithValue(ctx, metadataKey{}, metadata)
+
+	return WithCorrelationId(ctx, correlationId)
+}
+
+// MetadataFromContext returns the metadata of the message being handled
+func MetadataFromContext(ctx context.Context) (Metadata, bool) {
+	metadata, ok := ctx.Value(metadataKey{}).(Metadata)
+	return metadata, ok
+}
+
+// HeaderFromContext returns a header of the message being handled
+func HeaderFromContext(ctx context.Context, key string) (string, bool) {
+	metadata, ok := MetadataFromContext(ctx)
+	if !ok {
+		return "", false
+	}
+
+	value, ok := metadata.Headers[key]
+	return value, ok
+}
+
+// WithCorrelationId returns a context carrying correlationId
+func WithCorrelationId(ctx context.Context, correlationId string) context.Context {
+	return context.WithValue(ctx, correlationIdKey{}, correlationId)
+}
+
+// CorrelationIdFromContext returns the correlation id of ctx, or an empty string
+func CorrelationIdFromContext(ctx context.Context) string {
+	correlationId, _ := ctx.Value(correlationIdKey{}).(string)
+	return correlationId
+}
+
+// PropagationHeaders returns the headers to produce a message with while handling the message of ctx,
+// the propagated headers of the consumed message and the correlation id of ctx
+func PropagationHeaders(ctx context.Context) map[string]string {
+	headers := map[string]string{}
+
+	metadata, _ := MetadataFromContext(ctx)
+	for _, key := range PropagatedHeaders {
+		if value, ok := metadata.Headers[key]; ok {
+			headers[key] = value
+		}
+	}
+
+	if correlationId := CorrelationIdFromContext(ctx); correlationId != "" {
+		headers[HeaderCorrelationId] = correlationId
+	}
+
+	return headers
+}
+
+// NewCorrelationId returns a random uuid
+func NewCorrelationId() string {
+	id := make([]byte, 16)
+	_, _ = rand.Read(id)
+	id[6] = (id[6] & 0x0f) | 0x40
+	id[8] = (id[8] & 0x3f) | 0x80
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", id[0:4], id[4:6], id[6:8], id[8:10], id[10:])
+}
+
+// setCorrelationId gives a message without a correlation id a new one, so the retry and dead letter
+// messages made from it keep the same id
+func setCorrelationId(message *sarama.ConsumerMessage) {
+	for _, header := range message.Headers {
+		if string(header.Key) == HeaderCorrelationId && len(header.Value) > 0 {
+			return
+		}
+	}
+
+	message.Headers = append(message.Headers, &sarama.RecordHeader{
+		Key:   []byte(HeaderCorrelationId),
+		Value: []byte(NewCorrelationId()),
+	})
+}
@@ -0,0 +1,141 @@
+package kafka
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/suite"
+)
+
+type ContextTestSuite struct {
+	suite.Suite
+	message *sarama.ConsumerMessage
+}
+
+func (suite *ContextTestSuite) SetupTest() {
+	suite.message = &sarama.ConsumerMessage{
+		Topic:     "success.order",
+		Partition: 2,
+		Offset:    42,
+		Key:       []byte("10"),
+		Timestamp: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		Headers:   []*sarama.RecordHeader{{Key: []byte(HeaderCorrelationId), Value: []byte("checkout-1")}},
+	}
+}
+
+func (suite *ContextTestSuite) TestContext_HappyCase_WithMessage() {
+	ctx := WithMessage(context.Background(), suite.message)
+
+	metadata, ok := MetadataFromContext(ctx)
+	suite.True(ok)
+	suite.Equal("success.order", metadata.Topic)
+	suite.Equal(int32(2), metadata.Partition)
+	suite.Equal(int64(42), metadata.Offset)
+	suite.Equal("10", metadata.Key)
+	suite.Equal(suite.message.Timestamp, metadata.Timestamp)
+
+	header, ok := HeaderFromContext(ctx, HeaderCorrelationId)
+	suite.True(ok)
+	suite.Equal("checkout-1", header)
+	suite.Equal("checkout-1", CorrelationIdFromContext(ctx))
+}
+
+func (suite *ContextTestSuite) TestContext_WithoutMessage() {
+	_, ok := MetadataFromContext(context.Background())
+	suite.False(ok)
+
+	_, ok = HeaderFromContext(context.Background(), HeaderCorrelationId)
+	suite.False(ok)
+	suite.Equal("", CorrelationIdFromContext(context.Background()))
+}
+
+func (suite *ContextTestSuite) TestContext_GeneratedCorrelationId() {
+	suite.message.Headers = nil
+	suite.Regexp("^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$", CorrelationIdFromContext(WithMessage(context.Background(), suite.message)))
+
+	// the id is kept on the message so its retry and dead letter messages have the same one
+	setCorrelationId(suite.message)
+	correlationId := messageHeaders(suite.message)[HeaderCorrelationId]
+	suite.NotEmpty(correlationId)
+
+	setCorrelationId(suite.message)
+	suite.Len(suite.message.Headers, 1)
+	suite.Equal(correlationId, CorrelationIdFromContext(WithMessage(context.Background(), suite.message)))
+}
+
+func (suite *ContextTestSuite) TestContext_HappyCase_PropagationHeaders() {
+	suite.message.Headers = append(suite.message.Headers,
+		&sarama.RecordHeader{Key: []byte(HeaderTraceparent), Value: []byte("00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01")},
+		&sarama.RecordHeader{Key: []byte(HeaderTenant), Value: []byte("th")},
+		&sarama.RecordHeader{Key: []byte(HeaderOriginalTopic), Value: []byte("success.order")},
+	)
+
+	headers := PropagationHeaders(WithMessage(context.Background(), suite.message))
+	suite.Equal(map[string]string{
+		HeaderCorrelationId: "checkout-1",
+		HeaderTraceparent:   "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01",
+		HeaderTenant:        "th",
+	}, headers)
+
+	suite.Equal(map[string]string{HeaderCorrelationId: "checkout-2"}, PropagationHeaders(WithCorrelationId(context.Background(), "checkout-2")))
+	suite.Equal(map[string]string{}, PropagationHeaders(context.Background()))
+}
+
+func (suite *ContextTestSuite) TestContext_ConsumerProcess() {
+	consumer := NewConsumer(NewRouter(), nil, Timeouts{Handler: time.Minute, Processing: time.Minute})
+	route := &Route{RetryPolicy: RetryPolicy{MaxAttempts: 1}}
+
+	err := consumer.process(context.Background(), route, func(ctx context.Context, message *sarama.ConsumerMessage) error {
+		_, ok := ctx.Deadline()
+		suite.True(ok)
+		suite.Equal("checkout-1", CorrelationIdFromContext(ctx))
+		return nil
+	}, suite.message, nil)
+	suite.Nil(err)
+}
+
+func (suite *ContextTestSuite) TestContext_ConsumerProcessTimeout() {
+	consumer := NewConsumer(NewRouter(), nil, Timeouts{Handler: time.Minute, Processing: time.Millisecond * 10})
+	route := &Route{RetryPolicy: RetryPolicy{MaxAttempts: 5, InitialBackoff: time.Minute}}
+
+	err := consumer.process(context.Background(), route, func(context.Context, *sarama.ConsumerMessage) error {
+		return Retryable(errors.New("deadlock detected"))
+	}, suite.message, nil)
+	suite.ErrorIs(err, context.DeadlineExceeded)
+	suite.True(IsRetryable(err))
+}
+
+func (suite *ContextTestSuite) TestContext_ConsumerProcessAttemptTimeout() {
+	consumer := NewConsumer(NewRouter(), nil, Timeouts{Handler: time.Millisecond, Processing: time.Minute})
+	route := &Route{RetryPolicy: RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond}}
+
+	attempts := 0
+	err := consumer.process(context.Background(), route, func(ctx context.Context, message *sarama.ConsumerMessage) error {
+		attempts++
+		if attempts == 1 {
+			<-ctx.Done()
+			return Retryable(ctx.Err())
+		}
+		return ctx.Err()
+	}, suite.message, nil)
+	suite.Nil(err)
+	suite.Equal(2, attempts)
+}
+
+func (suite *ContextTestSuite) TestContext_ConsumerProcessSessionEnded() {
+	consumer := NewConsumer(NewRouter(), nil, Timeouts{Handler: time.Minute, Processing: time.Minute})
+	sessionCtx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := consumer.process(sessionCtx, &Route{RetryPolicy: RetryPolicy{MaxAttempts: 1}}, func(ctx context.Context, message *sarama.ConsumerMessage) error {
+		return ctx.Err()
+	}, suite.message, nil)
+	suite.ErrorIs(err, context.Canceled)
+}
+
+func TestContextTestSuite(t *testing.T) {
+	suite.Run(t, new(ContextTestSuite))
+}
@@ -0,0 +1,65 @@
+package kafka
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/IBM/sarama"
+	"github.com/pkg/errors"
+)
+
+// headers appended to a dead letter message, original headers are kept as is
+const (
+	HeaderOriginalTopic     = "x-original-topic"
+	HeaderOriginalPartition = "x-original-partition"
+	HeaderOriginalOffset    = "x-original-offset"
+	HeaderErrorReason       = "x-error-reason"
+)
+
+type DeadLetter interface {
+	SendDeadLetter(ctx context.Context, message *sarama.ConsumerMessage, reason error) error
+}
+
+type deadLetter struct {
+	producer Producer
+	topic    string
+}
+
+func NewDeadLetter(producer Producer, topic string) DeadLetter {
+	return &deadLetter{
+		producer: producer,
+		topic:    topic,
+	}
+}
+
+// SendDeadLetter sends message to the dead letter topic, with the kafka transaction of ctx if any
+func (deadLetter *deadLetter) SendDeadLetter(ctx context.Context, message *sarama.ConsumerMessage, reason error) error {
+	headers := messageHeaders(message)
+
+	// a message coming from a retry topic already carries its origin
+	if _, ok := headers[HeaderOriginalTopic]; !ok {
+		headers[HeaderOriginalTopic] = message.Topic
+		headers[HeaderOriginalPartition] = strconv.FormatInt(int64(message.Partition), 10)
+		headers[HeaderOriginalOffset] = strconv.FormatInt(message.Offset, 10)
+	}
+
+	headers[HeaderErrorReason] = reason.Error()
+
+	var schemaErr *SchemaError
+	if errors.As(reason, &schemaErr) {
+		headers[HeaderValidationErrors] = strings.Join(schemaErr.Problems, "; ")
+	}
+
+	producer := deadLetter.producer
+	if transaction, ok := TransactionFromContext(ctx); ok {
+		producer = transaction
+	}
+
+	err := producer.SendMessage(deadLetter.topic, string(message.Key), string(message.Value), headers)
+	if err != nil {
+		return errors.Wrap(err, "send dead letter message error")
+	}
+
+	return nil
+}
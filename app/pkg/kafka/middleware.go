@@ -0,0 +1,205 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"strconv"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// outcomes of a handled message
+const (
+	OutcomeSuccess = "success"
+	OutcomeRetry   = "retry"
+	OutcomeFailure = "failure"
+)
+
+var ErrHandlerPanic = errors.New("handler panic")
+
+func outcome(err error) string {
+	switch {
+	case err == nil:
+		return OutcomeSuccess
+	case IsRetryable(err):
+		return OutcomeRetry
+	default:
+		return OutcomeFailure
+	}
+}
+
+// Logging logs every handled message at debug level and every failed one at error level
+func Logging(logger *slog.Logger) Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, message *sarama.ConsumerMessage) error {
+			start := time.Now()
+			err := next(ctx, message)
+
+			attrs := []slog.Attr{
+				slog.String("topic", message.Topic),
+				slog.Int("partition", int(message.Partition)),
+				slog.Int64("offset", message.Offset),
+				slog.String("correlation_id", CorrelationIdFromContext(ctx)),
+				slog.String("outcome", outcome(err)),
+				slog.Duration("duration", time.Since(start)),
+			}
+
+			if err != nil {
+				attrs = append(attrs, slog.String("error", err.Error()))
+				logger.LogAttrs(ctx, slog.LevelError, "handle message error", attrs...)
+				return err
+			}
+
+			if logger.Enabled(ctx, slog.LevelDebug) {
+				attrs = append(attrs, slog.Any("headers", messageHeaders(message)), slog.String("value", string(message.Value)))
+				logger.LogAttrs(ctx, slog.LevelDebug, "handle message", attrs...)
+			}
+
+			return nil
+		}
+	}
+}
+
+// Recovery turns a panic of the handler into a permanent error, the message goes to the dead letter topic
+// instead of crashing the consumer
+func Recovery(logger *slog.Logger) Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, message *sarama.ConsumerMessage) (err error) {
+			defer func() {
+				recovered := recover()
+				if recovered == nil {
+					return
+				}
+
+				logger.ErrorContext(ctx, "handler panic",
+					slog.String("topic", message.Topic),
+					slog.Int64("offset", message.Offset),
+					slog.String("correlation_id", CorrelationIdFromContext(ctx)),
+					slog.Any("panic", recovered),
+					slog.String("stack", string(debug.Stack())),
+				)
+				err = fmt.Errorf("%w: %v", ErrHandlerPanic, recovered)
+			}()
+
+			return next(ctx, message)
+		}
+	}
+}
+
+// Timeout cancels the context of a handler attempt that runs longer than timeout
+func Timeout(timeout time.Duration) Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, message *sarama.ConsumerMessage) error {
+			ctx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+
+			return next(ctx, message)
+		}
+	}
+}
+
+// Metrics counts handled messages by topic and outcome, times their handling by topic, and keeps the lag
+// of the consumed partitions and the producer errors by topic
+type Metrics struct {
+	messages       *prometheus.CounterVec
+	duration       *prometheus.HistogramVec
+	lag            *prometheus.GaugeVec
+	producerErrors *prometheus.CounterVec
+}
+
+// NewMetrics registers the kafka metrics with registerer
+func NewMetrics(registerer prometheus.Registerer) *Metrics {
+	metrics := &Metrics{
+		messages: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "kafka_consumer_messages_total",
+			Help: "Handler attempts by topic and outcome, a message retried in process counts once per attempt.",
+		}, []string{"topic", "outcome"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "kafka_handler_duration_seconds",
+			Help:    "Handling time of a message attempt by topic.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"topic"}),
+		lag: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "kafka_consumer_lag",
+			Help: "Messages behind the high water mark by topic and partition.",
+		}, []string{"topic", "partition"}),
+		producerErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "kafka_producer_errors_total",
+			Help: "Messages the producer failed to send by topic.",
+		}, []string{"topic"}),
+	}
+	registerer.MustRegister(metrics.messages, metrics.duration, metrics.lag, metrics.producerErrors)
+
+	return metrics
+}
+
+// Middleware records the outcome and handling time of every message
+func (metrics *Metrics) Middleware() Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, message *sarama.ConsumerMessage) error {
+			start := time.Now()
+			err := next(ctx, message)
+
+			metrics.messages.WithLabelValues(message.Topic, outcome(err)).Inc()
+			metrics.duration.WithLabelValues(message.Topic).Observe(time.Since(start).Seconds())
+
+			return err
+		}
+	}
+}
+
+// ProducerError counts a message to topic that could not be sent, for the delivery callback of an async producer
+func (metrics *Metrics) ProducerError(topic string) {
+	metrics.producerErrors.WithLabelValues(topic).Inc()
+}
+
+// Producer returns producer counting the messages it fails to send
+func (metrics *Metrics) Producer(producer Producer) Producer {
+	return &instrumentedProducer{
+		Producer: producer,
+		metrics:  metrics,
+	}
+}
+
+// observeLag sets the lag of the partition of claim, the messages after message
+func (metrics *Metrics) observeLag(claim sarama.ConsumerGroupClaim, message *sarama.ConsumerMessage) {
+	lag := claim.HighWaterMarkOffset() - message.Offset - 1
+	if lag < 0 {
+		lag = 0
+	}
+
+	metrics.lag.WithLabelValues(message.Topic, strconv.FormatInt(int64(message.Partition), 10)).Set(float64(lag))
+}
+
+// forgetLag removes the lag of a partition the consumer does not own anymore
+func (metrics *Metrics) forgetLag(topic string, partition int32) {
+	metrics.lag.DeleteLabelValues(topic, strconv.FormatInt(int64(partition), 10))
+}
+
+type instrumentedProducer struct {
+	Producer
+	metrics *Metrics
+}
+
+func (producer *instrumentedProducer) SendMessage(topic string, key string, message string, headers map[string]string) error {
+	err := producer.Producer.SendMessage(topic, key, message, headers)
+	if err != nil {
+		producer.metrics.ProducerError(topic)
+	}
+
+	return err
+}
+
+func (producer *instrumentedProducer) SendMessageToPartition(topic string, partition int32, key string, message string, headers map[string]string) error {
+	err := producer.Producer.SendMessageToPartition(topic, partition, key, message, headers)
+	if err != nil {
+		producer.metrics.ProducerError(topic)
+	}
+
+	return err
+}
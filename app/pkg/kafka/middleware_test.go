@@ -0,0 +1,164 @@
+package kafka
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/suite"
+)
+
+type MiddlewareTestSuite struct {
+	suite.Suite
+	logs   *bytes.Buffer
+	logger *slog.Logger
+}
+
+func (suite *MiddlewareTestSuite) SetupTest() {
+	suite.logs = &bytes.Buffer{}
+	suite.logger = slog.New(slog.NewJSONHandler(suite.logs, &slog.HandlerOptions{Level: slog.LevelDebug}))
+}
+
+func (suite *MiddlewareTestSuite) message() *sarama.ConsumerMessage {
+	return &sarama.ConsumerMessage{Topic: "success.order", Partition: 1, Offset: 42, Value: []byte(`{"order_id":1}`)}
+}
+
+func (suite *MiddlewareTestSuite) TestMiddleware_HappyCase_Logging() {
+	handler := Logging(suite.logger)(func(context.Context, *sarama.ConsumerMessage) error { return nil })
+
+	err := handler(context.Background(), suite.message())
+	suite.Nil(err)
+
+	var entry map[string]interface{}
+	suite.Nil(json.Unmarshal(suite.logs.Bytes(), &entry))
+	suite.Equal("DEBUG", entry["level"])
+	suite.Equal("success.order", entry["topic"])
+	suite.Equal(float64(42), entry["offset"])
+	suite.Equal(OutcomeSuccess, entry["outcome"])
+	suite.Equal(`{"order_id":1}`, entry["value"])
+}
+
+func (suite *MiddlewareTestSuite) TestMiddleware_LoggingError() {
+	handler := Logging(suite.logger)(func(context.Context, *sarama.ConsumerMessage) error {
+		return Retryable(errors.New("deadlock detected"))
+	})
+
+	err := handler(context.Background(), suite.message())
+	suite.True(IsRetryable(err))
+
+	var entry map[string]interface{}
+	suite.Nil(json.Unmarshal(suite.logs.Bytes(), &entry))
+	suite.Equal("ERROR", entry["level"])
+	suite.Equal(OutcomeRetry, entry["outcome"])
+	suite.Equal("deadlock detected", entry["error"])
+	suite.NotContains(entry, "value")
+}
+
+func (suite *MiddlewareTestSuite) TestMiddleware_Recovery() {
+	handler := Recovery(suite.logger)(func(context.Context, *sarama.ConsumerMessage) error {
+		panic("nil map")
+	})
+
+	err := handler(context.Background(), suite.message())
+	suite.ErrorIs(err, ErrHandlerPanic)
+	suite.ErrorContains(err, "nil map")
+	suite.False(IsRetryable(err))
+	suite.Contains(suite.logs.String(), "handler panic")
+}
+
+func (suite *MiddlewareTestSuite) TestMiddleware_Timeout() {
+	handler := Timeout(time.Millisecond)(func(ctx context.Context, message *sarama.ConsumerMessage) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	err := handler(context.Background(), suite.message())
+	suite.ErrorIs(err, context.DeadlineExceeded)
+}
+
+func (suite *MiddlewareTestSuite) TestMiddleware_Metrics() {
+	registry := prometheus.NewRegistry()
+	metrics := NewMetrics(registry)
+	results := []error{nil, nil, Retryable(errors.New("timeout")), errors.New("invalid order")}
+	handler := metrics.Middleware()(func(context.Context, *sarama.ConsumerMessage) error {
+		err := results[0]
+		results = results[1:]
+		return err
+	})
+
+	for range []int{1, 2, 3, 4} {
+		_ = handler(context.Background(), suite.message())
+	}
+
+	suite.Equal(2.0, testutil.ToFloat64(metrics.messages.WithLabelValues("success.order", OutcomeSuccess)))
+	suite.Equal(1.0, testutil.ToFloat64(metrics.messages.WithLabelValues("success.order", OutcomeRetry)))
+	suite.Equal(1.0, testutil.ToFloat64(metrics.messages.WithLabelValues("success.order", OutcomeFailure)))
+	suite.Equal(0.0, testutil.ToFloat64(metrics.messages.WithLabelValues("order.cancelled", OutcomeSuccess)))
+
+	recorder := httptest.NewRecorder()
+	promhttp.HandlerFor(registry, promhttp.HandlerOpts{}).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	suite.Contains(recorder.Body.String(), `kafka_consumer_messages_total{outcome="success",topic="success.order"} 2`)
+	suite.Contains(recorder.Body.String(), `kafka_handler_duration_seconds_bucket{topic="success.order",le="+Inf"} 4`)
+	suite.Contains(recorder.Body.String(), `kafka_handler_duration_seconds_count{topic="success.order"} 4`)
+}
+
+func (suite *MiddlewareTestSuite) TestMiddleware_MetricsLag() {
+	metrics := NewMetrics(prometheus.NewRegistry())
+	claim := &fakeClaim{highWaterMark: 50}
+
+	metrics.observeLag(claim, suite.message())
+	suite.Equal(7.0, testutil.ToFloat64(metrics.lag.WithLabelValues("success.order", "1")))
+
+	// the last message of the partition leaves nothing behind
+	metrics.observeLag(claim, &sarama.ConsumerMessage{Topic: "success.order", Partition: 1, Offset: 49})
+	suite.Equal(0.0, testutil.ToFloat64(metrics.lag.WithLabelValues("success.order", "1")))
+}
+
+func (suite *MiddlewareTestSuite) TestMiddleware_MetricsProducerErrors() {
+	metrics := NewMetrics(prometheus.NewRegistry())
+	transaction := &fakeTransaction{}
+	producer := metrics.Producer(transaction)
+
+	suite.Nil(producer.SendMessage("decrease.point.success", "1", `{"order_id":1}`, nil))
+	suite.Equal(0.0, testutil.ToFloat64(metrics.producerErrors.WithLabelValues("decrease.point.success")))
+
+	producer = metrics.Producer(failingProducer{transaction})
+	suite.ErrorIs(producer.SendMessage("decrease.point.success", "1", `{"order_id":1}`, nil), sarama.ErrNotLeaderForPartition)
+	suite.ErrorIs(producer.SendMessageToPartition("decrease.point.success", 2, "1", `{"order_id":1}`, nil), sarama.ErrNotLeaderForPartition)
+	suite.Equal(2.0, testutil.ToFloat64(metrics.producerErrors.WithLabelValues("decrease.point.success")))
+}
+
+type fakeClaim struct {
+	sarama.ConsumerGroupClaim
+	highWaterMark int64
+}
+
+func (claim *fakeClaim) HighWaterMarkOffset() int64 {
+	return claim.highWaterMark
+}
+
+type failingProducer struct {
+	Producer
+}
+
+func (producer failingProducer) SendMessage(topic string, key string, message string, headers map[string]string) error {
+	return sarama.ErrNotLeaderForPartition
+}
+
+func (producer failingProducer) SendMessageToPartition(topic string, partition int32, key string, message string, headers map[string]string) error {
+	return sarama.ErrNotLeaderForPartition
+}
+
+func TestMiddlewareTestSuite(t *testing.T) {
+	suite.Run(t, new(MiddlewareTestSuite))
+}
@@ -0,0 +1,47 @@
+// Code generated by mockery v2.39.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	sarama "github.com/IBM/sarama"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// DeadLetter is an autogenerated mock type for the DeadLetter type
+type DeadLetter struct {
+	mock.Mock
+}
+
+// SendDeadLetter provides a mock function with given fields: ctx, message, reason
+func (_m *DeadLetter) SendDeadLetter(ctx context.Context, message *sarama.ConsumerMessage, reason error) error {
+	ret := _m.Called(ctx, message, reason)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SendDeadLetter")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *sarama.ConsumerMessage, error) error); ok {
+		r0 = rf(ctx, message, reason)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewDeadLetter creates a new instance of DeadLetter. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewDeadLetter(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *DeadLetter {
+	mock := &DeadLetter{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
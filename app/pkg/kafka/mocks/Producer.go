@@ -27,17 +27,53 @@ func (_m *Producer) CloseConnection() error {
 	return r0
 }
 
-// SendMessage provides a mock function with given fields: topic, message, headers
-func (_m *Producer) SendMessage(topic string, message string, headers map[string]string) error {
-	ret := _m.Called(topic, message, headers)
+// Flush provides a mock function with given fields:
+func (_m *Producer) Flush() error {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Flush")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func() error); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SendMessage provides a mock function with given fields: topic, key, message, headers
+func (_m *Producer) SendMessage(topic string, key string, message string, headers map[string]string) error {
+	ret := _m.Called(topic, key, message, headers)
 
 	if len(ret) == 0 {
 		panic("no return value specified for SendMessage")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(string, string, map[string]string) error); ok {
-		r0 = rf(topic, message, headers)
+	if rf, ok := ret.Get(0).(func(string, string, string, map[string]string) error); ok {
+		r0 = rf(topic, key, message, headers)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SendMessageToPartition provides a mock function with given fields: topic, partition, key, message, headers
+func (_m *Producer) SendMessageToPartition(topic string, partition int32, key string, message string, headers map[string]string) error {
+	ret := _m.Called(topic, partition, key, message, headers)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SendMessageToPartition")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, int32, string, string, map[string]string) error); ok {
+		r0 = rf(topic, partition, key, message, headers)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -0,0 +1,147 @@
+package kafka
+
+import (
+	"github.com/IBM/sarama"
+)
+
+// Partitioner is how the partition of a message without an explicit partition is picked
+type Partitioner string
+
+const (
+	// HashPartitioner hashes the key with fnv-1a, the sarama default
+	HashPartitioner Partitioner = "hash"
+	// Murmur2Partitioner hashes the key like the java client, so keys land where java producers put them
+	Murmur2Partitioner Partitioner = "murmur2"
+	// RoundRobinPartitioner ignores the key and walks through the partitions
+	RoundRobinPartitioner Partitioner = "round_robin"
+)
+
+// AnyPartition lets the partitioner pick the partition of a message
+const AnyPartition int32 = -1
+
+func (partitioner Partitioner) Valid() bool {
+	switch partitioner {
+	case HashPartitioner, Murmur2Partitioner, RoundRobinPartitioner:
+		return true
+	default:
+		return false
+	}
+}
+
+// constructor returns the sarama partitioner, wrapped so a message with an explicit partition keeps it
+func (partitioner Partitioner) constructor() sarama.PartitionerConstructor {
+	var constructor sarama.PartitionerConstructor
+	switch partitioner {
+	case Murmur2Partitioner:
+		constructor = newMurmur2Partitioner
+	case RoundRobinPartitioner:
+		constructor = sarama.NewRoundRobinPartitioner
+	default:
+		constructor = sarama.NewHashPartitioner
+	}
+
+	return func(topic string) sarama.Partitioner {
+		return explicitPartitioner{partitioner: constructor(topic)}
+	}
+}
+
+type explicitPartitioner struct {
+	partitioner sarama.Partitioner
+}
+
+func (partitioner explicitPartitioner) Partition(message *sarama.ProducerMessage, numPartitions int32) (int32, error) {
+	if message.Partition != AnyPartition {
+		return message.Partition, nil
+	}
+
+	return partitioner.partitioner.Partition(message, numPartitions)
+}
+
+func (partitioner explicitPartitioner) RequiresConsistency() bool {
+	return partitioner.partitioner.RequiresConsistency()
+}
+
+// MessageRequiresConsistency makes sarama pick among every partition of the topic, not only the writable
+// ones, so an explicit partition or a key always maps to the same partition
+func (partitioner explicitPartitioner) MessageRequiresConsistency(message *sarama.ProducerMessage) bool {
+	if message.Partition != AnyPartition {
+		return true
+	}
+
+	if dynamic, ok := partitioner.partitioner.(sarama.DynamicConsistencyPartitioner); ok {
+		return dynamic.MessageRequiresConsistency(message)
+	}
+
+	return partitioner.partitioner.RequiresConsistency()
+}
+
+// murmur2Partitioner is the default partitioner of the java client, a message without a key
+// goes to a random partition
+type murmur2Partitioner struct {
+	random sarama.Partitioner
+}
+
+func newMurmur2Partitioner(topic string) sarama.Partitioner {
+	return murmur2Partitioner{random: sarama.NewRandomPartitioner(topic)}
+}
+
+func (partitioner murmur2Partitioner) Partition(message *sarama.ProducerMessage, numPartitions int32) (int32, error) {
+	if message.Key == nil {
+		return partitioner.random.Partition(message, numPartitions)
+	}
+
+	key, err := message.Key.Encode()
+	if err != nil {
+		return AnyPartition, err
+	}
+
+	return int32(murmur2(key)&0x7fffffff) % numPartitions, nil
+}
+
+func (partitioner murmur2Partitioner) RequiresConsistency() bool {
+	return true
+}
+
+func (partitioner murmur2Partitioner) MessageRequiresConsistency(message *sarama.ProducerMessage) bool {
+	return message.Key != nil
+}
+
+// murmur2 is org.apache.kafka.common.utils.Utils.murmur2
+func murmur2(data []byte) uint32 {
+	const (
+		seed uint32 = 0x9747b28c
+		m    uint32 = 0x5bd1e995
+		r           = 24
+	)
+
+	length := len(data)
+	h := seed ^ uint32(length)
+
+	for i := 0; i+4 <= length; i += 4 {
+		k := uint32(data[i]) | uint32(data[i+1])<<8 | uint32(data[i+2])<<16 | uint32(data[i+3])<<24
+		k *= m
+		k ^= k >> r
+		k *= m
+		h *= m
+		h ^= k
+	}
+
+	tail := data[length&^3:]
+	switch len(tail) {
+	case 3:
+		h ^= uint32(tail[2]) << 16
+		fallthrough
+	case 2:
+		h ^= uint32(tail[1]) << 8
+		fallthrough
+	case 1:
+		h ^= uint32(tail[0])
+		h *= m
+	}
+
+	h ^= h >> 13
+	h *= m
+	h ^= h >> 15
+
+	return h
+}
@@ -0,0 +1,74 @@
+package kafka
+
+import (
+	"testing"
+
+	"github.com/IBM/sarama"
+	"github.com/stretchr/testify/suite"
+)
+
+type PartitionerTestSuite struct {
+	suite.Suite
+}
+
+func (suite *PartitionerTestSuite) TestPartitioner_HappyCase_Murmur2() {
+	// the cases of the java client UtilsTest
+	cases := map[string]int32{
+		"21":                         -973932308,
+		"foobar":                     -790332482,
+		"a-little-bit-long-string":   -985981536,
+		"a-little-bit-longer-string": -1486304829,
+		"lkjh234lh9fiuh90y23oiuhsafujhadof229phr9h19h89h8": -58897971,
+		"abc": 479470107,
+	}
+
+	for key, expected := range cases {
+		suite.Equal(expected, int32(murmur2([]byte(key))), key)
+	}
+}
+
+func (suite *PartitionerTestSuite) TestPartitioner_Murmur2Key() {
+	partitioner := Murmur2Partitioner.constructor()("decrease.point.success")
+
+	for _, key := range []string{"1", "42", "1000"} {
+		message := &sarama.ProducerMessage{Key: sarama.StringEncoder(key), Partition: AnyPartition}
+		first, err := partitioner.Partition(message, 12)
+		suite.Nil(err)
+		suite.Equal(int32(murmur2([]byte(key))&0x7fffffff)%12, first)
+
+		again, _ := partitioner.Partition(message, 12)
+		suite.Equal(first, again)
+	}
+}
+
+func (suite *PartitionerTestSuite) TestPartitioner_ExplicitPartition() {
+	for _, name := range []Partitioner{HashPartitioner, Murmur2Partitioner, RoundRobinPartitioner} {
+		partitioner := name.constructor()("decrease.point.success")
+		message := &sarama.ProducerMessage{Key: sarama.StringEncoder("1"), Partition: 3}
+
+		partition, err := partitioner.Partition(message, 12)
+		suite.Nil(err)
+		suite.Equal(int32(3), partition, name)
+		suite.True(partitioner.(sarama.DynamicConsistencyPartitioner).MessageRequiresConsistency(message), name)
+	}
+}
+
+func (suite *PartitionerTestSuite) TestPartitioner_RoundRobin() {
+	partitioner := RoundRobinPartitioner.constructor()("decrease.point.success")
+
+	partitions := []int32{}
+	for range []int{1, 2, 3, 4} {
+		partition, _ := partitioner.Partition(&sarama.ProducerMessage{Key: sarama.StringEncoder("1"), Partition: AnyPartition}, 3)
+		partitions = append(partitions, partition)
+	}
+	suite.Equal([]int32{0, 1, 2, 0}, partitions)
+}
+
+func (suite *PartitionerTestSuite) TestPartitioner_Valid() {
+	suite.True(Murmur2Partitioner.Valid())
+	suite.False(Partitioner("random").Valid())
+}
+
+func TestPartitionerTestSuite(t *testing.T) {
+	suite.Run(t, new(PartitionerTestSuite))
+}
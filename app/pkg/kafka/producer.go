@@ -8,8 +8,13 @@ import (
 	"github.com/pkg/errors"
 )
 
+// Producer sends messages of the same key to the same partition, an empty key lets the partitioner
+// spread the messages
 type Producer interface {
-	SendMessage(topic string, message string, headers map[string]string) error
+	SendMessage(topic string, key string, message string, headers map[string]string) error
+	SendMessageToPartition(topic string, partition int32, key string, message string, headers map[string]string) error
+	// Flush waits for the messages sent so far and returns the first one that failed
+	Flush() error
 	CloseConnection() error
 }
 
@@ -18,9 +23,10 @@ type producer struct {
 	logEnable    bool
 }
 
-func NewProducer(addresses []string) (Producer, error) {
+func NewProducer(addresses []string, partitioner Partitioner) (Producer, error) {
 	kafkaConfig := sarama.NewConfig()
 	kafkaConfig.Version = sarama.DefaultVersion
+	kafkaConfig.Producer.Partitioner = partitioner.constructor()
 	kafkaConfig.Producer.RequiredAcks = sarama.WaitForAll
 	kafkaConfig.Producer.Retry.Max = 3
 	kafkaConfig.Producer.Return.Successes = true
@@ -35,35 +41,24 @@ func NewProducer(addresses []string) (Producer, error) {
 	}, nil
 }
 
-func (producer producer) SendMessage(topic string, message string, headers map[string]string) error {
-	if headers == nil {
-		headers = map[string]string{}
-	}
+func (producer producer) SendMessage(topic string, key string, message string, headers map[string]string) error {
+	return producer.SendMessageToPartition(topic, AnyPartition, key, message, headers)
+}
 
-	recordHeaders := []sarama.RecordHeader{}
-	for keyHeader, valueHeader := range headers {
-		recordHeaders = append(recordHeaders, sarama.RecordHeader{
-			Key:   []byte(keyHeader),
-			Value: []byte(valueHeader),
-		})
-	}
-
-	producerMessage := &sarama.ProducerMessage{
-		Topic:     topic,
-		Value:     sarama.StringEncoder(message),
-		Headers:   recordHeaders,
-		Timestamp: time.Now(),
-	}
+func (producer producer) SendMessageToPartition(topic string, partition int32, key string, message string, headers map[string]string) error {
+	producerMessage := newProducerMessage(topic, partition, key, message, headers)
 
 	partition, offset, err := producer.SyncProducer.SendMessage(producerMessage)
 	if err != nil {
-		return errors.Wrap(err, "send message error")
+		// a failed send is worth retrying, sarama already gave up on its own retries
+		return Retryable(errors.Wrap(err, "send message error"))
 	}
 
 	// message logging
 	if producer.logEnable {
-		log.Printf("produce message: headers = %+v, value = %s, timestamp = %s, partition = %d, offset = %d\n",
-			headers,
+		log.Printf("produce message: key = %s, headers = %+v, value = %s, timestamp = %s, partition = %d, offset = %d\n",
+			key,
+			producerMessage.Headers,
 			message,
 			producerMessage.Timestamp.Format(time.RFC3339),
 			partition,
@@ -74,6 +69,34 @@ func (producer producer) SendMessage(topic string, message string, headers map[s
 	return nil
 }
 
+// Flush has nothing to wait for, SendMessage returns once the message is written
+func (producer producer) Flush() error {
+	return nil
+}
+
 func (producer producer) CloseConnection() error {
 	return producer.SyncProducer.Close()
 }
+
+func newProducerMessage(topic string, partition int32, key string, message string, headers map[string]string) *sarama.ProducerMessage {
+	recordHeaders := []sarama.RecordHeader{}
+	for keyHeader, valueHeader := range headers {
+		recordHeaders = append(recordHeaders, sarama.RecordHeader{
+			Key:   []byte(keyHeader),
+			Value: []byte(valueHeader),
+		})
+	}
+
+	producerMessage := &sarama.ProducerMessage{
+		Topic:     topic,
+		Partition: partition,
+		Value:     sarama.StringEncoder(message),
+		Headers:   recordHeaders,
+		Timestamp: time.Now(),
+	}
+	if key != "" {
+		producerMessage.Key = sarama.StringEncoder(key)
+	}
+
+	return producerMessage
+}
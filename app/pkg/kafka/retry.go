@@ -0,0 +1,86 @@
+package kafka
+
+import (
+	"math"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const HeaderRetryAttempt = "x-retry-attempt"
+
+type retryableError struct {
+	err error
+}
+
+func (e retryableError) Error() string {
+	return e.err.Error()
+}
+
+func (e retryableError) Unwrap() error {
+	return e.err
+}
+
+// Retryable marks err as transient, the consumer retries it instead of sending it to the dead letter topic.
+func Retryable(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return retryableError{err: err}
+}
+
+func IsRetryable(err error) bool {
+	var target retryableError
+	return errors.As(err, &target)
+}
+
+// RetryTopic delays a message by Delay before it is handled again.
+type RetryTopic struct {
+	Topic string
+	Delay time.Duration
+}
+
+// RetryPolicy retries a transient error in-process MaxAttempts times with exponential backoff,
+// then passes the message through RetryTopics in order before it ends up in the dead letter topic.
+type RetryPolicy struct {
+	MaxAttempts    uint
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	Multiplier     float64
+	RetryTopics    []RetryTopic
+}
+
+// Backoff returns the wait before the given in-process attempt, attempt starts at 1.
+func (policy RetryPolicy) Backoff(attempt uint) time.Duration {
+	if attempt <= 1 {
+		return policy.InitialBackoff
+	}
+
+	backoff := float64(policy.InitialBackoff) * math.Pow(policy.Multiplier, float64(attempt-1))
+	if policy.MaxBackoff > 0 && backoff > float64(policy.MaxBackoff) {
+		return policy.MaxBackoff
+	}
+
+	return time.Duration(backoff)
+}
+
+func (policy RetryPolicy) Topics() []string {
+	topics := []string{}
+	for _, retryTopic := range policy.RetryTopics {
+		topics = append(topics, retryTopic.Topic)
+	}
+
+	return topics
+}
+
+// tier returns the index of topic in RetryTopics, or -1 for a topic that is not a retry topic.
+func (policy RetryPolicy) tier(topic string) int {
+	for i, retryTopic := range policy.RetryTopics {
+		if retryTopic.Topic == topic {
+			return i
+		}
+	}
+
+	return -1
+}
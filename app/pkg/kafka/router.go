@@ -0,0 +1,113 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/IBM/sarama"
+	"github.com/pkg/errors"
+)
+
+// HandlerFunc handles one message, an error marked Retryable is retried by the route retry policy
+type HandlerFunc func(ctx context.Context, message *sarama.ConsumerMessage) error
+
+// Middleware wraps a handler with behavior shared by many handlers
+type Middleware func(next HandlerFunc) HandlerFunc
+
+// Decoder reads a message value into v
+type Decoder func(data []byte, v interface{}) error
+
+// JSONDecoder decodes a json message value
+var JSONDecoder Decoder = json.Unmarshal
+
+// Decode turns a handler of a decoded value into a HandlerFunc, a value the decoder
+// cannot read is a permanent error
+func Decode[T any](decoder Decoder, handle func(ctx context.Context, value T) error) HandlerFunc {
+	return func(ctx context.Context, message *sarama.ConsumerMessage) error {
+		var value T
+		err := decoder(message.Value, &value)
+		if err != nil {
+			return errors.Wrap(err, "decode message value error")
+		}
+
+		return handle(ctx, value)
+	}
+}
+
+// Route is how the messages of a topic are handled. A message still failing after
+// RetryPolicy goes to DeadLetter, Middlewares only wrap the handler of this route.
+type Route struct {
+	Topic       string
+	Handler     HandlerFunc
+	RetryPolicy RetryPolicy
+	DeadLetter  DeadLetter
+	Middlewares []Middleware
+}
+
+// Router dispatches messages of many topics, and of their retry topics, to the route of the topic
+type Router struct {
+	middlewares []Middleware
+	routes      []*Route
+	topics      map[string]*Route
+}
+
+func NewRouter() *Router {
+	return &Router{
+		topics: map[string]*Route{},
+	}
+}
+
+// Use adds middlewares wrapping every route, the first one added is the outermost
+func (router *Router) Use(middlewares ...Middleware) {
+	router.middlewares = append(router.middlewares, middlewares...)
+}
+
+// Handle adds a route, a topic or retry topic can only belong to one route
+func (router *Router) Handle(route Route) error {
+	if route.Handler == nil || route.DeadLetter == nil {
+		return errors.Errorf("route of topic %s needs a handler and a dead letter", route.Topic)
+	}
+
+	topics := append([]string{route.Topic}, route.RetryPolicy.Topics()...)
+	for _, topic := range topics {
+		if _, ok := router.topics[topic]; ok {
+			return errors.Errorf("topic %s already has a route", topic)
+		}
+	}
+
+	for _, topic := range topics {
+		router.topics[topic] = &route
+	}
+	router.routes = append(router.routes, &route)
+
+	return nil
+}
+
+// Topics returns every topic to subscribe to, retry topics included
+func (router *Router) Topics() []string {
+	topics := []string{}
+	for _, route := range router.routes {
+		topics = append(topics, route.Topic)
+		topics = append(topics, route.RetryPolicy.Topics()...)
+	}
+
+	return topics
+}
+
+// match returns the route of topic and its handler wrapped in the router and route middlewares
+func (router *Router) match(topic string) (*Route, HandlerFunc, bool) {
+	route, ok := router.topics[topic]
+	if !ok {
+		return nil, nil, false
+	}
+
+	handler := route.Handler
+	for i := len(route.Middlewares) - 1; i >= 0; i-- {
+		handler = route.Middlewares[i](handler)
+	}
+	for i := len(router.middlewares) - 1; i >= 0; i-- {
+		handler = router.middlewares[i](handler)
+	}
+
+	return route, handler, true
+}
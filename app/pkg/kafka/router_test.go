@@ -0,0 +1,99 @@
+package kafka
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/stretchr/testify/suite"
+)
+
+type RouterTestSuite struct {
+	suite.Suite
+	router *Router
+	calls  []string
+}
+
+type noDeadLetter struct{}
+
+func (noDeadLetter) SendDeadLetter(context.Context, *sarama.ConsumerMessage, error) error {
+	return nil
+}
+
+func (suite *RouterTestSuite) record(name string) Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, message *sarama.ConsumerMessage) error {
+			suite.calls = append(suite.calls, name)
+			return next(ctx, message)
+		}
+	}
+}
+
+func (suite *RouterTestSuite) SetupTest() {
+	suite.calls = nil
+	suite.router = NewRouter()
+	suite.router.Use(suite.record("first"), suite.record("second"))
+
+	err := suite.router.Handle(Route{
+		Topic: "success.order",
+		Handler: Decode(JSONDecoder, func(ctx context.Context, value struct {
+			OrderId uint `json:"order_id"`
+		}) error {
+			suite.calls = append(suite.calls, "success.order")
+			return nil
+		}),
+		RetryPolicy: RetryPolicy{RetryTopics: []RetryTopic{{Topic: "success.order.retry.5s", Delay: time.Second * 5}}},
+		DeadLetter:  noDeadLetter{},
+		Middlewares: []Middleware{suite.record("route")},
+	})
+	suite.Nil(err)
+}
+
+func (suite *RouterTestSuite) TestRouter_HappyCase_Match() {
+	for _, topic := range []string{"success.order", "success.order.retry.5s"} {
+		suite.calls = nil
+		route, handler, ok := suite.router.match(topic)
+		suite.True(ok)
+		suite.Equal("success.order", route.Topic)
+
+		err := handler(context.Background(), &sarama.ConsumerMessage{Topic: topic, Value: []byte(`{"order_id":1}`)})
+		suite.Nil(err)
+		suite.Equal([]string{"first", "second", "route", "success.order"}, suite.calls)
+	}
+}
+
+func (suite *RouterTestSuite) TestRouter_UnknownTopic() {
+	_, _, ok := suite.router.match("order.cancelled")
+	suite.False(ok)
+}
+
+func (suite *RouterTestSuite) TestRouter_DecodeError() {
+	_, handler, _ := suite.router.match("success.order")
+
+	err := handler(context.Background(), &sarama.ConsumerMessage{Value: []byte("invalid body")})
+	suite.ErrorContains(err, "decode message value error")
+	suite.False(IsRetryable(err))
+	suite.NotContains(suite.calls, "success.order")
+}
+
+func (suite *RouterTestSuite) TestRouter_Topics() {
+	err := suite.router.Handle(Route{Topic: "order.cancelled", Handler: func(context.Context, *sarama.ConsumerMessage) error { return nil }, DeadLetter: noDeadLetter{}})
+	suite.Nil(err)
+	suite.Equal([]string{"success.order", "success.order.retry.5s", "order.cancelled"}, suite.router.Topics())
+}
+
+func (suite *RouterTestSuite) TestRouter_DuplicateTopic() {
+	handler := func(context.Context, *sarama.ConsumerMessage) error { return nil }
+
+	err := suite.router.Handle(Route{Topic: "success.order.retry.5s", Handler: handler, DeadLetter: noDeadLetter{}})
+	suite.ErrorContains(err, "topic success.order.retry.5s already has a route")
+
+	err = suite.router.Handle(Route{Topic: "order.refunded", Handler: handler})
+	suite.ErrorContains(err, "needs a handler and a dead letter")
+	suite.Equal([]string{"success.order", "success.order.retry.5s"}, suite.router.Topics())
+}
+
+func TestRouterTestSuite(t *testing.T) {
+	suite.Run(t, new(RouterTestSuite))
+}
@@ -0,0 +1,388 @@
+package kafka
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/linkedin/goavro/v2"
+	"github.com/pkg/errors"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+// SchemaFormat is how the messages of a subject are written
+type SchemaFormat string
+
+const (
+	// JSONSchemaFormat messages are json validated against a JSON Schema, the schema version is the
+	// version field of the message, a message without version is version 1
+	JSONSchemaFormat SchemaFormat = "json"
+	// AvroFormat messages are in the avro single object encoding, the schema is found by the
+	// fingerprint of the message
+	AvroFormat SchemaFormat = "avro"
+)
+
+// HeaderValidationErrors is set on a dead letter message that failed its schema
+const HeaderValidationErrors = "x-validation-errors"
+
+var ErrInvalidMessage = errors.New("message does not match its schema")
+
+// SchemaError lists every problem of a message that does not match its schema
+type SchemaError struct {
+	Subject  string
+	Version  int
+	Problems []string
+}
+
+func (err *SchemaError) Error() string {
+	return fmt.Sprintf("%s: %s v%d: %s", ErrInvalidMessage.Error(), err.Subject, err.Version, strings.Join(err.Problems, "; "))
+}
+
+func (err *SchemaError) Unwrap() error {
+	return ErrInvalidMessage
+}
+
+// Encoder writes the value of a message sent to topic
+type Encoder func(topic string, v interface{}) ([]byte, error)
+
+// JSONEncoder encodes a json message value whatever the topic
+var JSONEncoder Encoder = func(topic string, v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+// Schema is one version of the schema of a subject
+type Schema struct {
+	Subject string
+	Version int
+	Format  SchemaFormat
+
+	json *jsonschema.Schema
+	avro *goavro.Codec
+	// avroJSON converts between plain json and the values of avro, a union value is not wrapped in
+	// its type. Its binary decoding picks the wrong branch of a union, so avro does the binary.
+	avroJSON *goavro.Codec
+}
+
+// Registry holds the schemas of the subjects, the subject of a message is its topic
+type Registry struct {
+	subjects map[string]map[int]*Schema
+}
+
+var schemaFilePattern = regexp.MustCompile(`^v([1-9][0-9]*)\.(json|avsc)$`)
+
+func NewRegistry() *Registry {
+	return &Registry{
+		subjects: map[string]map[int]*Schema{},
+	}
+}
+
+// NewFileRegistry reads the schemas of dir, <dir>/<subject>/v<version>.json is a JSON Schema and
+// <dir>/<subject>/v<version>.avsc an avro schema. Every version of a subject has the same format.
+func NewFileRegistry(dir string) (*Registry, error) {
+	registry := NewRegistry()
+
+	paths, err := filepath.Glob(filepath.Join(dir, "*", "v*"))
+	if err != nil {
+		return nil, errors.Wrap(err, "list schema files error")
+	}
+
+	for _, path := range paths {
+		match := schemaFilePattern.FindStringSubmatch(filepath.Base(path))
+		if match == nil {
+			continue
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, errors.Wrap(err, "read schema file error")
+		}
+
+		version, _ := strconv.Atoi(match[1])
+		format := JSONSchemaFormat
+		if match[2] == "avsc" {
+			format = AvroFormat
+		}
+
+		err = registry.Register(filepath.Base(filepath.Dir(path)), version, format, content)
+		if err != nil {
+			return nil, errors.Wrapf(err, "schema %s", path)
+		}
+	}
+
+	return registry, nil
+}
+
+// Register adds a version of the schema of subject
+func (registry *Registry) Register(subject string, version int, format SchemaFormat, content []byte) error {
+	schema := &Schema{Subject: subject, Version: version, Format: format}
+
+	var err error
+	switch format {
+	case JSONSchemaFormat:
+		schema.json, err = compileJSONSchema(subject, version, content)
+	case AvroFormat:
+		schema.avro, schema.avroJSON, err = parseAvroSchema(content)
+	default:
+		err = errors.Errorf("schema format %s is not supported", format)
+	}
+	if err != nil {
+		return err
+	}
+
+	versions, ok := registry.subjects[subject]
+	if !ok {
+		versions = map[int]*Schema{}
+		registry.subjects[subject] = versions
+	}
+	for _, other := range versions {
+		if other.Format != format {
+			return errors.Errorf("subject %s mixes %s and %s schemas", subject, other.Format, format)
+		}
+	}
+	versions[version] = schema
+
+	return nil
+}
+
+// Latest returns the highest version of the schema of subject
+func (registry *Registry) Latest(subject string) (*Schema, bool) {
+	versions := []int{}
+	for version := range registry.subjects[subject] {
+		versions = append(versions, version)
+	}
+	if len(versions) == 0 {
+		return nil, false
+	}
+	sort.Ints(versions)
+
+	return registry.subjects[subject][versions[len(versions)-1]], true
+}
+
+func (registry *Registry) schema(subject string, version int) (*Schema, error) {
+	schema, ok := registry.subjects[subject][version]
+	if !ok {
+		return nil, &SchemaError{Subject: subject, Version: version, Problems: []string{"unknown schema version"}}
+	}
+
+	return schema, nil
+}
+
+// Decoder returns the decoder of the messages of subject, a message that does not match its schema
+// is a SchemaError. A subject without schema is plain json.
+func (registry *Registry) Decoder(subject string) Decoder {
+	latest, ok := registry.Latest(subject)
+	if !ok {
+		return JSONDecoder
+	}
+
+	return func(data []byte, v interface{}) error {
+		var err error
+		if latest.Format == AvroFormat {
+			data, err = registry.decodeAvro(subject, data)
+		} else {
+			err = registry.validateJSON(subject, data)
+		}
+		if err != nil {
+			return err
+		}
+
+		// the validated value is decoded into v through json, field names are the json names
+		return json.Unmarshal(data, v)
+	}
+}
+
+// Encode writes v with the schema of topic, the latest version for avro and the version of v for
+// json, a topic without schema is plain json
+func (registry *Registry) Encode(topic string, v interface{}) ([]byte, error) {
+	content, err := json.Marshal(v)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshal message error")
+	}
+
+	latest, ok := registry.Latest(topic)
+	if !ok {
+		return content, nil
+	}
+
+	if latest.Format == JSONSchemaFormat {
+		err = registry.validateJSON(topic, content)
+		if err != nil {
+			return nil, err
+		}
+
+		return content, nil
+	}
+
+	native, _, err := latest.avroJSON.NativeFromTextual(content)
+	if err != nil {
+		return nil, &SchemaError{Subject: topic, Version: latest.Version, Problems: []string{"invalid avro: " + err.Error()}}
+	}
+
+	payload, err := latest.avro.SingleFromNative(nil, native)
+	if err != nil {
+		return nil, &SchemaError{Subject: topic, Version: latest.Version, Problems: []string{"invalid avro: " + err.Error()}}
+	}
+
+	return payload, nil
+}
+
+// Format returns the format of the schemas of subject
+func (registry *Registry) Format(subject string) (SchemaFormat, bool) {
+	latest, ok := registry.Latest(subject)
+	if !ok {
+		return "", false
+	}
+
+	return latest.Format, true
+}
+
+func (registry *Registry) validateJSON(subject string, data []byte) error {
+	value, err := decodeJSON(data)
+	if err != nil {
+		return &SchemaError{Subject: subject, Problems: []string{"invalid json: " + err.Error()}}
+	}
+
+	schema, err := registry.schema(subject, jsonVersion(value))
+	if err != nil {
+		return err
+	}
+
+	err = schema.json.Validate(value)
+	if err != nil {
+		return &SchemaError{Subject: subject, Version: schema.Version, Problems: validationProblems(err)}
+	}
+
+	return nil
+}
+
+// decodeAvro returns the json of an avro message, the schema version is the one whose fingerprint
+// the message carries
+func (registry *Registry) decodeAvro(subject string, data []byte) ([]byte, error) {
+	fingerprint, _, err := goavro.FingerprintFromSOE(data)
+	if err != nil {
+		return nil, &SchemaError{Subject: subject, Problems: []string{"invalid avro: " + err.Error()}}
+	}
+
+	var schema *Schema
+	for _, candidate := range registry.subjects[subject] {
+		if candidate.avro.Rabin == fingerprint {
+			schema = candidate
+		}
+	}
+	if schema == nil {
+		return nil, &SchemaError{Subject: subject, Problems: []string{fmt.Sprintf("unknown schema fingerprint %016x", fingerprint)}}
+	}
+
+	native, rest, err := schema.avro.NativeFromSingle(data)
+	if err == nil && len(rest) > 0 {
+		err = errors.Errorf("%d bytes after the value", len(rest))
+	}
+	if err != nil {
+		return nil, &SchemaError{Subject: subject, Version: schema.Version, Problems: []string{"invalid avro: " + err.Error()}}
+	}
+
+	content, err := schema.avroJSON.TextualFromNative(nil, native)
+	if err != nil {
+		return nil, &SchemaError{Subject: subject, Version: schema.Version, Problems: []string{"invalid avro: " + err.Error()}}
+	}
+
+	return content, nil
+}
+
+func parseAvroSchema(content []byte) (*goavro.Codec, *goavro.Codec, error) {
+	codec, err := goavro.NewCodec(string(content))
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "parse avro schema error")
+	}
+
+	jsonCodec, err := goavro.NewCodecForStandardJSONFull(string(content))
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "parse avro schema error")
+	}
+
+	return codec, jsonCodec, nil
+}
+
+func compileJSONSchema(subject string, version int, content []byte) (*jsonschema.Schema, error) {
+	url := fmt.Sprintf("file:///%s/v%d.json", subject, version)
+
+	compiler := jsonschema.NewCompiler()
+	err := compiler.AddResource(url, bytes.NewReader(content))
+	if err != nil {
+		return nil, errors.Wrap(err, "parse json schema error")
+	}
+
+	schema, err := compiler.Compile(url)
+	if err != nil {
+		return nil, errors.Wrap(err, "compile json schema error")
+	}
+
+	return schema, nil
+}
+
+// validationProblems lists the failed rules of err by the location of the value, sorted
+func validationProblems(err error) []string {
+	var validationErr *jsonschema.ValidationError
+	if !errors.As(err, &validationErr) {
+		return []string{err.Error()}
+	}
+
+	var problems []string
+	var walk func(validationErr *jsonschema.ValidationError)
+	walk = func(validationErr *jsonschema.ValidationError) {
+		if len(validationErr.Causes) == 0 {
+			problems = append(problems, pointer(validationErr.InstanceLocation)+": "+validationErr.Message)
+			return
+		}
+		for _, cause := range validationErr.Causes {
+			walk(cause)
+		}
+	}
+	walk(validationErr)
+	sort.Strings(problems)
+
+	return problems
+}
+
+// decodeJSON decodes data keeping numbers as json.Number, so integers are not rounded
+func decodeJSON(data []byte) (interface{}, error) {
+	var value interface{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	err := decoder.Decode(&value)
+	if err != nil {
+		return nil, err
+	}
+
+	return value, nil
+}
+
+func pointer(path string) string {
+	if path == "" {
+		return "/"
+	}
+
+	return path
+}
+
+// jsonVersion is the version field of a json message, 1 when it has none
+func jsonVersion(value interface{}) int {
+	object, _ := value.(map[string]interface{})
+	number, ok := object["version"].(json.Number)
+	if !ok {
+		return 1
+	}
+
+	version, err := strconv.Atoi(number.String())
+	if err != nil {
+		return 0
+	}
+
+	return version
+}
@@ -0,0 +1,222 @@
+package kafka
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/IBM/sarama"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/suite"
+)
+
+const orderAvroSchema = `{
+	"type": "record",
+	"name": "SuccessOrder",
+	"fields": [
+		{"name": "order_id", "type": "long"},
+		{"name": "user_id", "type": ["null", "long"], "default": null},
+		{"name": "channel", "type": {"type": "enum", "name": "Channel", "symbols": ["web", "store"]}, "default": "web"},
+		{"name": "items", "type": {"type": "array", "items": {
+			"type": "record",
+			"name": "OrderItem",
+			"fields": [
+				{"name": "product_id", "type": "long"},
+				{"name": "quantity", "type": "int"},
+				{"name": "unit_price", "type": ["null", "double"], "default": null}
+			]
+		}}}
+	]
+}`
+
+type testOrderItem struct {
+	ProductId uint     `json:"product_id"`
+	Quantity  uint     `json:"quantity"`
+	UnitPrice *float64 `json:"unit_price,omitempty"`
+}
+
+type testOrder struct {
+	Version   uint            `json:"version,omitempty"`
+	OrderId   uint            `json:"order_id"`
+	UserId    uint            `json:"user_id,omitempty"`
+	ProductId uint            `json:"product_id,omitempty"`
+	Channel   string          `json:"channel,omitempty"`
+	Items     []testOrderItem `json:"items,omitempty"`
+}
+
+type SchemaTestSuite struct {
+	suite.Suite
+	registry *Registry
+}
+
+func (suite *SchemaTestSuite) SetupTest() {
+	// the schemas shipped with the service
+	registry, err := NewFileRegistry(filepath.Join("..", "..", "..", "schemas"))
+	suite.Require().Nil(err)
+	suite.registry = registry
+}
+
+func (suite *SchemaTestSuite) TestSchema_HappyCase_JSONVersions() {
+	decoder := suite.registry.Decoder("success.order")
+
+	var order testOrder
+	err := decoder([]byte(`{"order_id": 1, "product_id": 2}`), &order)
+	suite.Nil(err)
+	suite.Equal(testOrder{OrderId: 1, ProductId: 2}, order)
+
+	order = testOrder{}
+	err = decoder([]byte(`{"version": 2, "order_id": 3, "items": [{"product_id": 2, "quantity": 4, "unit_price": 9.5}]}`), &order)
+	suite.Nil(err)
+	suite.Equal(uint(2), order.Version)
+	suite.Equal(uint(4), order.Items[0].Quantity)
+	suite.Equal(9.5, *order.Items[0].UnitPrice)
+}
+
+func (suite *SchemaTestSuite) TestSchema_InvalidJSON() {
+	decoder := suite.registry.Decoder("success.order")
+
+	var order testOrder
+	err := decoder([]byte(`{}`), &order)
+	var schemaErr *SchemaError
+	suite.ErrorAs(err, &schemaErr)
+	suite.ErrorIs(err, ErrInvalidMessage)
+	suite.Equal("success.order", schemaErr.Subject)
+	suite.Equal(1, schemaErr.Version)
+	suite.Equal([]string{"/: missing properties: 'order_id', 'product_id'"}, schemaErr.Problems)
+
+	err = decoder([]byte(`{"version": 2, "order_id": "3", "items": [{"product_id": 2, "quantity": 0}]}`), &order)
+	suite.ErrorAs(err, &schemaErr)
+	suite.Equal([]string{"/items/0/quantity: must be >= 1 but found 0", "/order_id: expected integer, but got string"}, schemaErr.Problems)
+
+	err = decoder([]byte(`{"version": 9, "order_id": 3}`), &order)
+	suite.ErrorAs(err, &schemaErr)
+	suite.Equal([]string{"unknown schema version"}, schemaErr.Problems)
+
+	err = decoder([]byte(`not json`), &order)
+	suite.ErrorIs(err, ErrInvalidMessage)
+}
+
+func (suite *SchemaTestSuite) TestSchema_HappyCase_Encode() {
+	payload, err := suite.registry.Encode("decrease.point.success", map[string]interface{}{
+		"version":  2,
+		"order_id": 1,
+		"points":   []map[string]interface{}{{"level": "1", "amount": 1}},
+	})
+	suite.Nil(err)
+	suite.JSONEq(`{"version": 2, "order_id": 1, "points": [{"level": "1", "amount": 1}]}`, string(payload))
+
+	// a topic without schema is plain json
+	payload, err = suite.registry.Encode("point.audit", testOrder{OrderId: 1})
+	suite.Nil(err)
+	suite.JSONEq(`{"order_id": 1}`, string(payload))
+}
+
+func (suite *SchemaTestSuite) TestSchema_EncodeInvalid() {
+	_, err := suite.registry.Encode("decrease.point.failed", map[string]interface{}{"order_id": 1, "reason": ""})
+	suite.ErrorIs(err, ErrInvalidMessage)
+	suite.ErrorContains(err, "/reason: length must be >= 1, but got 0")
+}
+
+func (suite *SchemaTestSuite) TestSchema_HappyCase_Avro() {
+	dir := suite.T().TempDir()
+	suite.Require().Nil(os.Mkdir(filepath.Join(dir, "success.order"), 0o755))
+	suite.Require().Nil(os.WriteFile(filepath.Join(dir, "success.order", "v3.avsc"), []byte(orderAvroSchema), 0o644))
+	registry, err := NewFileRegistry(dir)
+	suite.Require().Nil(err)
+
+	format, ok := registry.Format("success.order")
+	suite.True(ok)
+	suite.Equal(AvroFormat, format)
+
+	unitPrice := 9.5
+	payload, err := registry.Encode("success.order", testOrder{
+		OrderId: 1,
+		Items:   []testOrderItem{{ProductId: 2, Quantity: 3}, {ProductId: 4, Quantity: 1, UnitPrice: &unitPrice}},
+	})
+	suite.Nil(err)
+	// the avro single object encoding marker
+	suite.Equal([]byte{0xc3, 0x01}, payload[:2])
+
+	var order testOrder
+	err = registry.Decoder("success.order")(payload, &order)
+	suite.Nil(err)
+	// the missing user id and channel take the defaults of their fields
+	suite.Equal(testOrder{
+		OrderId: 1,
+		Channel: "web",
+		Items:   []testOrderItem{{ProductId: 2, Quantity: 3}, {ProductId: 4, Quantity: 1, UnitPrice: &unitPrice}},
+	}, order)
+}
+
+func (suite *SchemaTestSuite) TestSchema_InvalidAvro() {
+	registry := NewRegistry()
+	suite.Require().Nil(registry.Register("success.order", 1, AvroFormat, []byte(orderAvroSchema)))
+
+	_, err := registry.Encode("success.order", testOrder{Channel: "phone"})
+	var schemaErr *SchemaError
+	suite.ErrorAs(err, &schemaErr)
+	suite.Contains(schemaErr.Problems[0], "invalid avro")
+	suite.Contains(schemaErr.Problems[0], `"phone"`)
+
+	var order testOrder
+	err = registry.Decoder("success.order")([]byte(`{"order_id": 1}`), &order)
+	suite.ErrorAs(err, &schemaErr)
+	suite.Contains(schemaErr.Problems[0], "invalid avro")
+
+	// a message written with a schema the registry does not have
+	other := NewRegistry()
+	suite.Require().Nil(other.Register("success.order", 1, AvroFormat, []byte(`{"type": "record", "name": "SuccessOrder", "fields": [{"name": "order_id", "type": "long"}]}`)))
+	payload, err := other.Encode("success.order", testOrder{OrderId: 1})
+	suite.Require().Nil(err)
+	err = registry.Decoder("success.order")(payload, &order)
+	suite.ErrorAs(err, &schemaErr)
+	suite.Contains(schemaErr.Problems[0], "unknown schema fingerprint")
+
+	payload, err = registry.Encode("success.order", testOrder{OrderId: 1, Items: []testOrderItem{{ProductId: 2, Quantity: 1}}})
+	suite.Require().Nil(err)
+	err = registry.Decoder("success.order")(append(payload, 2), &order)
+	suite.ErrorAs(err, &schemaErr)
+	suite.Equal(1, schemaErr.Version)
+	suite.Contains(schemaErr.Problems[0], "invalid avro")
+}
+
+func (suite *SchemaTestSuite) TestSchema_InvalidSchema() {
+	registry := NewRegistry()
+
+	err := registry.Register("success.order", 1, JSONSchemaFormat, []byte(`{"type": "object", "required": "order_id"}`))
+	suite.ErrorContains(err, "compile json schema error")
+
+	err = registry.Register("success.order", 1, JSONSchemaFormat, []byte(`{"type": `))
+	suite.ErrorContains(err, "parse json schema error")
+
+	err = registry.Register("success.order", 1, AvroFormat, []byte(`{"type": "fixed", "name": "Hash"}`))
+	suite.ErrorContains(err, "parse avro schema error")
+
+	suite.Require().Nil(registry.Register("success.order", 1, JSONSchemaFormat, []byte(`{"type": "object"}`)))
+	err = registry.Register("success.order", 2, AvroFormat, []byte(orderAvroSchema))
+	suite.ErrorContains(err, "subject success.order mixes json and avro schemas")
+}
+
+func (suite *SchemaTestSuite) TestSchema_DeadLetterValidationErrors() {
+	transaction := &fakeTransaction{}
+	message := &sarama.ConsumerMessage{Topic: "success.order", Value: []byte(`{}`)}
+
+	err := Decode(suite.registry.Decoder("success.order"), func(ctx context.Context, value testOrder) error {
+		return nil
+	})(context.Background(), message)
+	suite.ErrorIs(err, ErrInvalidMessage)
+
+	err = NewDeadLetter(nil, "success.order.dlq").SendDeadLetter(WithTransaction(context.Background(), transaction), message, err)
+	suite.Nil(err)
+	suite.Equal("/: missing properties: 'order_id', 'product_id'", transaction.headers[HeaderValidationErrors])
+
+	// other errors have no validation errors
+	err = NewDeadLetter(nil, "success.order.dlq").SendDeadLetter(WithTransaction(context.Background(), transaction), message, errors.New("invalid order"))
+	suite.Nil(err)
+	suite.NotContains(transaction.headers, HeaderValidationErrors)
+}
+
+func TestSchemaTestSuite(t *testing.T) {
+	suite.Run(t, new(SchemaTestSuite))
+}
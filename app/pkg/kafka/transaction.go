@@ -0,0 +1,114 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/IBM/sarama"
+	"github.com/pkg/errors"
+)
+
+// ErrTransactionFailed is a kafka transaction that could neither commit nor abort, the producer
+// cannot be used anymore
+var ErrTransactionFailed = errors.New("kafka transaction failed")
+
+// TransactionalProducer sends messages and the offsets of consumed messages in one kafka transaction,
+// consumers reading read_committed see all of them or none
+type TransactionalProducer interface {
+	Producer
+	BeginTxn() error
+	CommitTxn() error
+	AbortTxn() error
+	// AddMessageToTxn adds the offset after message to the transaction with AddOffsetsToTxn
+	AddMessageToTxn(message *sarama.ConsumerMessage, groupId string) error
+}
+
+// TransactionalProducerFactory returns the producer of a claimed partition, its transactional id
+// should only depend on the topic and partition so a restarted consumer fences the previous one
+type TransactionalProducerFactory func(topic string, partition int32) (TransactionalProducer, error)
+
+type transactionalProducer struct {
+	producer
+}
+
+type transactionKey struct{}
+
+func NewTransactionalProducer(addresses []string, partitioner Partitioner, transactionalId string) (TransactionalProducer, error) {
+	kafkaConfig := sarama.NewConfig()
+	kafkaConfig.Version = sarama.DefaultVersion
+	kafkaConfig.Producer.Partitioner = partitioner.constructor()
+	kafkaConfig.Producer.RequiredAcks = sarama.WaitForAll
+	kafkaConfig.Producer.Retry.Max = 3
+	kafkaConfig.Producer.Return.Successes = true
+	kafkaConfig.Producer.Idempotent = true
+	kafkaConfig.Producer.Transaction.ID = transactionalId
+	kafkaConfig.Net.MaxOpenRequests = 1
+
+	syncProducer, err := sarama.NewSyncProducer(addresses, kafkaConfig)
+	if err != nil {
+		return nil, errors.Wrap(err, "new transactional producer sarama error")
+	}
+
+	return transactionalProducer{
+		producer: producer{
+			SyncProducer: syncProducer,
+		},
+	}, nil
+}
+
+func (producer transactionalProducer) BeginTxn() error {
+	return producer.SyncProducer.BeginTxn()
+}
+
+func (producer transactionalProducer) CommitTxn() error {
+	return producer.SyncProducer.CommitTxn()
+}
+
+func (producer transactionalProducer) AbortTxn() error {
+	return producer.SyncProducer.AbortTxn()
+}
+
+func (producer transactionalProducer) AddMessageToTxn(message *sarama.ConsumerMessage, groupId string) error {
+	return producer.SyncProducer.AddMessageToTxn(message, groupId, nil)
+}
+
+// WithTransaction returns a context whose messages are sent with the transaction of producer
+func WithTransaction(ctx context.Context, producer Producer) context.Context {
+	return context.WithValue(ctx, transactionKey{}, producer)
+}
+
+// TransactionFromContext returns the producer of the kafka transaction of ctx
+func TransactionFromContext(ctx context.Context) (Producer, bool) {
+	producer, ok := ctx.Value(transactionKey{}).(Producer)
+	return producer, ok
+}
+
+// inTransaction runs fn in a kafka transaction that also commits the offset of message. An error of fn
+// aborts the transaction, the messages fn sent are never seen by read_committed consumers.
+func inTransaction(ctx context.Context, producer TransactionalProducer, groupId string, message *sarama.ConsumerMessage, fn func(ctx context.Context) error) error {
+	err := producer.BeginTxn()
+	if err != nil {
+		return fmt.Errorf("%w: begin: %v", ErrTransactionFailed, err)
+	}
+
+	err = fn(WithTransaction(ctx, producer))
+	if err == nil {
+		err = producer.AddMessageToTxn(message, groupId)
+		if err == nil {
+			err = producer.CommitTxn()
+		}
+		if err != nil {
+			err = Retryable(errors.Wrap(err, "commit transaction error"))
+		}
+	}
+	if err == nil {
+		return nil
+	}
+
+	abortErr := producer.AbortTxn()
+	if abortErr != nil {
+		return fmt.Errorf("%w: abort: %v", ErrTransactionFailed, abortErr)
+	}
+
+	return err
+}
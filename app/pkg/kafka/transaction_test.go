@@ -0,0 +1,143 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/suite"
+)
+
+type fakeTransaction struct {
+	calls     []string
+	headers   map[string]string
+	commitErr error
+	abortErr  error
+}
+
+func (transaction *fakeTransaction) SendMessage(topic string, key string, message string, headers map[string]string) error {
+	transaction.calls = append(transaction.calls, "send "+topic)
+	transaction.headers = headers
+	return nil
+}
+
+func (transaction *fakeTransaction) SendMessageToPartition(topic string, partition int32, key string, message string, headers map[string]string) error {
+	return transaction.SendMessage(topic, key, message, headers)
+}
+
+func (transaction *fakeTransaction) Flush() error {
+	return nil
+}
+
+func (transaction *fakeTransaction) CloseConnection() error {
+	return nil
+}
+
+func (transaction *fakeTransaction) BeginTxn() error {
+	transaction.calls = append(transaction.calls, "begin")
+	return nil
+}
+
+func (transaction *fakeTransaction) CommitTxn() error {
+	transaction.calls = append(transaction.calls, "commit")
+	return transaction.commitErr
+}
+
+func (transaction *fakeTransaction) AbortTxn() error {
+	transaction.calls = append(transaction.calls, "abort")
+	return transaction.abortErr
+}
+
+func (transaction *fakeTransaction) AddMessageToTxn(message *sarama.ConsumerMessage, groupId string) error {
+	transaction.calls = append(transaction.calls, fmt.Sprintf("offset %s %d", groupId, message.Offset))
+	return nil
+}
+
+type TransactionTestSuite struct {
+	suite.Suite
+	transaction *fakeTransaction
+	message     *sarama.ConsumerMessage
+}
+
+func (suite *TransactionTestSuite) SetupTest() {
+	suite.transaction = &fakeTransaction{}
+	suite.message = &sarama.ConsumerMessage{Topic: "success.order", Offset: 42}
+}
+
+func (suite *TransactionTestSuite) send(ctx context.Context) error {
+	producer, ok := TransactionFromContext(ctx)
+	suite.True(ok)
+	return producer.SendMessage("decrease.point.success", "1", `{"order_id":1}`, nil)
+}
+
+func (suite *TransactionTestSuite) TestTransaction_HappyCase_Commit() {
+	err := inTransaction(context.Background(), suite.transaction, "point-service", suite.message, suite.send)
+	suite.Nil(err)
+	suite.Equal([]string{"begin", "send decrease.point.success", "offset point-service 42", "commit"}, suite.transaction.calls)
+}
+
+func (suite *TransactionTestSuite) TestTransaction_HandlerError() {
+	err := inTransaction(context.Background(), suite.transaction, "point-service", suite.message, func(ctx context.Context) error {
+		_ = suite.send(ctx)
+		return errors.New("invalid order")
+	})
+	suite.ErrorContains(err, "invalid order")
+	suite.Equal([]string{"begin", "send decrease.point.success", "abort"}, suite.transaction.calls)
+}
+
+func (suite *TransactionTestSuite) TestTransaction_CommitError() {
+	suite.transaction.commitErr = sarama.ErrConcurrentTransactions
+
+	err := inTransaction(context.Background(), suite.transaction, "point-service", suite.message, suite.send)
+	suite.ErrorIs(err, sarama.ErrConcurrentTransactions)
+	suite.True(IsRetryable(err))
+	suite.Equal("abort", suite.transaction.calls[len(suite.transaction.calls)-1])
+}
+
+func (suite *TransactionTestSuite) TestTransaction_AbortError() {
+	suite.transaction.commitErr = sarama.ErrProducerFenced
+	suite.transaction.abortErr = sarama.ErrProducerFenced
+
+	err := inTransaction(context.Background(), suite.transaction, "point-service", suite.message, suite.send)
+	suite.ErrorIs(err, ErrTransactionFailed)
+	suite.False(IsRetryable(err))
+}
+
+func (suite *TransactionTestSuite) TestTransaction_ConsumerProcess() {
+	consumer := NewTransactionalConsumer(NewRouter(), "point-service", nil, Timeouts{Handler: time.Minute, Processing: time.Minute})
+	route := &Route{RetryPolicy: RetryPolicy{MaxAttempts: 2}}
+
+	attempts := 0
+	err := consumer.process(context.Background(), route, func(ctx context.Context, message *sarama.ConsumerMessage) error {
+		attempts++
+		_ = suite.send(ctx)
+		if attempts == 1 {
+			return Retryable(errors.New("deadlock detected"))
+		}
+		return nil
+	}, suite.message, suite.transaction)
+	suite.Nil(err)
+
+	// the failed attempt is aborted, only the second one commits its message with the offset
+	suite.Equal([]string{
+		"begin", "send decrease.point.success", "abort",
+		"begin", "send decrease.point.success", "offset point-service 42", "commit",
+	}, suite.transaction.calls)
+}
+
+func (suite *TransactionTestSuite) TestTransaction_DeadLetter() {
+	deadLetter := NewDeadLetter(nil, "success.order.dlq")
+
+	err := inTransaction(context.Background(), suite.transaction, "point-service", suite.message, func(ctx context.Context) error {
+		return deadLetter.SendDeadLetter(ctx, suite.message, errors.New("invalid order"))
+	})
+	suite.Nil(err)
+	suite.Equal([]string{"begin", "send success.order.dlq", "offset point-service 42", "commit"}, suite.transaction.calls)
+}
+
+func TestTransactionTestSuite(t *testing.T) {
+	suite.Run(t, new(TransactionTestSuite))
+}